@@ -0,0 +1,189 @@
+package zoau
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Placeholders of a backup name pattern that vary from a backup to the next, with the expression
+// matching their values. {hlq}, {name} and {member} are replaced first.
+var backupPlaceholders = map[string]string{
+	"{date}": `[0-9]{6}`,
+	"{time}": `[0-9]{6}`,
+	"{seq}":  `([0-9]{4})`,
+}
+
+// Backup runs Client.Backup on the default client.
+func Backup(dataset string, args *BackupArgs) (string, error) {
+	return DefaultClient().Backup(context.Background(), dataset, args)
+}
+
+// Copy a sequential dataset, a member or a whole partitioned dataset to a backup and return the name
+// of the backup. Once copied, the oldest backups named after the same pattern are deleted to keep
+// args.Retention of them.
+func (c *Client) Backup(ctx context.Context, dataset string, args *BackupArgs) (string, error) {
+	if err := validateNames(dataset); err != nil {
+		return "", err
+	}
+	if args == nil {
+		args = &BackupArgs{}
+	}
+	if args.Name == nil {
+		if args.Retention > 0 {
+			return "", errors.New("Retention requires a backup name pattern")
+		}
+		backup, err := c.TmpName(ctx, args.Hlq)
+		if err != nil {
+			return "", err
+		}
+		return backup, c.Copy(ctx, dataset, backup, nil)
+	}
+
+	hlq := ""
+	if args.Hlq != nil {
+		hlq = *args.Hlq
+	} else if strings.Contains(*args.Name, "{hlq}") {
+		var err error
+		if hlq, err = c.Hlq(ctx); err != nil {
+			return "", err
+		}
+	}
+	name, member := splitMemberReference(strings.ToUpper(dataset))
+	pattern := strings.NewReplacer("{hlq}", strings.ToUpper(hlq), "{name}", name, "{member}", member).Replace(*args.Name)
+	matcher, err := backupMatcher(pattern)
+	if err != nil {
+		return "", err
+	}
+
+	existing, err := c.listBackups(ctx, pattern, matcher)
+	if err != nil {
+		return "", err
+	}
+	seq := 0
+	for _, b := range existing {
+		if m := matcher.FindStringSubmatch(b); len(m) > 1 {
+			n, _ := strconv.Atoi(m[1])
+			seq = max(seq, n)
+		}
+	}
+	now := time.Now()
+	backup := strings.NewReplacer(
+		"{date}", now.Format("060102"),
+		"{time}", now.Format("150405"),
+		"{seq}", fmt.Sprintf("%04d", seq+1),
+	).Replace(pattern)
+	if err := validateNames(backup); err != nil {
+		return "", err
+	}
+	if err := c.Copy(ctx, dataset, backup, nil); err != nil {
+		return "", err
+	}
+
+	if args.Retention > 0 {
+		backups := existing
+		if !slices.Contains(backups, backup) {
+			backups = append(backups, backup)
+		}
+		sort.Strings(backups)
+		for _, old := range backups[:max(len(backups)-int(args.Retention), 0)] {
+			if err := c.deleteBackup(ctx, old); err != nil {
+				return backup, err
+			}
+		}
+	}
+	return backup, nil
+}
+
+// backupMatcher returns the expression matching the names pattern can produce once its placeholders
+// are replaced. The {seq} group, if any, is the first one.
+func backupMatcher(pattern string) (*regexp.Regexp, error) {
+	var expr strings.Builder
+	expr.WriteString("^")
+	for rest := pattern; rest != ""; {
+		open := strings.IndexByte(rest, '{')
+		end := strings.IndexByte(rest, '}')
+		if open < 0 || end < open {
+			expr.WriteString(regexp.QuoteMeta(rest))
+			break
+		}
+		placeholder := rest[open : end+1]
+		value, ok := backupPlaceholders[placeholder]
+		if !ok {
+			return nil, fmt.Errorf("%w %q: unknown placeholder %s", ErrInvalidName, pattern, placeholder)
+		}
+		expr.WriteString(regexp.QuoteMeta(rest[:open]) + value)
+		rest = rest[end+1:]
+	}
+	expr.WriteString("$")
+	return regexp.Compile(expr.String())
+}
+
+// listBackups returns the existing datasets or members matching the backup name pattern.
+func (c *Client) listBackups(ctx context.Context, pattern string, matcher *regexp.Regexp) ([]string, error) {
+	wildcards := regexp.MustCompile(`\{[a-z]+\}`)
+	name, member := splitMemberReference(pattern)
+	var stdout string
+	var rc int
+	var err error
+	if member == "" {
+		stdout, rc, err = c.execZaouCmd(ctx, "dls", []string{wildcards.ReplaceAllString(name, "*")})
+	} else if !strings.Contains(name, "{") {
+		stdout, rc, err = c.execZaouCmd(ctx, "mls", []string{name + "(" + wildcards.ReplaceAllString(member, "*") + ")"})
+	} else {
+		return nil, fmt.Errorf("%w %q: the dataset of a backup member must not vary", ErrInvalidName, pattern)
+	}
+	if rc == 1 || errors.Is(err, ErrNotFound) {
+		// No backup yet, or a partitioned dataset of backups yet to be created.
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	backups := make([]string, 0)
+	for _, line := range strings.Split(stdout, "\n") {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		if member != "" && !strings.Contains(line, "(") {
+			line = name + "(" + line + ")"
+		}
+		if matcher.MatchString(line) {
+			backups = append(backups, line)
+		}
+	}
+	return backups, nil
+}
+
+func (c *Client) deleteBackup(ctx context.Context, backup string) error {
+	if _, member := splitMemberReference(backup); member != "" {
+		_, err := c.DeleteMember(ctx, backup)
+		return err
+	}
+	_, err := c.Delete(ctx, backup)
+	return err
+}
+
+// Restore runs Client.Restore on the default client.
+func Restore(backup string, target string, args *RestoreArgs) error {
+	return DefaultClient().Restore(context.Background(), backup, target, args)
+}
+
+// Copy a backup taken by Backup back to its target. The members of a partitioned dataset are replaced,
+// those added since the backup are kept.
+func (c *Client) Restore(ctx context.Context, backup string, target string, args *RestoreArgs) error {
+	if err := c.Copy(ctx, backup, target, nil); err != nil {
+		return err
+	}
+	if args != nil && args.Delete {
+		return c.deleteBackup(ctx, backup)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package zoau_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Stolkerve/zoau-go"
+	"github.com/Stolkerve/zoau-go/zoautest"
+)
+
+func TestFindReplaceBackup(t *testing.T) {
+	ctx := context.Background()
+	c, ds := newSedDataset(t, "SYSNAME=SYS1\nCLOCK=00")
+
+	backup, err := c.FindReplace(ctx, ds, "SYS1", "SYS2", &zoau.FindReplaceArgs{Backup: &zoau.BackupArgs{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if backup == "" {
+		t.Fatal("expected: the name of the backup")
+	}
+	if out, err := c.Read(ctx, backup, nil); err != nil || out != "SYSNAME=SYS1\nCLOCK=00" {
+		t.Fatalf("Unexpected backup %q, %v", out, err)
+	}
+
+	if err := c.Restore(ctx, backup, ds, &zoau.RestoreArgs{Delete: true}); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := c.Read(ctx, ds, nil); err != nil || out != "SYSNAME=SYS1\nCLOCK=00" {
+		t.Fatalf("Unexpected restored content %q, %v", out, err)
+	}
+	if exists, err := c.Exist(ctx, backup); err != nil || exists {
+		t.Fatalf("expected: %s deleted, got %v, %v", backup, exists, err)
+	}
+
+	if backup, err := c.FindReplace(ctx, ds, "SYS1", "SYS2", nil); err != nil || backup != "" {
+		t.Fatalf("expected: no backup, got %q, %v", backup, err)
+	}
+	// dsed runs even when nothing matches, so the backup is taken.
+	if backup, err := c.FindReplace(ctx, ds, "SYS1", "SYS2", &zoau.FindReplaceArgs{Backup: &zoau.BackupArgs{}}); err != nil || backup == "" {
+		t.Fatalf("expected: a backup, got %q, %v", backup, err)
+	}
+}
+
+func TestLineInFileBackupRetention(t *testing.T) {
+	ctx := context.Background()
+	c := zoau.NewClient(&zoau.ClientArgs{Executor: zoautest.NewSimulator("USER")})
+	if _, err := c.Create(ctx, "USER.PARMLIB", &zoau.CreateArgs{Type: zoau.String(zoau.DS_ORG_PDSE)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Write(ctx, "USER.PARMLIB(IEASYS00)", "MAXUSER=100", false); err != nil {
+		t.Fatal(err)
+	}
+
+	args := &zoau.BackupArgs{Name: zoau.String("{hlq}.BACKUP.{member}.V{seq}"), Retention: 2}
+	backups := make([]string, 0)
+	for _, value := range []string{"200", "300", "400"} {
+		res, err := c.LineInFile(ctx, "USER.PARMLIB(IEASYS00)", "MAXUSER="+value, &zoau.LineInFileArgs{Regex: zoau.String("^MAXUSER="), Backup: args})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !res.Changed || res.Backup == "" {
+			t.Fatalf("expected: a change and a backup, got %+v", res)
+		}
+		backups = append(backups, res.Backup)
+	}
+	expected := []string{"USER.BACKUP.IEASYS00.V0001", "USER.BACKUP.IEASYS00.V0002", "USER.BACKUP.IEASYS00.V0003"}
+	if !reflect.DeepEqual(backups, expected) {
+		t.Fatalf("expected: %v, got %v", expected, backups)
+	}
+	for i, backup := range backups {
+		if exists, err := c.Exist(ctx, backup); err != nil || exists != (i > 0) {
+			t.Fatalf("%s: unexpected existence %v, %v", backup, exists, err)
+		}
+	}
+	if out, err := c.Read(ctx, backups[2], nil); err != nil || out != "MAXUSER=300" {
+		t.Fatalf("Unexpected backup %q, %v", out, err)
+	}
+
+	if err := c.Restore(ctx, backups[1], "USER.PARMLIB(IEASYS00)", nil); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := c.Read(ctx, "USER.PARMLIB(IEASYS00)", nil); err != nil || out != "MAXUSER=200" {
+		t.Fatalf("Unexpected restored content %q, %v", out, err)
+	}
+}
+
+func TestBackupPartitioned(t *testing.T) {
+	ctx := context.Background()
+	c := zoau.NewClient(&zoau.ClientArgs{Executor: zoautest.NewSimulator("USER")})
+	for _, name := range []string{"USER.PROCLIB", "USER.BACKLIB"} {
+		if _, err := c.Create(ctx, name, &zoau.CreateArgs{Type: zoau.String(zoau.DS_ORG_PDSE)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, content := range map[string]string{"USER.PROCLIB(PROC1)": "//PROC1 PROC", "USER.PROCLIB(PROC2)": "//PROC2 PROC"} {
+		if err := c.Write(ctx, name, content, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	backup, err := c.Backup(ctx, "USER.PROCLIB", &zoau.BackupArgs{Name: zoau.String("{name}.D{date}"), Hlq: zoau.String("IGNORED")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(backup, "USER.PROCLIB.D") || len(backup) != len("USER.PROCLIB.D000000") {
+		t.Fatalf("Unexpected backup name %s", backup)
+	}
+	if members, err := c.ListMembers(ctx, backup); err != nil || strings.Join(members, " ") != "PROC1 PROC2 " {
+		t.Fatalf("Unexpected members %v, %v", members, err)
+	}
+
+	res, err := c.BlockInFile(ctx, "USER.PROCLIB(PROC2)", &zoau.BlockInFileArgs{Block: zoau.String("//STEP EXEC PGM=IEFBR14"), Backup: &zoau.BackupArgs{Name: zoau.String("USER.BACKLIB({member})")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Backup != "USER.BACKLIB(PROC2)" {
+		t.Fatalf("Unexpected backup %+v", res)
+	}
+	if out, err := c.Read(ctx, res.Backup, nil); err != nil || out != "//PROC2 PROC" {
+		t.Fatalf("Unexpected backup %q, %v", out, err)
+	}
+
+	// Only a missing dataset of backups means that there is no backup yet.
+	if err := c.Write(ctx, "USER.FLAT", "A", false); err != nil {
+		t.Fatal(err)
+	}
+	var cmdErr *zoau.CommandError
+	if _, err := c.Backup(ctx, "USER.PROCLIB(PROC1)", &zoau.BackupArgs{Name: zoau.String("USER.FLAT(B{seq})")}); !errors.As(err, &cmdErr) || cmdErr.Command != "mls" {
+		t.Fatalf("A dataset of backups that is not partitioned must be reported by mls, got %v", err)
+	}
+
+	if _, err := c.Backup(ctx, "USER.PROCLIB", &zoau.BackupArgs{Name: zoau.String("USER.{unknown}")}); err == nil {
+		t.Fatal("An unknown placeholder must be rejected")
+	}
+	if _, err := c.Backup(ctx, "USER.PROCLIB", &zoau.BackupArgs{Retention: 1}); err == nil {
+		t.Fatal("Retention without a name pattern must be rejected")
+	}
+}
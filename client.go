@@ -0,0 +1,185 @@
+package zoau
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Client runs the ZOAU utilities through an Executor.
+//
+// A Client is immutable once created and safe for concurrent use by multiple goroutines.
+type Client struct {
+	executor    Executor
+	plan        *Plan
+	middlewares []Middleware
+	retry       *RetryPolicy
+	config      *Config
+	env         []string
+	hlq         string
+	timeout     time.Duration
+}
+
+type ClientArgs struct {
+	// Executor used to run the ZOAU utilities. Defaults to an ExecExecutor using Config.
+	Executor Executor
+
+	// ZOAU installation, see LoadConfig. Its release decides which flags the functions use, and
+	// the functions needing a missing capability return ErrUnsupported. Nil assumes every capability.
+	Config *Config
+
+	// Enables the dry-run mode: the functions that change datasets, jobs or the system configuration
+	// (Create, Delete, Write, Execute, SubmitJob, Apf, ...) record their commands into DryRun and report
+	// success without running them. Read-only functions still run.
+	DryRun *Plan
+
+	// Extra environment variables in "KEY=value" form, passed to every command (e.g. "_BPXK_AUTOCVT=ON").
+	Env []string
+
+	// High level qualifier returned by Hlq and used by TmpName when no HLQ is given.
+	// Defaults to the HLQ of the active TSO environment.
+	Hlq *string
+
+	// Maximum duration of every command, 0 for no limit. It applies to each attempt when the
+	// command is retried.
+	Timeout time.Duration
+
+	// Logs every command, see NewSlogMiddleware.
+	Logger *slog.Logger
+
+	// Middlewares observing every command, see Middleware.
+	Middlewares []Middleware
+
+	// Retries the commands failing for a transient reason. Nil disables the retries.
+	// WithRetryPolicy overrides it for a single call.
+	Retry *RetryPolicy
+}
+
+// NewClient returns a Client configured by args. A nil args gives a Client that runs the
+// ZOAU utilities as local processes.
+func NewClient(args *ClientArgs) *Client {
+	c := &Client{executor: ExecExecutor{}}
+	if args != nil {
+		c.config = args.Config
+		if args.Executor != nil {
+			c.executor = args.Executor
+		} else if args.Config != nil {
+			c.executor = ExecExecutor{Config: args.Config}
+		}
+		c.plan = args.DryRun
+		c.env = append([]string(nil), args.Env...)
+		if args.Hlq != nil {
+			c.hlq = *args.Hlq
+		}
+		c.timeout = args.Timeout
+		if args.Logger != nil {
+			c.middlewares = append(c.middlewares, NewSlogMiddleware(args.Logger, nil))
+		}
+		c.middlewares = append(c.middlewares, args.Middlewares...)
+		if args.Retry != nil {
+			retry := *args.Retry
+			c.retry = &retry
+		}
+	}
+	return c
+}
+
+var (
+	defaultClientMu sync.RWMutex
+	defaultClient   = NewClient(nil)
+)
+
+// DefaultClient returns the Client used by the package-level functions.
+func DefaultClient() *Client {
+	defaultClientMu.RLock()
+	defer defaultClientMu.RUnlock()
+	return defaultClient
+}
+
+// SetDefaultClient replaces the Client used by the package-level functions.
+// A nil client restores a Client that runs the ZOAU utilities as local processes.
+func SetDefaultClient(c *Client) {
+	if c == nil {
+		c = NewClient(nil)
+	}
+	defaultClientMu.Lock()
+	defer defaultClientMu.Unlock()
+	defaultClient = c
+}
+
+func (c *Client) execZaouCmd(ctx context.Context, proc string, params []string) (string, int, error) {
+	return c.execCommand(ctx, Command{Name: proc, Args: params})
+}
+
+// execCommand runs cmd with the environment of the Client, or records it in dry-run mode.
+func (c *Client) execCommand(ctx context.Context, cmd Command) (string, int, error) {
+	cmd.Env = append(slices.Clip(c.env), cmd.Env...)
+	if c.plan != nil && isMutating(cmd) {
+		c.plan.add(cmd)
+		return "", 0, nil
+	}
+
+	res, err := c.runWithRetry(ctx, cmd)
+	return res.Stdout, res.Rc, err
+}
+
+// run runs cmd through the middlewares and the executor. The error is nil or a *CommandError.
+func (c *Client) run(ctx context.Context, cmd Command, attempt int) (Result, error) {
+	ctx = c.before(ctx, cmd)
+	runCtx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	start := time.Now()
+	res, err := c.executor.Run(runCtx, cmd)
+	return c.after(ctx, cmd, attempt, start, res, err)
+}
+
+// before calls the Before hook of the middlewares.
+func (c *Client) before(ctx context.Context, cmd Command) context.Context {
+	for _, m := range c.middlewares {
+		ctx = m.Before(ctx, cmd)
+	}
+	return ctx
+}
+
+// withTimeout returns the context of a command, bounded by the Client timeout.
+func (c *Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.timeout > 0 {
+		return context.WithTimeout(ctx, c.timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// after turns the outcome of the executor into a *CommandError and calls the After hook of the middlewares.
+func (c *Client) after(ctx context.Context, cmd Command, attempt int, start time.Time, res Result, err error) (Result, error) {
+	if err != nil {
+		res.Rc = -1
+		err = &CommandError{
+			Command:  cmd.Name,
+			Args:     cmd.Args,
+			Rc:       -1,
+			Stdout:   res.Stdout,
+			Stderr:   res.Stderr,
+			Err:      err,
+			Attempts: attempt,
+		}
+	} else if res.Rc != 0 {
+		err = &CommandError{
+			Command:  cmd.Name,
+			Args:     cmd.Args,
+			Rc:       res.Rc,
+			Stdout:   res.Stdout,
+			Stderr:   res.Stderr,
+			Messages: ParseMessages(res.Stderr + "\n" + res.Stdout),
+			Attempts: attempt,
+		}
+	}
+
+	event := Event{Command: cmd, Attempt: attempt, Rc: res.Rc, Start: start, Duration: time.Since(start), Err: err}
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		c.middlewares[i].After(ctx, event)
+	}
+	return res, err
+}
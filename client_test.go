@@ -0,0 +1,78 @@
+package zoau_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Stolkerve/zoau-go"
+	"github.com/Stolkerve/zoau-go/zoautest"
+)
+
+// slowExecutor waits for ctx to be done.
+type slowExecutor struct{}
+
+func (slowExecutor) Run(ctx context.Context, cmd zoau.Command) (zoau.Result, error) {
+	<-ctx.Done()
+	return zoau.Result{Rc: -1}, fmt.Errorf("%s: %w", cmd.Name, ctx.Err())
+}
+
+func TestClientConfig(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeExecutor{results: map[string]zoau.Result{"mvstmp": {Stdout: "PROD.P0000001.T0000001.C0000001\n"}}}
+	c := zoau.NewClient(&zoau.ClientArgs{Executor: fake, Hlq: zoau.String("PROD"), Env: []string{"_BPXK_AUTOCVT=ON"}})
+
+	if hlq, err := c.Hlq(ctx); err != nil || hlq != "PROD" {
+		t.Fatalf("expected: PROD, <nil>, got %s, %v", hlq, err)
+	}
+	if _, err := c.TmpName(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.commands) != 1 || strings.Join(fake.commands[0].Args, " ") != "PROD" {
+		t.Fatalf("expected: mvstmp PROD, got %v", fake.commands)
+	}
+	if env := fake.commands[0].Env; len(env) != 1 || env[0] != "_BPXK_AUTOCVT=ON" {
+		t.Fatalf("expected: [_BPXK_AUTOCVT=ON], got %v", env)
+	}
+
+	c = zoau.NewClient(&zoau.ClientArgs{Executor: slowExecutor{}, Timeout: 10 * time.Millisecond})
+	if _, err := c.Read(ctx, "USER.PARMS", nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected: %v, got %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestClientConcurrent(t *testing.T) {
+	ctx := context.Background()
+	c := zoau.NewClient(&zoau.ClientArgs{Executor: zoautest.NewSimulator("USER")})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := fmt.Sprintf("USER.DS%d", i)
+			if err := c.Write(ctx, name, name, false); err != nil {
+				errs <- err
+				return
+			}
+			if out, err := c.Read(ctx, name, nil); err != nil || out != name {
+				errs <- fmt.Errorf("expected: %s, got %q, %v", name, out, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	datasets, err := c.ListingDataset(ctx, "USER.DS*", nil)
+	if err != nil || len(datasets) != 16 {
+		t.Fatalf("expected: 16 datasets, got %d, %v", len(datasets), err)
+	}
+}
@@ -0,0 +1,227 @@
+package codepage
+
+import (
+	"sync"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Charmap is a single byte code page, whose 256 bytes each stand for a rune.
+type Charmap struct {
+	name  string
+	table *[256]rune
+
+	// Byte written by an Encoder made by ReplaceUnsupported for a rune missing from the code page:
+	// SUB, 0x3F in EBCDIC and 0x1A in ISO8859-1.
+	sub byte
+
+	once   sync.Once
+	encode map[rune]byte
+}
+
+// charmapTable returns the byte to rune table of a code page of golang.org/x/text.
+func charmapTable(m *charmap.Charmap) *[256]rune {
+	var table [256]rune
+	for i := range table {
+		table[i] = m.DecodeByte(byte(i))
+	}
+	return &table
+}
+
+var (
+	// IBM037 is the EBCDIC code page of the USA and Canada.
+	IBM037 = &Charmap{name: "IBM-037", table: charmapTable(charmap.CodePage037), sub: 0x3F}
+
+	// IBM273 is the EBCDIC code page of Germany and Austria.
+	IBM273 = &Charmap{name: "IBM-273", table: &ibm273, sub: 0x3F}
+
+	// IBM500 is the international EBCDIC code page.
+	IBM500 = &Charmap{name: "IBM-500", table: &ibm500, sub: 0x3F}
+
+	// IBM1047 is the Latin-1 EBCDIC code page of z/OS UNIX System Services.
+	IBM1047 = &Charmap{name: "IBM-1047", table: charmapTable(charmap.CodePage1047), sub: 0x3F}
+
+	// IBM1140 to IBM1149 are the EBCDIC code pages of the euro family, each the code page of a country
+	// with the euro sign in place of the currency sign.
+	IBM1140 = &Charmap{name: "IBM-1140", table: charmapTable(charmap.CodePage1140), sub: 0x3F}
+	IBM1141 = &Charmap{name: "IBM-1141", table: &ibm1141, sub: 0x3F}
+	IBM1142 = &Charmap{name: "IBM-1142", table: &ibm1142, sub: 0x3F}
+	IBM1143 = &Charmap{name: "IBM-1143", table: &ibm1143, sub: 0x3F}
+	IBM1144 = &Charmap{name: "IBM-1144", table: &ibm1144, sub: 0x3F}
+	IBM1145 = &Charmap{name: "IBM-1145", table: &ibm1145, sub: 0x3F}
+	IBM1146 = &Charmap{name: "IBM-1146", table: &ibm1146, sub: 0x3F}
+	IBM1147 = &Charmap{name: "IBM-1147", table: &ibm1147, sub: 0x3F}
+	IBM1148 = &Charmap{name: "IBM-1148", table: &ibm1148, sub: 0x3F}
+	IBM1149 = &Charmap{name: "IBM-1149", table: &ibm1149, sub: 0x3F}
+
+	// ISO8859_1 is the Latin-1 ASCII code page, whose bytes are the first 256 runes.
+	ISO8859_1 = &Charmap{name: "ISO8859-1", table: charmapTable(charmap.ISO8859_1), sub: 0x1A}
+)
+
+func (m *Charmap) String() string {
+	return m.name
+}
+
+// DecodeByte returns the rune of b.
+func (m *Charmap) DecodeByte(b byte) rune {
+	return m.table[b]
+}
+
+// EncodeRune returns the byte of r, and false if r is not in the code page.
+func (m *Charmap) EncodeRune(r rune) (byte, bool) {
+	m.once.Do(func() {
+		m.encode = make(map[rune]byte, len(m.table))
+		for i, r := range m.table {
+			m.encode[r] = byte(i)
+		}
+	})
+	b, ok := m.encode[r]
+	return b, ok
+}
+
+// NewDecoder returns a Decoder of the code page. Every byte has a rune, so decoding does not fail.
+func (m *Charmap) NewDecoder() *Decoder {
+	return &Decoder{charmapDecoder{m}}
+}
+
+// NewEncoder returns an Encoder of the code page, failing with an *UnsupportedRuneError on a rune the
+// code page does not have.
+func (m *Charmap) NewEncoder() *Encoder {
+	return &Encoder{&charmapEncoder{charmap: m}}
+}
+
+type charmapDecoder struct {
+	charmap *Charmap
+}
+
+func (d charmapDecoder) Reset() {}
+
+func (d charmapDecoder) Transform(dst, src []byte, atEOF bool) (int, int, error) {
+	nDst, nSrc := 0, 0
+	for ; nSrc < len(src); nSrc++ {
+		r := d.charmap.table[src[nSrc]]
+		if r < utf8.RuneSelf {
+			if nDst == len(dst) {
+				return nDst, nSrc, ErrShortDst
+			}
+			dst[nDst] = byte(r)
+			nDst++
+			continue
+		}
+		if nDst+utf8.RuneLen(r) > len(dst) {
+			return nDst, nSrc, ErrShortDst
+		}
+		nDst += utf8.EncodeRune(dst[nDst:], r)
+	}
+	return nDst, nSrc, nil
+}
+
+type charmapEncoder struct {
+	charmap *Charmap
+	replace bool
+}
+
+func (e *charmapEncoder) Reset() {}
+
+func (e *charmapEncoder) Transform(dst, src []byte, atEOF bool) (int, int, error) {
+	nDst, nSrc := 0, 0
+	for nSrc < len(src) {
+		r, size := rune(src[nSrc]), 1
+		if r >= utf8.RuneSelf {
+			if !atEOF && !utf8.FullRune(src[nSrc:]) {
+				return nDst, nSrc, ErrShortSrc
+			}
+			r, size = utf8.DecodeRune(src[nSrc:])
+		}
+		if nDst == len(dst) {
+			return nDst, nSrc, ErrShortDst
+		}
+
+		b, ok := e.charmap.EncodeRune(r)
+		switch {
+		case r == utf8.RuneError && size == 1 && !e.replace:
+			return nDst, nSrc, ErrInvalidUTF8
+		case r == utf8.RuneError && size == 1, !ok && e.replace:
+			b = e.charmap.sub
+		case !ok:
+			return nDst, nSrc, &UnsupportedRuneError{Rune: r, Codepage: e.charmap.name}
+		}
+		dst[nDst] = b
+		nDst++
+		nSrc += size
+	}
+	return nDst, nSrc, nil
+}
+
+func (e *charmapEncoder) replacing() Transformer {
+	return &charmapEncoder{charmap: e.charmap, replace: true}
+}
+
+// ReplaceUnsupported returns an Encoder writing the substitute character of the code page in place of
+// the runes it does not have and of invalid UTF-8, instead of failing.
+func ReplaceUnsupported(e *Encoder) *Encoder {
+	if r, ok := e.Transformer.(interface{ replacing() Transformer }); ok {
+		return &Encoder{r.replacing()}
+	}
+	return e
+}
+
+type utf8Encoding struct{}
+
+// UTF8 is the UTF-8 encoding. Its Decoder replaces invalid UTF-8 by U+FFFD, its Encoder fails on it
+// with ErrInvalidUTF8.
+var UTF8 Encoding = utf8Encoding{}
+
+func (utf8Encoding) String() string {
+	return "UTF-8"
+}
+
+func (utf8Encoding) NewDecoder() *Decoder {
+	return &Decoder{&utf8Validator{replace: true}}
+}
+
+func (utf8Encoding) NewEncoder() *Encoder {
+	return &Encoder{&utf8Validator{}}
+}
+
+type utf8Validator struct {
+	replace bool
+}
+
+func (v *utf8Validator) Reset() {}
+
+func (v *utf8Validator) Transform(dst, src []byte, atEOF bool) (int, int, error) {
+	nDst, nSrc := 0, 0
+	for nSrc < len(src) {
+		if src[nSrc] < utf8.RuneSelf {
+			if nDst == len(dst) {
+				return nDst, nSrc, ErrShortDst
+			}
+			dst[nDst] = src[nSrc]
+			nDst, nSrc = nDst+1, nSrc+1
+			continue
+		}
+		if !atEOF && !utf8.FullRune(src[nSrc:]) {
+			return nDst, nSrc, ErrShortSrc
+		}
+		r, size := utf8.DecodeRune(src[nSrc:])
+		valid := src[nSrc : nSrc+size]
+		if r == utf8.RuneError && size == 1 {
+			if !v.replace {
+				return nDst, nSrc, ErrInvalidUTF8
+			}
+			valid = []byte(string(utf8.RuneError))
+		}
+		if nDst+len(valid) > len(dst) {
+			return nDst, nSrc, ErrShortDst
+		}
+		nDst += copy(dst[nDst:], valid)
+		nSrc += size
+	}
+	return nDst, nSrc, nil
+}
+
+func (v *utf8Validator) replacing() Transformer {
+	return &utf8Validator{replace: true}
+}
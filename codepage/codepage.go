@@ -0,0 +1,125 @@
+// Package codepage converts between UTF-8 and the code pages of z/OS datasets: the EBCDIC code pages
+// IBM-037, IBM-273, IBM-500, IBM-1047 and the IBM-1140 to IBM-1149 euro family, and ISO8859-1.
+//
+// The conversions are table driven and do not depend on iconv or on z/OS. IBM-037, IBM-1047, IBM-1140 and
+// ISO8859-1 use the tables of golang.org/x/text/encoding/charmap. The package is modelled on
+// golang.org/x/text/encoding: an Encoding has a Decoder, turning its bytes into UTF-8, and an Encoder,
+// turning UTF-8 into its bytes, which are golang.org/x/text/transform.Transformers and can be chained
+// with those of golang.org/x/text.
+//
+//	text, err := codepage.IBM1047.NewDecoder().Bytes(record)
+//	w := codepage.IBM1047.NewEncoder().Writer(binaryWriter)
+package codepage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrInvalidUTF8 means that an Encoder was given bytes that are not valid UTF-8.
+var ErrInvalidUTF8 = errors.New("codepage: invalid UTF-8")
+
+// UnsupportedRuneError is returned by an Encoder for a rune its code page cannot represent.
+type UnsupportedRuneError struct {
+	Rune     rune
+	Codepage string
+}
+
+func (e *UnsupportedRuneError) Error() string {
+	return fmt.Sprintf("codepage: rune %U %q is not in %s", e.Rune, e.Rune, e.Codepage)
+}
+
+// Encoding is a code page, with the conversions of its bytes from and to UTF-8.
+type Encoding interface {
+	// NewDecoder returns a Decoder turning bytes of the code page into UTF-8.
+	NewDecoder() *Decoder
+
+	// NewEncoder returns an Encoder turning UTF-8 into bytes of the code page.
+	NewEncoder() *Encoder
+
+	// String returns the name of the code page, as ZOAU spells it (e.g. "IBM-1047").
+	String() string
+}
+
+// Decoder converts bytes of a code page to UTF-8.
+type Decoder struct {
+	Transformer
+}
+
+// Bytes converts b to UTF-8.
+func (d *Decoder) Bytes(b []byte) ([]byte, error) {
+	return transformBytes(d, b)
+}
+
+// String converts s to UTF-8.
+func (d *Decoder) String(s string) (string, error) {
+	b, err := transformBytes(d, []byte(s))
+	return string(b), err
+}
+
+// Reader returns a reader of the content of r converted to UTF-8.
+func (d *Decoder) Reader(r io.Reader) io.Reader {
+	return newReader(r, d)
+}
+
+// Encoder converts UTF-8 to bytes of a code page.
+type Encoder struct {
+	Transformer
+}
+
+// Bytes converts the UTF-8 of b.
+func (e *Encoder) Bytes(b []byte) ([]byte, error) {
+	return transformBytes(e, b)
+}
+
+// String converts the UTF-8 of s.
+func (e *Encoder) String(s string) (string, error) {
+	b, err := transformBytes(e, []byte(s))
+	return string(b), err
+}
+
+// Writer returns a writer converting the UTF-8 written to it before writing it to w. A rune split
+// across writes is converted once complete; Close reports a rune left incomplete.
+func (e *Encoder) Writer(w io.Writer) io.WriteCloser {
+	return newWriter(w, e)
+}
+
+// Encodings known to Lookup.
+var encodings = []Encoding{
+	IBM037, IBM273, IBM500, IBM1047,
+	IBM1140, IBM1141, IBM1142, IBM1143, IBM1144, IBM1145, IBM1146, IBM1147, IBM1148, IBM1149,
+	ISO8859_1, UTF8,
+}
+
+// Lookup returns the Encoding of a code page name, as given to the Encoding arguments of the zoau
+// package or to iconv: "IBM-1047", "IBM1047", "CP1047" and "1047" are the same code page, and so are
+// "ISO8859-1", "ISO-8859-1" and "LATIN1", or "UTF-8" and "UTF8".
+func Lookup(name string) (Encoding, error) {
+	key := canonicalName(name)
+	if key == "LATIN1" || key == "IBM819" {
+		key = "ISO88591"
+	}
+	for _, e := range encodings {
+		if canonicalName(e.String()) == key {
+			return e, nil
+		}
+	}
+	return nil, fmt.Errorf("codepage: unknown code page %q", name)
+}
+
+// canonicalName upper-cases name, removes its separators and spells a number alone as an IBM code page.
+func canonicalName(name string) string {
+	name = strings.ToUpper(strings.NewReplacer("-", "", "_", "", " ", "").Replace(name))
+	if name != "" && strings.Trim(name, "0123456789") == "" {
+		name = "IBM" + name
+	}
+	if strings.HasPrefix(name, "CP") && len(name) > 2 && strings.Trim(name[2:], "0123456789") == "" {
+		name = "IBM" + name[2:]
+	}
+	if strings.HasPrefix(name, "IBM") && len(name) == len("IBM")+2 {
+		name = "IBM0" + name[3:]
+	}
+	return name
+}
@@ -0,0 +1,179 @@
+package codepage_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/Stolkerve/zoau-go/codepage"
+	"golang.org/x/text/transform"
+)
+
+var charmaps = []*codepage.Charmap{
+	codepage.IBM037, codepage.IBM273, codepage.IBM500, codepage.IBM1047,
+	codepage.IBM1140, codepage.IBM1141, codepage.IBM1142, codepage.IBM1143, codepage.IBM1144,
+	codepage.IBM1145, codepage.IBM1146, codepage.IBM1147, codepage.IBM1148, codepage.IBM1149,
+	codepage.ISO8859_1,
+}
+
+func TestRoundTrip(t *testing.T) {
+	all := make([]byte, 256)
+	for i := range all {
+		all[i] = byte(i)
+	}
+	for _, m := range charmaps {
+		text, err := m.NewDecoder().Bytes(all)
+		if err != nil {
+			t.Fatalf("%s: %v", m, err)
+		}
+		back, err := m.NewEncoder().Bytes(text)
+		if err != nil {
+			t.Fatalf("%s: %v", m, err)
+		}
+		if !bytes.Equal(back, all) {
+			t.Fatalf("%s: expected every byte back, got %x", m, back)
+		}
+	}
+}
+
+func TestKnownBytes(t *testing.T) {
+	cases := []struct {
+		charmap *codepage.Charmap
+		text    string
+		bytes   []byte
+	}{
+		{codepage.IBM1047, "Hello, World!", []byte{0xC8, 0x85, 0x93, 0x93, 0x96, 0x6B, 0x40, 0xE6, 0x96, 0x99, 0x93, 0x84, 0x5A}},
+		{codepage.IBM1047, "[^]\n", []byte{0xAD, 0x5F, 0xBD, 0x25}},
+		{codepage.IBM037, "[¬]", []byte{0xBA, 0x5F, 0xBB}},
+		{codepage.IBM500, "[!]", []byte{0x4A, 0x4F, 0x5A}},
+		{codepage.IBM273, "Ä{ß", []byte{0x4A, 0x43, 0xA1}},
+		{codepage.IBM1140, "€1", []byte{0x9F, 0xF1}},
+		{codepage.IBM1141, "€", []byte{0x9F}},
+		{codepage.IBM1148, "€", []byte{0x9F}},
+		{codepage.ISO8859_1, "café", []byte{'c', 'a', 'f', 0xE9}},
+	}
+	for _, tc := range cases {
+		encoded, err := tc.charmap.NewEncoder().String(tc.text)
+		if err != nil || encoded != string(tc.bytes) {
+			t.Fatalf("%s: expected %x for %q, got %x, %v", tc.charmap, tc.bytes, tc.text, encoded, err)
+		}
+		decoded, err := tc.charmap.NewDecoder().Bytes(tc.bytes)
+		if err != nil || string(decoded) != tc.text {
+			t.Fatalf("%s: expected %q for %x, got %q, %v", tc.charmap, tc.text, tc.bytes, decoded, err)
+		}
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	_, err := codepage.IBM037.NewEncoder().String("price: 5€")
+	var unsupported *codepage.UnsupportedRuneError
+	if !errors.As(err, &unsupported) || unsupported.Rune != '€' || unsupported.Codepage != "IBM-037" {
+		t.Fatalf("expected: an unsupported rune error, got %v", err)
+	}
+	if _, err := codepage.IBM1047.NewEncoder().Bytes([]byte{'A', 0xFF}); err != codepage.ErrInvalidUTF8 {
+		t.Fatalf("expected: %v, got %v", codepage.ErrInvalidUTF8, err)
+	}
+
+	out, err := codepage.ReplaceUnsupported(codepage.IBM037.NewEncoder()).String("5€")
+	if err != nil || out != "\xF5\x3F" {
+		t.Fatalf("expected: the substitute character, got %x, %v", out, err)
+	}
+	out, err = codepage.ReplaceUnsupported(codepage.ISO8859_1.NewEncoder()).String("5€")
+	if err != nil || out != "5\x1A" {
+		t.Fatalf("expected: the substitute character, got %x, %v", out, err)
+	}
+}
+
+func TestReaderWriter(t *testing.T) {
+	text := strings.Repeat("Grüße, €uro - ok\n", 1000)
+	encoded, err := codepage.IBM1141.NewEncoder().String(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Reading one byte at a time exercises every buffer boundary.
+	decoded, err := io.ReadAll(codepage.IBM1141.NewDecoder().Reader(iotest.OneByteReader(strings.NewReader(encoded))))
+	if err != nil || string(decoded) != text {
+		t.Fatalf("Unexpected decoded content, %v", err)
+	}
+	if err := iotest.TestReader(codepage.IBM1141.NewDecoder().Reader(strings.NewReader(encoded)), []byte(text)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Runes split across writes are encoded once complete.
+	var out bytes.Buffer
+	w := codepage.IBM1141.NewEncoder().Writer(&out)
+	for i := 0; i < len(text); i += 7 {
+		if n, err := w.Write([]byte(text[i:min(i+7, len(text))])); err != nil || n != min(7, len(text)-i) {
+			t.Fatalf("Unexpected write %d, %v", n, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != encoded {
+		t.Fatal("Unexpected encoded content")
+	}
+
+	w = codepage.IBM1141.NewEncoder().Writer(&out)
+	if _, err := w.Write([]byte("€")[:2]); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != codepage.ErrInvalidUTF8 {
+		t.Fatalf("expected: %v for an incomplete rune, got %v", codepage.ErrInvalidUTF8, err)
+	}
+}
+
+func TestTextTransform(t *testing.T) {
+	// Converting a code page to another chains the Decoder of one with the Encoder of the other.
+	convert := transform.Chain(codepage.IBM037.NewDecoder(), codepage.IBM1047.NewEncoder())
+	src, err := codepage.IBM037.NewEncoder().String("[a^b]|¬")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, _ := codepage.IBM1047.NewEncoder().String("[a^b]|¬")
+	if out, _, err := transform.String(convert, src); err != nil || out != expected {
+		t.Fatalf("expected: %x, got %x, %v", expected, out, err)
+	}
+
+	// A short destination is reported with the error of golang.org/x/text.
+	if _, _, err := codepage.IBM1047.NewDecoder().Transform(make([]byte, 1), []byte("ab"), true); err != transform.ErrShortDst {
+		t.Fatalf("expected: %v, got %v", transform.ErrShortDst, err)
+	}
+}
+
+func TestUTF8(t *testing.T) {
+	if out, err := codepage.UTF8.NewDecoder().String("ok\xFF"); err != nil || out != "ok�" {
+		t.Fatalf("Unexpected decoding %q, %v", out, err)
+	}
+	if _, err := codepage.UTF8.NewEncoder().String("ok\xFF"); err != codepage.ErrInvalidUTF8 {
+		t.Fatalf("expected: %v, got %v", codepage.ErrInvalidUTF8, err)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	for name, expected := range map[string]codepage.Encoding{
+		"IBM-1047":   codepage.IBM1047,
+		"ibm1047":    codepage.IBM1047,
+		"CP1047":     codepage.IBM1047,
+		"1047":       codepage.IBM1047,
+		"IBM-37":     codepage.IBM037,
+		"cp037":      codepage.IBM037,
+		"IBM-1149":   codepage.IBM1149,
+		"ISO8859-1":  codepage.ISO8859_1,
+		"ISO-8859-1": codepage.ISO8859_1,
+		"latin1":     codepage.ISO8859_1,
+		"UTF-8":      codepage.UTF8,
+		"utf8":       codepage.UTF8,
+	} {
+		if e, err := codepage.Lookup(name); err != nil || e != expected {
+			t.Fatalf("%s: expected %v, got %v, %v", name, expected, e, err)
+		}
+	}
+	if _, err := codepage.Lookup("IBM-930"); err == nil {
+		t.Fatal("An unknown code page must be rejected")
+	}
+}
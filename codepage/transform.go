@@ -0,0 +1,33 @@
+package codepage
+
+import (
+	"io"
+
+	"golang.org/x/text/transform"
+)
+
+var (
+	// ErrShortDst means that the destination buffer was too short to receive all of the transformed bytes.
+	ErrShortDst = transform.ErrShortDst
+
+	// ErrShortSrc means that the source buffer has insufficient data to complete the transformation.
+	ErrShortSrc = transform.ErrShortSrc
+)
+
+// Transformer transforms bytes. The Decoders and Encoders of the package are
+// golang.org/x/text/transform.Transformers, so they chain with those of golang.org/x/text.
+type Transformer = transform.Transformer
+
+// transformBytes runs t over the whole of src.
+func transformBytes(t Transformer, src []byte) ([]byte, error) {
+	dst, _, err := transform.Bytes(t, src)
+	return dst, err
+}
+
+func newReader(r io.Reader, t Transformer) io.Reader {
+	return transform.NewReader(r, t)
+}
+
+func newWriter(w io.Writer, t Transformer) io.WriteCloser {
+	return transform.NewWriter(w, t)
+}
@@ -0,0 +1,226 @@
+package zoau
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	compareHeaderRegex  = regexp.MustCompile(`^\s*NEW:\s+(\S+)\s+OLD:\s+(\S+)`)
+	compareLineRegex    = regexp.MustCompile(`^\s{1,3}([ID]) - (.*)$`)
+	compareDeltaRegex   = regexp.MustCompile(`\s+(INS|DEL|RPL)\s+(\d+)\s+(\d+)\s+(\d+)\s*$`)
+	compareSummaryRegex = regexp.MustCompile(`(\d+)\s+(NUMBER OF LINE MATCHES|REFORMATTED LINES|NEW FILE LINE INSERTIONS|OLD FILE LINE DELETIONS|NEW FILE LINES PROCESSED|OLD FILE LINES PROCESSED|TOTAL CHANGES|PAIRED CHANGES|NON-PAIRED INSERTS|NON-PAIRED DELETES)`)
+	compareMemberRegex  = regexp.MustCompile(`^(.*\s|)([A-Z@#$][A-Z0-9@#$]{0,7})\s+(\d+)(?:\s+(\d+))?\s*$`)
+)
+
+// ParseCompareListing parses the SuperC (ISRSUPC) line compare listing produced by ddiff: the page
+// headers naming the NEW and OLD files, the delta lines of the LISTING OUTPUT SECTION, the MEMBER
+// SUMMARY LISTING and NON-PAIRED NEW/OLD MEMBERS sections of a partitioned dataset compare, and the
+// LINE COMPARE SUMMARY AND STATISTICS section. Equal is set when the listing reports no change.
+func ParseCompareListing(listing string) *CompareResult {
+	result := &CompareResult{Listing: listing}
+	members := make(map[string]int)
+	member := func(name string) *MemberCompare {
+		i, ok := members[name]
+		if !ok {
+			i = len(result.Members)
+			members[name] = i
+			result.Members = append(result.Members, MemberCompare{Name: name, Status: MEMBER_COMPARE_CHANGED})
+		}
+		return &result.Members[i]
+	}
+	summary := map[string]*uint{
+		"NUMBER OF LINE MATCHES":   &result.Summary.LineMatches,
+		"REFORMATTED LINES":        &result.Summary.ReformattedLines,
+		"NEW FILE LINE INSERTIONS": &result.Summary.Insertions,
+		"OLD FILE LINE DELETIONS":  &result.Summary.Deletions,
+		"NEW FILE LINES PROCESSED": &result.Summary.NewLinesProcessed,
+		"OLD FILE LINES PROCESSED": &result.Summary.OldLinesProcessed,
+		"TOTAL CHANGES":            &result.Summary.TotalChanges,
+		"PAIRED CHANGES":           &result.Summary.PairedChanges,
+		"NON-PAIRED INSERTS":       &result.Summary.NonPairedInserts,
+		"NON-PAIRED DELETES":       &result.Summary.NonPairedDeletes,
+	}
+
+	const (
+		sectionNone = iota
+		sectionMembers
+		sectionNewMembers
+		sectionOldMembers
+	)
+	section := sectionNone
+	diffColumn, sameColumn := -1, -1
+	current := ""
+	var hunk *DiffHunk
+
+	for _, line := range strings.Split(listing, "\n") {
+		line = strings.TrimRight(line, " \r")
+		switch {
+		case strings.Contains(line, "MEMBER SUMMARY LISTING"):
+			section = sectionMembers
+			continue
+		case strings.Contains(line, "NON-PAIRED NEW MEMBERS"):
+			section = sectionNewMembers
+			continue
+		case strings.Contains(line, "NON-PAIRED OLD MEMBERS"):
+			section = sectionOldMembers
+			continue
+		case strings.HasPrefix(line, "1") || strings.Contains(line, "LISTING OUTPUT SECTION") || strings.Contains(line, "LINE COMPARE SUMMARY"):
+			section = sectionNone
+			continue
+		}
+
+		if m := compareHeaderRegex.FindStringSubmatch(line); m != nil {
+			section = sectionNone
+			newName, newMember := splitMemberReference(m[1])
+			oldName, _ := splitMemberReference(m[2])
+			if result.New == "" {
+				result.New, result.Old = newName, oldName
+			}
+			current, hunk = newMember, nil
+			continue
+		}
+		if m := compareSummaryRegex.FindAllStringSubmatch(line, -1); m != nil {
+			for _, field := range m {
+				n, _ := strconv.ParseUint(field[1], 10, 0)
+				*summary[field[2]] = uint(n)
+			}
+			continue
+		}
+
+		if m := compareLineRegex.FindStringSubmatch(line); m != nil {
+			text := m[2]
+			if delta := compareDeltaRegex.FindStringSubmatchIndex(text); delta != nil {
+				newLine, _ := strconv.ParseUint(text[delta[6]:delta[7]], 10, 0)
+				oldLine, _ := strconv.ParseUint(text[delta[8]:delta[9]], 10, 0)
+				hunk = &DiffHunk{Kind: text[delta[2]:delta[3]], NewLine: uint(newLine), OldLine: uint(oldLine)}
+				text = strings.TrimRight(text[:delta[0]], " ")
+				if current == "" {
+					result.Hunks = append(result.Hunks, *hunk)
+					hunk = &result.Hunks[len(result.Hunks)-1]
+				} else {
+					m := member(current)
+					m.Hunks = append(m.Hunks, *hunk)
+					hunk = &m.Hunks[len(m.Hunks)-1]
+				}
+			}
+			if hunk == nil {
+				continue
+			}
+			if m[1] == "I" {
+				hunk.Inserted = append(hunk.Inserted, text)
+			} else {
+				hunk.Deleted = append(hunk.Deleted, text)
+			}
+			continue
+		}
+
+		switch section {
+		case sectionMembers:
+			if strings.Contains(line, "DIFF") && strings.Contains(line, "SAME") {
+				diffColumn, sameColumn = strings.Index(line, "DIFF"), strings.Index(line, "SAME")
+				continue
+			}
+			m := compareMemberRegex.FindStringSubmatch(line)
+			if m == nil || m[4] == "" {
+				continue
+			}
+			info := member(m[2])
+			info.NewLines, info.OldLines = parseUint(m[3]), parseUint(m[4])
+			info.Status = MEMBER_COMPARE_CHANGED
+			if mark := strings.Index(m[1], "**"); mark >= 0 && sameColumn >= 0 && abs(mark-sameColumn) < abs(mark-diffColumn) {
+				info.Status = MEMBER_COMPARE_SAME
+			}
+		case sectionNewMembers, sectionOldMembers:
+			m := compareMemberRegex.FindStringSubmatch(line)
+			if m == nil || strings.TrimSpace(m[1]) != "" || m[4] != "" {
+				continue
+			}
+			info := member(m[2])
+			if section == sectionNewMembers {
+				info.Status, info.NewLines = MEMBER_COMPARE_NEW_ONLY, parseUint(m[3])
+			} else {
+				info.Status, info.OldLines = MEMBER_COMPARE_OLD_ONLY, parseUint(m[3])
+			}
+		}
+	}
+
+	sort.Slice(result.Members, func(i, j int) bool { return result.Members[i].Name < result.Members[j].Name })
+	result.Equal = result.Summary.TotalChanges == 0 && len(result.Hunks) == 0
+	for _, m := range result.Members {
+		if m.Status != MEMBER_COMPARE_SAME {
+			result.Equal = false
+		}
+	}
+	return result
+}
+
+// splitMemberReference splits "DSN(MEMBER)" into the dataset name and the member name.
+func splitMemberReference(ref string) (string, string) {
+	if open := strings.IndexByte(ref, '('); open >= 0 && strings.HasSuffix(ref, ")") {
+		return ref[:open], ref[open+1 : len(ref)-1]
+	}
+	return ref, ""
+}
+
+func parseUint(s string) uint {
+	n, _ := strconv.ParseUint(s, 10, 0)
+	return uint(n)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// UnifiedDiff renders the result as a unified diff, as "diff -U0" does: SuperC lists the changed lines
+// only, so the hunks have no context lines and apply with "patch" or "git apply --unidiff-zero".
+// Members found in a single dataset of a partitioned dataset compare are reported by "Only in" lines.
+func (r *CompareResult) UnifiedDiff() string {
+	var out strings.Builder
+	writeUnifiedDiff(&out, r.Old, r.New, r.Hunks)
+	for _, m := range r.Members {
+		switch m.Status {
+		case MEMBER_COMPARE_NEW_ONLY:
+			fmt.Fprintf(&out, "Only in %s: %s\n", r.New, m.Name)
+		case MEMBER_COMPARE_OLD_ONLY:
+			fmt.Fprintf(&out, "Only in %s: %s\n", r.Old, m.Name)
+		default:
+			writeUnifiedDiff(&out, r.Old+"("+m.Name+")", r.New+"("+m.Name+")", m.Hunks)
+		}
+	}
+	return out.String()
+}
+
+func writeUnifiedDiff(out *strings.Builder, old string, new string, hunks []DiffHunk) {
+	if len(hunks) == 0 {
+		return
+	}
+	fmt.Fprintf(out, "--- %s\n+++ %s\n", old, new)
+	for _, hunk := range hunks {
+		fmt.Fprintf(out, "@@ -%s +%s @@\n", unifiedRange(hunk.OldLine, len(hunk.Deleted)), unifiedRange(hunk.NewLine, len(hunk.Inserted)))
+		for _, line := range hunk.Deleted {
+			fmt.Fprintf(out, "-%s\n", line)
+		}
+		for _, line := range hunk.Inserted {
+			fmt.Fprintf(out, "+%s\n", line)
+		}
+	}
+}
+
+// unifiedRange formats the start,count range of a hunk. An empty range starts at the line before it.
+func unifiedRange(line uint, count int) string {
+	switch {
+	case count == 0:
+		return fmt.Sprintf("%d,0", max(line, 1)-1)
+	case count == 1:
+		return strconv.FormatUint(uint64(line), 10)
+	default:
+		return fmt.Sprintf("%d,%d", line, count)
+	}
+}
@@ -0,0 +1,163 @@
+package zoau_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/Stolkerve/zoau-go"
+	"github.com/Stolkerve/zoau-go/zoautest"
+)
+
+func TestCompareResult(t *testing.T) {
+	ctx := context.Background()
+	c := zoau.NewClient(&zoau.ClientArgs{Executor: zoautest.NewSimulator("USER")})
+
+	for name, content := range map[string]string{
+		"USER.OLD": "A\nB\nC\nD\nE\nF",
+		"USER.NEW": "A\nX\nC\nE\nF\nG\nH",
+	} {
+		if _, err := c.Create(ctx, name, nil); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.Write(ctx, name, content, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	res, err := c.Compare(ctx, "USER.OLD", "USER.NEW", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Equal || res.Old != "USER.OLD" || res.New != "USER.NEW" {
+		t.Fatalf("Unexpected result %+v", res)
+	}
+	expected := []zoau.DiffHunk{
+		{Kind: zoau.DIFF_REPLACE, NewLine: 2, OldLine: 2, Inserted: []string{"X"}, Deleted: []string{"B"}},
+		{Kind: zoau.DIFF_DELETE, NewLine: 4, OldLine: 4, Deleted: []string{"D"}},
+		{Kind: zoau.DIFF_INSERT, NewLine: 6, OldLine: 7, Inserted: []string{"G", "H"}},
+	}
+	if !reflect.DeepEqual(res.Hunks, expected) {
+		t.Fatalf("expected: %+v, got %+v", expected, res.Hunks)
+	}
+	summary := zoau.CompareSummary{
+		LineMatches:       4,
+		Insertions:        3,
+		Deletions:         2,
+		NewLinesProcessed: 7,
+		OldLinesProcessed: 6,
+		TotalChanges:      3,
+		PairedChanges:     1,
+		NonPairedInserts:  1,
+		NonPairedDeletes:  1,
+	}
+	if res.Summary != summary {
+		t.Fatalf("expected: %+v, got %+v", summary, res.Summary)
+	}
+
+	diff := `--- USER.OLD
++++ USER.NEW
+@@ -2 +2 @@
+-B
++X
+@@ -4 +3,0 @@
+-D
+@@ -6,0 +6,2 @@
++G
++H
+`
+	if res.UnifiedDiff() != diff {
+		t.Fatalf("expected: %s, got %s", diff, res.UnifiedDiff())
+	}
+
+	if res, err := c.Compare(ctx, "USER.OLD", "USER.OLD", nil); err != nil || !res.Equal || len(res.Hunks) != 0 || res.UnifiedDiff() != "" {
+		t.Fatalf("expected: equal, got %+v, %v", res, err)
+	}
+}
+
+func TestCompareMembers(t *testing.T) {
+	ctx := context.Background()
+	c := zoau.NewClient(&zoau.ClientArgs{Executor: zoautest.NewSimulator("USER")})
+
+	for _, name := range []string{"USER.OLD.PDS", "USER.NEW.PDS"} {
+		if _, err := c.Create(ctx, name, &zoau.CreateArgs{Type: zoau.String(zoau.DS_ORG_PDSE)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, content := range map[string]string{
+		"USER.OLD.PDS(SAME)":    "1\n2",
+		"USER.NEW.PDS(SAME)":    "1\n2",
+		"USER.OLD.PDS(CHANGED)": "1\n2\n3",
+		"USER.NEW.PDS(CHANGED)": "1\n3",
+		"USER.OLD.PDS(GONE)":    "1",
+		"USER.NEW.PDS(ADDED)":   "1\n2\n3\n4",
+	} {
+		if err := c.Write(ctx, name, content, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	res, err := c.Compare(ctx, "USER.OLD.PDS", "USER.NEW.PDS", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []zoau.MemberCompare{
+		{Name: "ADDED", Status: zoau.MEMBER_COMPARE_NEW_ONLY, NewLines: 4},
+		{Name: "CHANGED", Status: zoau.MEMBER_COMPARE_CHANGED, NewLines: 2, OldLines: 3, Hunks: []zoau.DiffHunk{
+			{Kind: zoau.DIFF_DELETE, NewLine: 2, OldLine: 2, Deleted: []string{"2"}},
+		}},
+		{Name: "GONE", Status: zoau.MEMBER_COMPARE_OLD_ONLY, OldLines: 1},
+		{Name: "SAME", Status: zoau.MEMBER_COMPARE_SAME, NewLines: 2, OldLines: 2},
+	}
+	if res.Equal || res.Old != "USER.OLD.PDS" || res.New != "USER.NEW.PDS" || !reflect.DeepEqual(res.Members, expected) {
+		t.Fatalf("expected: %+v, got %+v", expected, res)
+	}
+
+	diff := `Only in USER.NEW.PDS: ADDED
+--- USER.OLD.PDS(CHANGED)
++++ USER.NEW.PDS(CHANGED)
+@@ -2 +1,0 @@
+-2
+Only in USER.OLD.PDS: GONE
+`
+	if res.UnifiedDiff() != diff {
+		t.Fatalf("expected: %s, got %s", diff, res.UnifiedDiff())
+	}
+}
+
+func TestParseCompareListing(t *testing.T) {
+	listing := `1  ISRSUPC   -   MVS/PDF FILE/LINE/WORD/BYTE/SFOR COMPARE UTILITY- ISPF FOR z/OS         2024/05/14  10.32    PAGE     1
+      NEW: IBMUSER.SRC.NEW                              OLD: IBMUSER.SRC.OLD
+
+                          LISTING OUTPUT SECTION (LINE COMPARE)
+
+  ID       SOURCE LINES                                                                      TYPE    LEN N-LN# O-LN#
+  ----+----1----+----2----+----3----+----4----+----5----+----6----+----7----+----8
+  I - 000200        MOVE 'NEW' TO WS-FLAG.                                              RPL  00003 00012 00012
+  I - 000210        DISPLAY WS-FLAG.
+  D - 000200        MOVE 'OLD' TO WS-FLAG.
+  I - 000900        STOP RUN.                                                           INS  00001 00045 00044
+
+                          LINE COMPARE SUMMARY AND STATISTICS
+
+     42 NUMBER OF LINE MATCHES             2  TOTAL CHANGES (PAIRED+NON PAIRED CHNG)
+      0 REFORMATTED LINES                  1  PAIRED CHANGES (REFM+PAIRED INS/DEL)
+      3 NEW FILE LINE INSERTIONS           1  NON-PAIRED INSERTS
+      1 OLD FILE LINE DELETIONS            0  NON-PAIRED DELETES
+     45 NEW FILE LINES PROCESSED
+     43 OLD FILE LINES PROCESSED
+`
+	res := zoau.ParseCompareListing(listing)
+	expected := []zoau.DiffHunk{
+		{Kind: zoau.DIFF_REPLACE, NewLine: 12, OldLine: 12,
+			Inserted: []string{"000200        MOVE 'NEW' TO WS-FLAG.", "000210        DISPLAY WS-FLAG."},
+			Deleted:  []string{"000200        MOVE 'OLD' TO WS-FLAG."}},
+		{Kind: zoau.DIFF_INSERT, NewLine: 45, OldLine: 44, Inserted: []string{"000900        STOP RUN."}},
+	}
+	if res.Equal || res.Old != "IBMUSER.SRC.OLD" || res.New != "IBMUSER.SRC.NEW" || !reflect.DeepEqual(res.Hunks, expected) {
+		t.Fatalf("expected: %+v, got %+v", expected, res)
+	}
+	if res.Summary.LineMatches != 42 || res.Summary.TotalChanges != 2 || res.Summary.NewLinesProcessed != 45 || res.Summary.OldLinesProcessed != 43 {
+		t.Fatalf("Unexpected summary %+v", res.Summary)
+	}
+}
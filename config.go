@@ -0,0 +1,214 @@
+package zoau
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrUnsupported is returned when a function needs a feature the installed ZOAU release does not provide.
+var ErrUnsupported = errors.New("zoau: unsupported by the installed release")
+
+// Version of a ZOAU release, as reported by zoaversion (e.g. V1.2.3.0).
+type Version struct {
+	Major, Minor, Patch, Fix int
+}
+
+var versionRegex = regexp.MustCompile(`\bV(\d+)\.(\d+)\.(\d+)(?:\.(\d+))?\b`)
+
+// ParseVersion parses a version in the "1.2.3" or "1.2.3.4" form, optionally prefixed by V, or
+// the output of zoaversion.
+func ParseVersion(s string) (Version, error) {
+	match := versionRegex.FindStringSubmatch(s)
+	if match == nil {
+		match = versionRegex.FindStringSubmatch("V" + strings.TrimSpace(s))
+	}
+	if match == nil {
+		return Version{}, fmt.Errorf("zoau: invalid version %q", s)
+	}
+	numbers := make([]int, 4)
+	for i, m := range match[1:] {
+		if m != "" {
+			numbers[i], _ = strconv.Atoi(m)
+		}
+	}
+	return Version{Major: numbers[0], Minor: numbers[1], Patch: numbers[2], Fix: numbers[3]}, nil
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d.%d", v.Major, v.Minor, v.Patch, v.Fix)
+}
+
+// Compare returns -1, 0 or +1 depending on whether v is older, the same or newer than other.
+func (v Version) Compare(other Version) int {
+	a := []int{v.Major, v.Minor, v.Patch, v.Fix}
+	b := []int{other.Major, other.Minor, other.Patch, other.Fix}
+	for i := range a {
+		if a[i] < b[i] {
+			return -1
+		}
+		if a[i] > b[i] {
+			return 1
+		}
+	}
+	return 0
+}
+
+// Capability is a feature of the ZOAU utilities that is not available in every release.
+type Capability int
+
+const (
+	// apfadm -lj, APF list in JSON.
+	CAPABILITY_APF_JSON Capability = iota
+	// dcp -I and -X, copy of aliases and executables.
+	CAPABILITY_COPY_ALIAS
+	// dls -j, dataset list in JSON.
+	CAPABILITY_DLS_JSON
+)
+
+// Release introducing each capability. ZOAU 1.1 lists APF libraries in text only and copies members
+// without their aliases; JSON output of dls came with ZOAU 1.3.
+var capabilities = []struct {
+	Capability Capability
+	Name       string
+	Since      Version
+}{
+	{CAPABILITY_APF_JSON, "apfadm JSON output", Version{Major: 1, Minor: 2}},
+	{CAPABILITY_COPY_ALIAS, "dcp alias and executable copy", Version{Major: 1, Minor: 2}},
+	{CAPABILITY_DLS_JSON, "dls JSON output", Version{Major: 1, Minor: 3}},
+}
+
+func (c Capability) String() string {
+	for _, known := range capabilities {
+		if known.Capability == c {
+			return known.Name
+		}
+	}
+	return fmt.Sprintf("Capability(%d)", int(c))
+}
+
+// Config describes a ZOAU installation.
+type Config struct {
+	// Installation directory.
+	Home string
+
+	// Directory of the ZOAU utilities.
+	BinDir string
+
+	// Directory of the ZOAU dlls.
+	LibDir string
+
+	// Installed release, nil if it is unknown, in which case every capability is assumed.
+	Version *Version
+}
+
+type ConfigArgs struct {
+	// Installation directory. Defaults to $ZOAU_HOME, then to the parent of the PATH directory holding zoaversion.
+	Home *string
+
+	// Executor used to run zoaversion. Defaults to an ExecExecutor using the located installation.
+	Executor Executor
+}
+
+// LoadConfig locates the ZOAU installation and runs zoaversion once to find its release.
+func LoadConfig(ctx context.Context, args *ConfigArgs) (*Config, error) {
+	home := ""
+	if args != nil && args.Home != nil {
+		home = *args.Home
+	} else if env := os.Getenv("ZOAU_HOME"); env != "" {
+		home = env
+	} else {
+		for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+			if info, err := os.Stat(filepath.Join(dir, "zoaversion")); err == nil && !info.IsDir() {
+				home = filepath.Dir(filepath.Clean(dir))
+				break
+			}
+		}
+		if home == "" {
+			return nil, errors.New("zoau: installation not found, set ZOAU_HOME or add its bin directory to PATH")
+		}
+	}
+
+	cfg := &Config{
+		Home:   home,
+		BinDir: filepath.Join(home, "bin"),
+		LibDir: filepath.Join(home, "lib"),
+	}
+	if info, err := os.Stat(cfg.BinDir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("zoau: %s is not a ZOAU installation, %s not found", home, cfg.BinDir)
+	}
+
+	var executor Executor = ExecExecutor{Config: cfg}
+	if args != nil && args.Executor != nil {
+		executor = args.Executor
+	}
+	stdout, err := NewClient(&ClientArgs{Executor: executor}).execSimpleStringCmd(ctx, "zoaversion", nil)
+	if err != nil {
+		return nil, err
+	}
+	version, err := ParseVersion(stdout)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Version = &version
+
+	return cfg, nil
+}
+
+// Env returns the PATH and LIBPATH variables giving access to the installation, in "KEY=value" form.
+func (cfg *Config) Env() []string {
+	return []string{
+		"PATH=" + prependPath(cfg.BinDir, os.Getenv("PATH")),
+		"LIBPATH=" + prependPath(cfg.LibDir, os.Getenv("LIBPATH")),
+	}
+}
+
+func prependPath(dir string, list string) string {
+	if list == "" {
+		return dir
+	}
+	return dir + string(filepath.ListSeparator) + list
+}
+
+// Has reports whether the installed release provides capability.
+func (cfg *Config) Has(capability Capability) bool {
+	if cfg == nil || cfg.Version == nil {
+		return true
+	}
+	for _, known := range capabilities {
+		if known.Capability == capability {
+			return cfg.Version.Compare(known.Since) >= 0
+		}
+	}
+	return false
+}
+
+// Capabilities returns the capabilities of the installed release.
+func (cfg *Config) Capabilities() []Capability {
+	list := make([]Capability, 0, len(capabilities))
+	for _, known := range capabilities {
+		if cfg.Has(known.Capability) {
+			list = append(list, known.Capability)
+		}
+	}
+	return list
+}
+
+// require returns an error wrapping ErrUnsupported if the installed release lacks capability.
+func (cfg *Config) require(capability Capability) error {
+	if cfg.Has(capability) {
+		return nil
+	}
+	for _, known := range capabilities {
+		if known.Capability == capability {
+			return fmt.Errorf("%s requires ZOAU %d.%d.%d, found %s: %w",
+				known.Name, known.Since.Major, known.Since.Minor, known.Since.Patch, cfg.Version, ErrUnsupported)
+		}
+	}
+	return fmt.Errorf("%v: %w", capability, ErrUnsupported)
+}
@@ -0,0 +1,129 @@
+package zoau_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Stolkerve/zoau-go"
+	"github.com/Stolkerve/zoau-go/zoautest"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected zoau.Version
+	}{
+		{"2023/04/06 18:17:48 CUT V1.2.3.0 7ea9a1cf 2873 PH52367 1094 4e14f6ea", zoau.Version{Major: 1, Minor: 2, Patch: 3}},
+		{"V1.1.1", zoau.Version{Major: 1, Minor: 1, Patch: 1}},
+		{"1.3.0.2", zoau.Version{Major: 1, Minor: 3, Fix: 2}},
+	}
+	for _, test := range tests {
+		if v, err := zoau.ParseVersion(test.input); err != nil || v != test.expected {
+			t.Fatalf("%q expected: %v, got %v, %v", test.input, test.expected, v, err)
+		}
+	}
+	if _, err := zoau.ParseVersion("unknown"); err == nil {
+		t.Fatal("expected an error")
+	}
+	if (zoau.Version{Major: 1, Minor: 2}).Compare(zoau.Version{Major: 1, Minor: 1, Patch: 9}) != 1 {
+		t.Fatal("1.2.0 must be newer than 1.1.9")
+	}
+}
+
+func zoauHome(t *testing.T) string {
+	home := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(home, "bin"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, "bin", "zoaversion"), nil, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return home
+}
+
+func TestLoadConfig(t *testing.T) {
+	ctx := context.Background()
+	sim := zoautest.NewSimulator("USER")
+	sim.SetVersion("1.1.1.0")
+
+	explicit := zoauHome(t)
+	cfg, err := zoau.LoadConfig(ctx, &zoau.ConfigArgs{Home: &explicit, Executor: sim})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.BinDir != filepath.Join(explicit, "bin") || cfg.LibDir != filepath.Join(explicit, "lib") {
+		t.Fatalf("Unexpected directories %s and %s", cfg.BinDir, cfg.LibDir)
+	}
+	if cfg.Version == nil || *cfg.Version != (zoau.Version{Major: 1, Minor: 1, Patch: 1}) {
+		t.Fatalf("expected: 1.1.1.0, got %v", cfg.Version)
+	}
+	if len(cfg.Capabilities()) != 0 {
+		t.Fatalf("ZOAU 1.1 has none of the capabilities, got %v", cfg.Capabilities())
+	}
+
+	env := zoauHome(t)
+	t.Setenv("ZOAU_HOME", env)
+	if cfg, err := zoau.LoadConfig(ctx, &zoau.ConfigArgs{Executor: sim}); err != nil || cfg.Home != env {
+		t.Fatalf("expected: %s, got %v, %v", env, cfg, err)
+	}
+
+	path := zoauHome(t)
+	t.Setenv("ZOAU_HOME", "")
+	t.Setenv("PATH", filepath.Join(path, "bin"))
+	if cfg, err := zoau.LoadConfig(ctx, &zoau.ConfigArgs{Executor: sim}); err != nil || cfg.Home != path {
+		t.Fatalf("expected: %s, got %v, %v", path, cfg, err)
+	}
+
+	t.Setenv("PATH", t.TempDir())
+	if _, err := zoau.LoadConfig(ctx, &zoau.ConfigArgs{Executor: sim}); err == nil {
+		t.Fatal("expected an error without installation")
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	ctx := context.Background()
+	sim := zoautest.NewSimulator("USER")
+	c := zoau.NewClient(&zoau.ClientArgs{Executor: sim, Config: &zoau.Config{Version: &zoau.Version{Major: 1, Minor: 1, Patch: 1}}})
+	err := c.Copy(ctx, "USER.LOAD(PGM)", "USER.LOAD(PGM2)", &zoau.CopyArgs{Alias: true})
+	if !errors.Is(err, zoau.ErrUnsupported) {
+		t.Fatalf("expected: %v, got %v", zoau.ErrUnsupported, err)
+	}
+
+	releases := []struct {
+		version  zoau.Version
+		expected []zoau.Capability
+	}{
+		{zoau.Version{Major: 1, Minor: 2, Patch: 5}, []zoau.Capability{zoau.CAPABILITY_APF_JSON, zoau.CAPABILITY_COPY_ALIAS}},
+		{zoau.Version{Major: 1, Minor: 3}, []zoau.Capability{zoau.CAPABILITY_APF_JSON, zoau.CAPABILITY_COPY_ALIAS, zoau.CAPABILITY_DLS_JSON}},
+	}
+	for _, release := range releases {
+		cfg := &zoau.Config{Version: &release.version}
+		if !reflect.DeepEqual(cfg.Capabilities(), release.expected) {
+			t.Fatalf("%v: expected: %v, got %v", release.version, release.expected, cfg.Capabilities())
+		}
+	}
+}
+
+func TestCapabilityFlags(t *testing.T) {
+	ctx := context.Background()
+	for _, release := range []struct {
+		version  zoau.Version
+		expected string
+	}{
+		{zoau.Version{Major: 1, Minor: 1, Patch: 1}, "-l"},
+		{zoau.Version{Major: 1, Minor: 2}, "-lj"},
+	} {
+		fake := &fakeExecutor{}
+		c := zoau.NewClient(&zoau.ClientArgs{Executor: fake, Config: &zoau.Config{Version: &release.version}})
+		if _, _, err := c.Apf(ctx, zoau.ApfArgs{Opt: zoau.Uint(zoau.OPT_LIST)}); err != nil {
+			t.Fatal(err)
+		}
+		if args := fake.commands[0].Args; len(args) != 1 || args[0] != release.expected {
+			t.Fatalf("%v: expected: apfadm %s, got %v", release.version, release.expected, args)
+		}
+	}
+}
@@ -0,0 +1,424 @@
+package copybook
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/Stolkerve/zoau-go/codepage"
+)
+
+// Codec decodes and encodes the records described by a record of a copybook.
+//
+// The fields of a REDEFINES, the redefined one and those redefining it, are all decoded, a field
+// whose bytes are not valid for it decoding to nil instead of failing. Encoding writes the first of
+// them, or the last one present in the values.
+type Codec struct {
+	// Code page of the text and the zoned decimal numbers of DISPLAY fields. NewCodec sets it to
+	// IBM-1047.
+	Encoding codepage.Encoding
+
+	// Remove the trailing spaces of alphanumeric fields when decoding.
+	TrimSpace bool
+
+	record *Field
+}
+
+// NewCodec returns a Codec of the records described by record.
+func NewCodec(record *Field) *Codec {
+	return &Codec{Encoding: codepage.IBM1047, record: record}
+}
+
+// Record returns the description of the records of c.
+func (c *Codec) Record() *Field {
+	return c.record
+}
+
+// state is shared by the decoding and the encoding of a record.
+type state struct {
+	codec  *Codec
+	data   []byte
+	ebcdic bool
+	space  []byte
+
+	// Values of the numeric fields decoded or encoded so far, by upper-cased name, for the tables of
+	// variable length depending on them.
+	counts map[string]int64
+}
+
+func (c *Codec) newState(data []byte) (*state, error) {
+	space, err := c.Encoding.NewEncoder().String(" ")
+	if err != nil {
+		return nil, err
+	}
+	zero, err := c.Encoding.NewEncoder().String("0")
+	if err != nil {
+		return nil, err
+	}
+	return &state{codec: c, data: data, ebcdic: zero == "\xF0", space: []byte(space), counts: map[string]int64{}}, nil
+}
+
+// Decode returns the values of the fields of record. A group is decoded to the map of its fields, a
+// FILLER group to the fields of its parent.
+func (c *Codec) Decode(record []byte) (map[string]any, error) {
+	s, err := c.newState(record)
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]any{}
+	if !c.record.IsGroup() {
+		_, err := s.decodeField(c.record, 0, c.record.Name, false, values)
+		return values, err
+	}
+	_, err = s.decodeGroup(c.record, 0, "", false, values)
+	return values, err
+}
+
+// Encode returns the record of values, as decoded by Decode. Fields missing from values are encoded as
+// spaces, or as zeros for numeric fields. A table of variable length has as many occurrences as the
+// value of its DependingOn field, so the record is as long as they are.
+func (c *Codec) Encode(values map[string]any) ([]byte, error) {
+	s, err := c.newState(make([]byte, c.record.Size))
+	if err != nil {
+		return nil, err
+	}
+	var end int
+	if c.record.IsGroup() {
+		end, err = s.encodeGroup(c.record, 0, "", values)
+	} else {
+		end, err = s.encodeField(c.record, 0, c.record.Name, values[c.record.Name], true)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.data[:end], nil
+}
+
+// redefined returns the fields of g that a later field redefines.
+func redefined(g *Field) map[*Field]bool {
+	fields := map[*Field]bool{}
+	for _, c := range g.Children {
+		if c.Redefines == "" {
+			continue
+		}
+		for _, r := range g.Children {
+			if strings.EqualFold(r.Name, c.Redefines) {
+				fields[r] = true
+			}
+		}
+	}
+	return fields
+}
+
+// qualify returns the qualified name of the field name within the group path.
+func qualify(path, name string) string {
+	if name == "" {
+		name = "FILLER"
+	}
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// occursOf returns the number of occurrences of f in the record.
+func (s *state) occursOf(f *Field, path string, offset int) (int, error) {
+	if f.DependingOn == "" {
+		return f.occurrences(), nil
+	}
+	n := s.counts[strings.ToUpper(f.DependingOn)]
+	if n < int64(f.MinOccurs) || n > int64(f.Occurs) {
+		return 0, &FieldError{Field: path, Offset: offset,
+			Err: fmt.Errorf("%w: %s is %d, out of the %d to %d occurrences", ErrInvalidData, f.DependingOn, n, f.MinOccurs, f.Occurs)}
+	}
+	return int(n), nil
+}
+
+// decodeGroup decodes the fields of g starting at pos into values, and returns the end of g. Fields in
+// a REDEFINES are lenient.
+func (s *state) decodeGroup(g *Field, pos int, path string, lenient bool, values map[string]any) (int, error) {
+	starts := map[string]int{}
+	alternatives := redefined(g)
+	end := pos
+	for _, c := range g.Children {
+		start := end
+		if c.Redefines != "" {
+			start = starts[strings.ToUpper(c.Redefines)]
+		}
+		if c.Name != "" {
+			starts[strings.ToUpper(c.Name)] = start
+		}
+		next, err := s.decodeField(c, start, qualify(path, c.Name), lenient || c.Redefines != "" || alternatives[c], values)
+		if err != nil {
+			return 0, err
+		}
+		end = max(end, next)
+	}
+	return end, nil
+}
+
+// decodeField decodes f, all its occurrences, starting at pos into values, and returns its end.
+func (s *state) decodeField(f *Field, pos int, path string, lenient bool, values map[string]any) (int, error) {
+	n, err := s.occursOf(f, path, pos)
+	if err != nil {
+		if lenient {
+			if f.Name != "" {
+				values[f.Name] = nil
+			}
+			return pos, nil
+		}
+		return 0, err
+	}
+
+	if f.Name == "" {
+		// The fields of a FILLER group are fields of its parent, a FILLER has no value.
+		if !f.IsGroup() || f.Occurs > 0 {
+			return pos + f.Size*n, nil
+		}
+		return s.decodeGroup(f, pos, path, lenient, values)
+	}
+
+	if f.Occurs == 0 {
+		value, end, err := s.decodeOne(f, pos, path, lenient)
+		values[f.Name] = value
+		return end, err
+	}
+	table := make([]any, n)
+	for i := range table {
+		value, end, err := s.decodeOne(f, pos, fmt.Sprintf("%s(%d)", path, i+1), lenient)
+		if err != nil {
+			return 0, err
+		}
+		table[i], pos = value, end
+	}
+	values[f.Name] = table
+	return pos, nil
+}
+
+// decodeOne decodes an occurrence of f at pos.
+func (s *state) decodeOne(f *Field, pos int, path string, lenient bool) (any, int, error) {
+	if f.IsGroup() {
+		values := map[string]any{}
+		end, err := s.decodeGroup(f, pos, path, lenient, values)
+		return values, end, err
+	}
+
+	end := pos + f.Size
+	var value any
+	var err error
+	if end > len(s.data) {
+		err = fmt.Errorf("%w: %d bytes for a field ending at %d", ErrShortRecord, len(s.data), end)
+	} else {
+		value, err = s.decodeElementary(f, s.data[pos:end])
+	}
+	if err != nil {
+		if lenient {
+			return nil, end, nil
+		}
+		return nil, 0, &FieldError{Field: path, Offset: pos, Err: err}
+	}
+	return value, end, nil
+}
+
+func (s *state) decodeElementary(f *Field, b []byte) (any, error) {
+	var number json.Number
+	var err error
+	switch {
+	case f.Usage == USAGE_NATIONAL:
+		units := make([]uint16, len(b)/2)
+		for i := range units {
+			units[i] = uint16(b[2*i])<<8 | uint16(b[2*i+1])
+		}
+		text := string(utf16.Decode(units))
+		if s.codec.TrimSpace {
+			text = strings.TrimRight(text, " ")
+		}
+		return text, nil
+	case f.isText():
+		text, err := s.codec.Encoding.NewDecoder().Bytes(b)
+		if err != nil {
+			return nil, err
+		}
+		if s.codec.TrimSpace {
+			text = []byte(strings.TrimRight(string(text), " "))
+		}
+		return string(text), nil
+	case f.Usage == USAGE_DISPLAY:
+		number, err = decodeZoned(f, b, s.ebcdic)
+	case f.Usage == USAGE_PACKED:
+		number, err = decodePacked(f, b)
+	case f.Usage == USAGE_BINARY || f.Usage == USAGE_NATIVE_BINARY:
+		number = decodeBinary(f, b)
+	default:
+		return decodeHexFloat(b), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.count(f, number)
+	return number, nil
+}
+
+// count remembers the value of an integer field, for the tables depending on it.
+func (s *state) count(f *Field, number json.Number) {
+	if f.Scale == 0 && f.Occurs == 0 {
+		if n, err := number.Int64(); err == nil {
+			s.counts[strings.ToUpper(f.Name)] = n
+		}
+	}
+}
+
+// encodeGroup encodes the fields of g starting at pos from values, and returns the end of g.
+func (s *state) encodeGroup(g *Field, pos int, path string, values map[string]any) (int, error) {
+	starts := map[string]int{}
+	end := pos
+	for _, c := range g.Children {
+		start := end
+		if c.Redefines != "" {
+			start = starts[strings.ToUpper(c.Redefines)]
+		}
+		if c.Name != "" {
+			starts[strings.ToUpper(c.Name)] = start
+		}
+
+		var next int
+		var err error
+		value, present := values[c.Name]
+		switch {
+		case c.Name == "" && c.IsGroup() && c.Occurs == 0:
+			next, err = s.encodeGroup(c, start, qualify(path, c.Name), values)
+		case c.Redefines != "" && (!present || value == nil):
+			// An absent alternative leaves the bytes of the redefined field.
+			next = start + c.Size*c.occurrences()
+		default:
+			next, err = s.encodeField(c, start, qualify(path, c.Name), value, c.Name != "")
+		}
+		if err != nil {
+			return 0, err
+		}
+		end = max(end, next)
+	}
+	return end, nil
+}
+
+// encodeField encodes value, all the occurrences of f, at pos and returns the end of f.
+func (s *state) encodeField(f *Field, pos int, path string, value any, named bool) (int, error) {
+	n, err := s.occursOf(f, path, pos)
+	if err != nil {
+		return 0, err
+	}
+	if f.Occurs == 0 {
+		return s.encodeOne(f, pos, path, value)
+	}
+
+	var table reflect.Value
+	if value != nil && named {
+		table = reflect.ValueOf(value)
+		if table.Kind() != reflect.Slice && table.Kind() != reflect.Array {
+			return 0, &FieldError{Field: path, Offset: pos, Err: fmt.Errorf("%w: %T is not a table", ErrInvalidValue, value)}
+		}
+		if table.Len() > n {
+			return 0, &FieldError{Field: path, Offset: pos, Err: fmt.Errorf("%w: %d values for %d occurrences", ErrInvalidValue, table.Len(), n)}
+		}
+	}
+	for i := 0; i < n; i++ {
+		var element any
+		if table.IsValid() && i < table.Len() {
+			element = table.Index(i).Interface()
+		}
+		if pos, err = s.encodeOne(f, pos, fmt.Sprintf("%s(%d)", path, i+1), element); err != nil {
+			return 0, err
+		}
+	}
+	return pos, nil
+}
+
+// encodeOne encodes value, an occurrence of f, at pos.
+func (s *state) encodeOne(f *Field, pos int, path string, value any) (int, error) {
+	if f.IsGroup() {
+		values, ok := value.(map[string]any)
+		if !ok && value != nil {
+			return 0, &FieldError{Field: path, Offset: pos, Err: fmt.Errorf("%w: %T is not a group", ErrInvalidValue, value)}
+		}
+		return s.encodeGroup(f, pos, path, values)
+	}
+	end := pos + f.Size
+	if err := s.encodeElementary(f, s.data[pos:end], value); err != nil {
+		return 0, &FieldError{Field: path, Offset: pos, Err: err}
+	}
+	return end, nil
+}
+
+func (s *state) encodeElementary(f *Field, b []byte, value any) error {
+	if f.Usage == USAGE_FLOAT || f.Usage == USAGE_DOUBLE {
+		return encodeHexFloat(b, value)
+	}
+	if f.Category == CATEGORY_NUMERIC {
+		negative, digits, err := numberOf(f, value)
+		if err != nil {
+			return err
+		}
+		switch f.Usage {
+		case USAGE_DISPLAY:
+			encodeZoned(f, b, negative, digits, s.ebcdic)
+		case USAGE_PACKED:
+			encodePacked(f, b, negative, digits)
+		default:
+			if err := encodeBinary(f, b, negative, digits); err != nil {
+				return err
+			}
+		}
+		if negative {
+			digits = "-" + digits
+		}
+		s.count(f, json.Number(digits))
+		return nil
+	}
+
+	var text string
+	switch v := value.(type) {
+	case nil:
+	case string:
+		text = v
+	case json.Number:
+		text = string(v)
+	default:
+		if rv := reflect.ValueOf(value); rv.Kind() == reflect.String {
+			text = rv.String()
+		} else {
+			return fmt.Errorf("%w: %T is not a string", ErrInvalidValue, value)
+		}
+	}
+
+	if f.Usage == USAGE_NATIONAL {
+		units := utf16.Encode([]rune(text))
+		if 2*len(units) > len(b) {
+			return fmt.Errorf("%w: %q is longer than PIC %s", ErrOverflow, text, f.Picture)
+		}
+		for i := range b {
+			b[i] = 0
+			if i%2 == 1 {
+				b[i] = ' '
+			}
+		}
+		for i, u := range units {
+			b[2*i], b[2*i+1] = byte(u>>8), byte(u)
+		}
+		return nil
+	}
+
+	encoded, err := s.codec.Encoding.NewEncoder().String(text)
+	if err != nil {
+		return err
+	}
+	if len(encoded) > len(b) {
+		return fmt.Errorf("%w: %q is longer than PIC %s", ErrOverflow, text, f.Picture)
+	}
+	n := copy(b, encoded)
+	for i := n; i < len(b); i++ {
+		b[i] = s.space[0]
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+// Package copybook parses the record descriptions of COBOL copybooks and converts the records they
+// describe, as read with the records package, to Go values and back.
+//
+// A record decodes to a map[string]any keyed by the names of its fields: a group is a nested map, a
+// table (OCCURS) is a []any, an alphanumeric field is a string and a numeric field is a json.Number,
+// whose text is exact for any PIC. FILLER fields are left out. Unmarshal and Marshal convert records
+// from and to tagged structs instead, and ExportJSON and ExportCSV convert whole datasets.
+//
+//	text, err := zoau.Read("USER.COPYLIB(CUSTREC)", &zoau.ReadArgs{})
+//	book, err := copybook.Parse(strings.NewReader(text), nil)
+//	codec := copybook.NewCodec(book.Records[0])
+//	fields, err := codec.Decode(record)
+package copybook
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrShortRecord means that a record ends before one of its fields.
+	ErrShortRecord = errors.New("short record")
+
+	// ErrInvalidData means that the bytes of a field are not valid for its PIC and USAGE.
+	ErrInvalidData = errors.New("invalid data")
+
+	// ErrOverflow means that a value has more digits than the PIC of its field, or is out of range of
+	// its usage.
+	ErrOverflow = errors.New("value out of range")
+
+	// ErrInvalidValue means that a value cannot be converted to or from the type of its field.
+	ErrInvalidValue = errors.New("invalid value")
+)
+
+// SyntaxError reports a copybook that cannot be parsed.
+type SyntaxError struct {
+	// Number of the line of the copybook, from 1.
+	Line int
+	Msg  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("copybook: line %d: %s", e.Line, e.Msg)
+}
+
+// FieldError reports a field of a record that cannot be decoded or encoded.
+type FieldError struct {
+	// Qualified name of the field, from the record, with the subscripts of its tables, e.g.
+	// "CUSTOMER.PHONES(2).NUMBER".
+	Field string
+
+	// Offset of the field in the record.
+	Offset int
+
+	// One of the errors of the package, wrapped with details.
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("copybook: field %s at offset %d: %v", e.Field, e.Offset, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+type Usage = string
+
+const (
+	// Zoned decimal numbers, and text.
+	USAGE_DISPLAY Usage = "DISPLAY"
+	// UTF-16 text of PIC N.
+	USAGE_NATIONAL Usage = "NATIONAL"
+	// Big endian binary numbers of COMP, COMP-4 and BINARY, whose values are limited to the digits of
+	// their PIC.
+	USAGE_BINARY Usage = "BINARY"
+	// Big endian binary numbers of COMP-5, whose values span the whole range of their size.
+	USAGE_NATIVE_BINARY Usage = "COMP-5"
+	// Packed decimal numbers of COMP-3 and PACKED-DECIMAL.
+	USAGE_PACKED Usage = "PACKED-DECIMAL"
+	// IBM hexadecimal floating point numbers of COMP-1, 4 bytes long.
+	USAGE_FLOAT Usage = "COMP-1"
+	// IBM hexadecimal floating point numbers of COMP-2, 8 bytes long.
+	USAGE_DOUBLE Usage = "COMP-2"
+)
+
+type Category = string
+
+const (
+	CATEGORY_GROUP        Category = "GROUP"
+	CATEGORY_ALPHANUMERIC Category = "ALPHANUMERIC"
+	CATEGORY_NUMERIC      Category = "NUMERIC"
+	// Numeric edited fields, e.g. PIC ZZ9.99-, which are decoded and encoded as text.
+	CATEGORY_EDITED Category = "EDITED"
+)
+
+// Struct that represents the description of a data item of a copybook: a record, a group or an
+// elementary field.
+type Field struct {
+	Level int
+
+	// Name of the field, empty for FILLER.
+	Name string
+
+	Picture  string
+	Usage    Usage
+	Category Category
+
+	// Digits of a numeric field, of which Scale are decimals, e.g. 7 and 2 for PIC S9(5)V99.
+	Digits int
+	Scale  int
+	Signed bool
+
+	// The sign of a zoned decimal field is on its first digit, or in a byte of its own, instead of
+	// over its last digit.
+	SignLeading  bool
+	SignSeparate bool
+
+	// Maximum number of occurrences of a table, 0 when the field is not a table. A table of variable
+	// length has at least MinOccurs occurrences, as many as the value of the field DependingOn.
+	Occurs      int
+	MinOccurs   int
+	DependingOn string
+
+	// Name of the field whose bytes this field describes again.
+	Redefines string
+
+	// Offset of the field in its record, and size of an occurrence. Offset assumes that the tables of
+	// variable length that precede the field have all their occurrences.
+	Offset int
+	Size   int
+
+	Children []*Field
+	Parent   *Field
+
+	// Line of the entry in the copybook, and whether its PIC is made of N symbols.
+	line     int
+	national bool
+}
+
+// Struct that represents a parsed copybook.
+type Copybook struct {
+	// Records of the copybook, its level 01 and 77 items.
+	Records []*Field
+}
+
+// Record returns the record named name, or nil if the copybook has none.
+func (b *Copybook) Record(name string) *Field {
+	for _, r := range b.Records {
+		if strings.EqualFold(r.Name, name) {
+			return r
+		}
+	}
+	return nil
+}
+
+// Find returns the first field named name within f, f included, or nil.
+func (f *Field) Find(name string) *Field {
+	if strings.EqualFold(f.Name, name) {
+		return f
+	}
+	for _, c := range f.Children {
+		if found := c.Find(name); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// IsGroup tells whether f is a group of fields.
+func (f *Field) IsGroup() bool {
+	return len(f.Children) > 0
+}
+
+// Elementary fields whose bytes are text.
+func (f *Field) isText() bool {
+	return f.Category == CATEGORY_ALPHANUMERIC || f.Category == CATEGORY_EDITED
+}
+
+// occurrences returns the maximum number of occurrences of f, 1 when it is not a table.
+func (f *Field) occurrences() int {
+	if f.Occurs > 0 {
+		return f.Occurs
+	}
+	return 1
+}
+
+// layout computes the size of f and the offsets of its children, starting at offset.
+func (f *Field) layout(offset int) {
+	f.Offset = offset
+	if !f.IsGroup() {
+		return
+	}
+	pos := offset
+	starts := map[string]int{}
+	for _, c := range f.Children {
+		start := pos
+		if c.Redefines != "" {
+			start = starts[strings.ToUpper(c.Redefines)]
+		}
+		c.layout(start)
+		if c.Name != "" {
+			starts[strings.ToUpper(c.Name)] = start
+		}
+		pos = max(pos, start+c.Size*c.occurrences())
+	}
+	f.Size = pos - offset
+}
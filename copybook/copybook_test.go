@@ -0,0 +1,346 @@
+package copybook_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Stolkerve/zoau-go/codepage"
+	"github.com/Stolkerve/zoau-go/copybook"
+	"github.com/Stolkerve/zoau-go/records"
+)
+
+const customer = `
+000100* CUSTOMER MASTER RECORD                                          CUSTREC
+000200 01  CUSTOMER-RECORD.                                             CUSTREC
+000300     05  CUST-ID             PIC 9(6).                            CUSTREC
+000400     05  CUST-NAME           PIC X(10).                           CUSTREC
+000500     05  BALANCE             PIC S9(5)V99 COMP-3.                 CUSTREC
+000600     05  ORDERS              PIC S9(4) COMP.                      CUSTREC
+000700     05  TEMPERATURE         PIC S9(3)V9.                         CUSTREC
+000800     05  FILLER              PIC X(2).                            CUSTREC
+000900     05  PHONE-COUNT         PIC 9.                               CUSTREC
+001000     05  STATUS-CODE         PIC X VALUE 'A'.                     CUSTREC
+001100         88  ACTIVE          VALUE 'A'.                           CUSTREC
+001200     05  PHONES OCCURS 1 TO 3 TIMES                               CUSTREC
+001300             DEPENDING ON PHONE-COUNT.                            CUSTREC
+001400         10  PHONE-TYPE      PIC X.                               CUSTREC
+001500         10  PHONE-NUMBER    PIC 9(7) COMP-5.                     CUSTREC
+`
+
+// Record of customer with two phones.
+const customerHex = "f0f0f0f1f2f3" + "c1d3c9c3c5" + "4040404040" + "0123456d" + "002a" + "f0f1f2d5" + "4040" +
+	"f2" + "c1" + "d4" + "0054b482" + "c8" + "00000007"
+
+func customerValues() map[string]any {
+	return map[string]any{
+		"CUST-ID":     json.Number("123"),
+		"CUST-NAME":   "ALICE",
+		"BALANCE":     json.Number("-1234.56"),
+		"ORDERS":      json.Number("42"),
+		"TEMPERATURE": json.Number("-12.5"),
+		"PHONE-COUNT": json.Number("2"),
+		"STATUS-CODE": "A",
+		"PHONES": []any{
+			map[string]any{"PHONE-TYPE": "M", "PHONE-NUMBER": json.Number("5551234")},
+			map[string]any{"PHONE-TYPE": "H", "PHONE-NUMBER": json.Number("7")},
+		},
+	}
+}
+
+func parse(t *testing.T, src string, args *copybook.ParseArgs) *copybook.Field {
+	t.Helper()
+	book, err := copybook.Parse(strings.NewReader(src), args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return book.Records[0]
+}
+
+func TestParse(t *testing.T) {
+	record := parse(t, customer, nil)
+	if record.Name != "CUSTOMER-RECORD" || record.Size != 45 || len(record.Children) != 9 {
+		t.Fatalf("Unexpected record %+v", record)
+	}
+
+	balance := record.Find("balance")
+	if balance.Offset != 16 || balance.Size != 4 || balance.Digits != 7 || balance.Scale != 2 || !balance.Signed || balance.Usage != copybook.USAGE_PACKED {
+		t.Fatalf("Unexpected BALANCE %+v", balance)
+	}
+	if filler := record.Children[5]; filler.Name != "" || filler.Offset != 26 || filler.Size != 2 {
+		t.Fatalf("Unexpected FILLER %+v", filler)
+	}
+	phones := record.Find("PHONES")
+	if phones.Offset != 30 || phones.Size != 5 || phones.Occurs != 3 || phones.MinOccurs != 1 || phones.DependingOn != "PHONE-COUNT" {
+		t.Fatalf("Unexpected PHONES %+v", phones)
+	}
+	if number := record.Find("PHONE-NUMBER"); number.Offset != 31 || number.Size != 4 || number.Usage != copybook.USAGE_NATIVE_BINARY {
+		t.Fatalf("Unexpected PHONE-NUMBER %+v", number)
+	}
+}
+
+func TestDecodeEncode(t *testing.T) {
+	codec := copybook.NewCodec(parse(t, customer, nil))
+	codec.TrimSpace = true
+	expected, _ := hex.DecodeString(customerHex)
+
+	record, err := codec.Encode(customerValues())
+	if err != nil || !bytes.Equal(record, expected) {
+		t.Fatalf("Unexpected record %x, %v", record, err)
+	}
+	values, err := codec.Decode(record)
+	if err != nil || !reflect.DeepEqual(values, customerValues()) {
+		t.Fatalf("Unexpected values %v, %v", values, err)
+	}
+
+	// The table has as many occurrences as PHONE-COUNT.
+	short := customerValues()
+	short["PHONE-COUNT"] = json.Number("1")
+	short["PHONES"] = short["PHONES"].([]any)[:1]
+	if record, err := codec.Encode(short); err != nil || len(record) != 35 {
+		t.Fatalf("Unexpected record %x, %v", record, err)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	codec := copybook.NewCodec(parse(t, customer, nil))
+	expected, _ := hex.DecodeString(customerHex)
+
+	cases := []struct {
+		record []byte
+		err    error
+		field  string
+		offset int
+	}{
+		{append(append(append([]byte{}, expected[:16]...), 0x01, 0x23, 0x4A, 0x6D), expected[20:]...), copybook.ErrInvalidData, "BALANCE", 16},
+		{expected[:38], copybook.ErrShortRecord, "PHONES(2).PHONE-NUMBER", 36},
+		{append(append(append([]byte{}, expected[:28]...), 0xF4), expected[29:]...), copybook.ErrInvalidData, "PHONES", 30},
+	}
+	for _, tc := range cases {
+		_, err := codec.Decode(tc.record)
+		var fieldErr *copybook.FieldError
+		if !errors.As(err, &fieldErr) || !errors.Is(err, tc.err) || fieldErr.Field != tc.field || fieldErr.Offset != tc.offset {
+			t.Fatalf("expected: %v in %s at %d, got %v", tc.err, tc.field, tc.offset, err)
+		}
+	}
+
+	for field, value := range map[string]any{
+		"CUST-ID":   1234567,
+		"BALANCE":   "12.345",
+		"ORDERS":    json.Number("10000"),
+		"CUST-NAME": "A NAME TOO LONG",
+	} {
+		values := customerValues()
+		values[field] = value
+		if _, err := codec.Encode(values); !errors.Is(err, copybook.ErrOverflow) {
+			t.Fatalf("%s: expected: %v, got %v", field, copybook.ErrOverflow, err)
+		}
+	}
+}
+
+const transaction = `
+01 TRANSACTION.
+   05 TXN-TYPE        PIC X.
+   05 TXN-DATA        PIC X(6).
+   05 TXN-AMOUNT      REDEFINES TXN-DATA PIC S9(9)V99 PACKED-DECIMAL.
+   05 TXN-DATE        REDEFINES TXN-DATA.
+      10 TXN-YEAR     PIC 99.
+      10 TXN-MONTH    PIC 99.
+      10 TXN-DAY      PIC 99.
+   05 RATE            COMP-2.
+   05 RATE-SHORT      USAGE IS COMPUTATIONAL-1.
+   05 ADJUSTMENT      PIC S999 SIGN IS LEADING SEPARATE CHARACTER.
+   05 COUNTS          COMP.
+      10 COUNT-A      PIC 9(3).
+      10 COUNT-B      PIC S9(9).
+   05 LABEL-TEXT      PIC N(2).
+   05 EDITED-AMOUNT   PIC ZZ9.99-.  *> numeric edited
+`
+
+func TestRedefines(t *testing.T) {
+	record := parse(t, transaction, &copybook.ParseArgs{FreeFormat: true})
+	if record.Size != 1+6+8+4+4+2+4+4+7 || record.Find("COUNT-B").Size != 4 || record.Find("TXN-DATE").Offset != 1 {
+		t.Fatalf("Unexpected layout of %d bytes", record.Size)
+	}
+	codec := copybook.NewCodec(record)
+
+	data, err := hex.DecodeString("c4" + "f2f4f0f1f1f5" + "4110000000000000" + "c276a000" + "60f0f4f2" +
+		"0007" + "fffffffe" + "00480069" + "40f1f24bf5f060")
+	if err != nil {
+		t.Fatal(err)
+	}
+	values, err := codec.Decode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]any{
+		"TXN-TYPE":   "D",
+		"TXN-DATA":   "240115",
+		"TXN-AMOUNT": nil,
+		"TXN-DATE": map[string]any{
+			"TXN-YEAR": json.Number("24"), "TXN-MONTH": json.Number("1"), "TXN-DAY": json.Number("15"),
+		},
+		"RATE":          json.Number("1"),
+		"RATE-SHORT":    json.Number("-118.625"),
+		"ADJUSTMENT":    json.Number("-42"),
+		"COUNTS":        map[string]any{"COUNT-A": json.Number("7"), "COUNT-B": json.Number("-2")},
+		"LABEL-TEXT":    "Hi",
+		"EDITED-AMOUNT": " 12.50-",
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Fatalf("Unexpected values %v", values)
+	}
+
+	// The redefined field is encoded unless a redefining one is given.
+	delete(values, "TXN-DATE")
+	encoded, err := codec.Encode(values)
+	if err != nil || !bytes.Equal(encoded, data) {
+		t.Fatalf("Unexpected record %x, %v", encoded, err)
+	}
+	values["TXN-AMOUNT"] = -1.5
+	encoded, err = codec.Encode(values)
+	if err != nil || hex.EncodeToString(encoded[1:7]) != "00000000150d" {
+		t.Fatalf("Unexpected record %x, %v", encoded, err)
+	}
+}
+
+func TestZonedASCII(t *testing.T) {
+	record := parse(t, "01 R.\n 05 A PIC S999.\n 05 B PIC S999.\n 05 C PIC 99.\n", &copybook.ParseArgs{FreeFormat: true})
+	codec := copybook.NewCodec(record)
+	codec.Encoding = codepage.ISO8859_1
+	encoded, err := codec.Encode(map[string]any{"A": -125, "B": 120, "C": 7})
+	if err != nil || string(encoded) != "12N12{07" {
+		t.Fatalf("Unexpected record %q, %v", encoded, err)
+	}
+	values, err := codec.Decode([]byte("12u12007"))
+	if err != nil || values["A"] != json.Number("-125") || values["B"] != json.Number("120") {
+		t.Fatalf("Unexpected values %v, %v", values, err)
+	}
+}
+
+type phone struct {
+	Type   string `copybook:"PHONE-TYPE"`
+	Number int64  `copybook:"PHONE-NUMBER"`
+}
+
+type customerRecord struct {
+	CustID      int
+	CustName    string `copybook:"cust-name"`
+	Balance     float64
+	Orders      *int16
+	Temperature json.Number
+	PhoneCount  uint8
+	Phones      []phone
+	Ignored     string `copybook:"-"`
+}
+
+func TestStruct(t *testing.T) {
+	codec := copybook.NewCodec(parse(t, customer, nil))
+	codec.TrimSpace = true
+	record, _ := hex.DecodeString(customerHex)
+
+	var c customerRecord
+	if err := codec.Unmarshal(record, &c); err != nil {
+		t.Fatal(err)
+	}
+	if c.CustID != 123 || c.CustName != "ALICE" || c.Balance != -1234.56 || *c.Orders != 42 || c.Temperature != "-12.5" ||
+		c.PhoneCount != 2 || len(c.Phones) != 2 || c.Phones[0] != (phone{"M", 5551234}) || c.Phones[1] != (phone{"H", 7}) {
+		t.Fatalf("Unexpected struct %+v", c)
+	}
+
+	// STATUS-CODE is not in the struct, so it is encoded as a space.
+	encoded, err := codec.Marshal(c)
+	if err != nil || !bytes.Equal(encoded[:29], record[:29]) || encoded[29] != 0x40 || !bytes.Equal(encoded[30:], record[30:]) {
+		t.Fatalf("Unexpected record %x, %v", encoded, err)
+	}
+
+	var small struct{ Orders int8 }
+	values := customerValues()
+	values["ORDERS"] = 300
+	record, _ = codec.Encode(values)
+	if err := codec.Unmarshal(record, &small); !errors.Is(err, copybook.ErrInvalidValue) {
+		t.Fatalf("expected: %v, got %v", copybook.ErrInvalidValue, err)
+	}
+}
+
+func TestExport(t *testing.T) {
+	codec := copybook.NewCodec(parse(t, customer, nil))
+	codec.TrimSpace = true
+	format := records.Format{Recfm: "VB", Lrecl: 49}
+
+	var image bytes.Buffer
+	w, _ := records.NewWriter(&image, format)
+	first, _ := hex.DecodeString(customerHex)
+	values := customerValues()
+	values["CUST-NAME"], values["PHONE-COUNT"], values["PHONES"] = `BOB "B"`, 1, []any{map[string]any{"PHONE-TYPE": "W", "PHONE-NUMBER": 1}}
+	second, err := codec.Encode(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(first); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(second); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	r, _ := records.NewReader(bytes.NewReader(image.Bytes()), format)
+	if err := codec.ExportJSON(&out, r); err != nil {
+		t.Fatal(err)
+	}
+	expected := `[
+  {"CUST-ID":123,"CUST-NAME":"ALICE","BALANCE":-1234.56,"ORDERS":42,"TEMPERATURE":-12.5,"PHONE-COUNT":2,"STATUS-CODE":"A","PHONES":[{"PHONE-TYPE":"M","PHONE-NUMBER":5551234},{"PHONE-TYPE":"H","PHONE-NUMBER":7}]},
+  {"CUST-ID":123,"CUST-NAME":"BOB \"B\"","BALANCE":-1234.56,"ORDERS":42,"TEMPERATURE":-12.5,"PHONE-COUNT":1,"STATUS-CODE":"A","PHONES":[{"PHONE-TYPE":"W","PHONE-NUMBER":1}]}
+]
+`
+	if out.String() != expected {
+		t.Fatalf("Unexpected JSON %s", out.String())
+	}
+	if !json.Valid(out.Bytes()) {
+		t.Fatal("Invalid JSON")
+	}
+
+	out.Reset()
+	r, _ = records.NewReader(bytes.NewReader(image.Bytes()), format)
+	if err := codec.ExportCSV(&out, r); err != nil {
+		t.Fatal(err)
+	}
+	expected = `CUST-ID,CUST-NAME,BALANCE,ORDERS,TEMPERATURE,PHONE-COUNT,STATUS-CODE,PHONES(1).PHONE-TYPE,PHONES(1).PHONE-NUMBER,PHONES(2).PHONE-TYPE,PHONES(2).PHONE-NUMBER,PHONES(3).PHONE-TYPE,PHONES(3).PHONE-NUMBER
+123,ALICE,-1234.56,42,-12.5,2,A,M,5551234,H,7,,
+123,"BOB ""B""",-1234.56,42,-12.5,1,A,W,1,,,,
+`
+	if out.String() != expected {
+		t.Fatalf("Unexpected CSV %s", out.String())
+	}
+}
+
+func TestSyntaxErrors(t *testing.T) {
+	for src, line := range map[string]int{
+		"01 R.\n 05 A PIC X(3.\n":                     2,
+		"01 R.\n 05 A.\n":                             2,
+		"01 R.\n 05 A PIC X.\n 05 A PIC X.\n":         3,
+		"01 R.\n 05 A PIC X COMP-3.\n":                2,
+		"01 R.\n 05 A PIC 9 OCCURS 1 TO 3.\n":         2,
+		"01 R.\n 05 B PIC X.\n 05 C REDEFINES A.\n":   3,
+		"01 R.\n 05 A PIC X OCCURS 2 DEPENDING ON B.": 2,
+		"01 R.\n 66 A RENAMES B.\n":                   2,
+		"05 A PIC X.\n":                               1,
+		"01 R.\n 05 A PIC PPP99.\n":                   2,
+		"01 R.\n\n 05 A PIC X BOGUS.\n":               3,
+	} {
+		_, err := copybook.Parse(strings.NewReader(src), &copybook.ParseArgs{FreeFormat: true})
+		var syntaxErr *copybook.SyntaxError
+		if !errors.As(err, &syntaxErr) || syntaxErr.Line != line {
+			t.Fatalf("%q: expected: a syntax error at line %d, got %v", src, line, err)
+		}
+	}
+
+	if _, err := copybook.Parse(strings.NewReader("       01  R.\n      X05  A PIC X.\n"), nil); err == nil {
+		t.Fatal("An invalid indicator must be rejected")
+	}
+}
@@ -0,0 +1,210 @@
+package copybook
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/Stolkerve/zoau-go/records"
+)
+
+// ExportJSON writes the records read from r to w as a JSON array of objects, whose members are in the
+// order of the fields of the copybook.
+func (c *Codec) ExportJSON(w io.Writer, r *records.Reader) error {
+	out := bufio.NewWriter(w)
+	if _, err := out.WriteString("["); err != nil {
+		return err
+	}
+	for n := 0; ; n++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			if n > 0 {
+				out.WriteString("\n")
+			}
+			break
+		}
+		if err != nil {
+			return err
+		}
+		values, err := c.Decode(record)
+		if err != nil {
+			return fmt.Errorf("record %d: %w", n+1, err)
+		}
+
+		if n > 0 {
+			out.WriteString(",")
+		}
+		out.WriteString("\n  ")
+		if c.record.IsGroup() {
+			err = writeObject(out, c.record, values)
+		} else {
+			err = writeObject(out, &Field{Children: []*Field{c.record}}, values)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if _, err := out.WriteString("]\n"); err != nil {
+		return err
+	}
+	return out.Flush()
+}
+
+// writeObject writes the values of the fields of g as a JSON object.
+func writeObject(out *bufio.Writer, g *Field, values map[string]any) error {
+	out.WriteString("{")
+	first := true
+	if err := writeMembers(out, g, values, &first); err != nil {
+		return err
+	}
+	_, err := out.WriteString("}")
+	return err
+}
+
+// writeMembers writes the values of the fields of g as members of a JSON object, the fields of a FILLER
+// group being members of the object of its parent.
+func writeMembers(out *bufio.Writer, g *Field, values map[string]any, first *bool) error {
+	for _, c := range g.Children {
+		if c.Name == "" {
+			if c.IsGroup() && c.Occurs == 0 {
+				if err := writeMembers(out, c, values, first); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		value, ok := values[c.Name]
+		if !ok {
+			continue
+		}
+		if !*first {
+			out.WriteString(",")
+		}
+		*first = false
+		name, _ := json.Marshal(c.Name)
+		out.Write(name)
+		out.WriteString(":")
+		if err := writeValue(out, c, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeValue writes value, all the occurrences of f, as JSON.
+func writeValue(out *bufio.Writer, f *Field, value any) error {
+	table, ok := value.([]any)
+	if !ok || f.Occurs == 0 {
+		return writeOne(out, f, value)
+	}
+	out.WriteString("[")
+	for i, element := range table {
+		if i > 0 {
+			out.WriteString(",")
+		}
+		if err := writeOne(out, f, element); err != nil {
+			return err
+		}
+	}
+	_, err := out.WriteString("]")
+	return err
+}
+
+// writeOne writes value, an occurrence of f, as JSON.
+func writeOne(out *bufio.Writer, f *Field, value any) error {
+	if values, ok := value.(map[string]any); ok && f.IsGroup() {
+		return writeObject(out, f, values)
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(b)
+	return err
+}
+
+// ExportCSV writes the records read from r to w as CSV, with a header row naming the columns. A column
+// is an elementary field qualified by its groups and the subscripts of its tables, e.g.
+// ADDRESS.PHONE(2). A table has the columns of all its occurrences, those a record has not are empty.
+func (c *Codec) ExportCSV(w io.Writer, r *records.Reader) error {
+	out := csv.NewWriter(w)
+	var header []string
+	c.columns(c.record, "", nil, func(name string, _ any) { header = append(header, name) })
+	if err := out.Write(header); err != nil {
+		return err
+	}
+
+	for n := 1; ; n++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		values, err := c.Decode(record)
+		if err != nil {
+			return fmt.Errorf("record %d: %w", n, err)
+		}
+		row := make([]string, 0, len(header))
+		c.columns(c.record, "", values, func(_ string, value any) {
+			switch v := value.(type) {
+			case nil:
+				row = append(row, "")
+			case string:
+				row = append(row, v)
+			default:
+				row = append(row, fmt.Sprint(v))
+			}
+		})
+		if err := out.Write(row); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// columns calls column with the name and the value of each elementary field of g, with values the
+// values of g, nil when g is missing.
+func (c *Codec) columns(g *Field, prefix string, values map[string]any, column func(string, any)) {
+	if !g.IsGroup() {
+		if g == c.record {
+			column(g.Name, values[g.Name])
+		}
+		return
+	}
+	for _, f := range g.Children {
+		if f.Name == "" {
+			if f.IsGroup() && f.Occurs == 0 {
+				c.columns(f, prefix, values, column)
+			}
+			continue
+		}
+		value := values[f.Name]
+		if f.Occurs == 0 {
+			c.column(f, prefix+f.Name, value, column)
+			continue
+		}
+		table, _ := value.([]any)
+		for i := 0; i < f.Occurs; i++ {
+			var element any
+			if i < len(table) {
+				element = table[i]
+			}
+			c.column(f, fmt.Sprintf("%s%s(%d)", prefix, f.Name, i+1), element, column)
+		}
+	}
+}
+
+// column calls column with the elementary fields of an occurrence of f.
+func (c *Codec) column(f *Field, name string, value any, column func(string, any)) {
+	if !f.IsGroup() {
+		column(name, value)
+		return
+	}
+	values, _ := value.(map[string]any)
+	c.columns(f, name+".", values, column)
+}
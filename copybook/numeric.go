@@ -0,0 +1,373 @@
+package copybook
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// formatDecimal returns the number whose unsigned digits have scale decimals.
+func formatDecimal(negative bool, digits string, scale int) json.Number {
+	digits = strings.TrimLeft(digits, "0")
+	if len(digits) <= scale {
+		digits = strings.Repeat("0", scale-len(digits)+1) + digits
+	}
+	if scale > 0 {
+		digits = digits[:len(digits)-scale] + "." + digits[len(digits)-scale:]
+	}
+	if negative && strings.Trim(digits, "0.") != "" {
+		digits = "-" + digits
+	}
+	return json.Number(digits)
+}
+
+// parseDecimal returns the sign and the unsigned digits of text scaled by scale decimals, e.g. false and
+// "12340" for "123.4" and 2, "0" for a zero.
+func parseDecimal(text string, scale int) (bool, string, error) {
+	text = strings.TrimSpace(text)
+	negative := strings.HasPrefix(text, "-")
+	text = strings.TrimLeft(text, "+-")
+	integer, fraction, _ := strings.Cut(text, ".")
+	if integer == "" && fraction == "" || strings.Trim(integer+fraction, "0123456789") != "" || len(text) < len(integer)+len(fraction) {
+		return false, "", fmt.Errorf("%w: %q is not a decimal number", ErrInvalidValue, text)
+	}
+	if len(fraction) > scale {
+		if strings.Trim(fraction[scale:], "0") != "" {
+			return false, "", fmt.Errorf("%w: %q has more than %d decimals", ErrOverflow, text, scale)
+		}
+		fraction = fraction[:scale]
+	}
+	digits := strings.TrimLeft(integer+fraction+strings.Repeat("0", scale-len(fraction)), "0")
+	if digits == "" {
+		return false, "0", nil
+	}
+	return negative, digits, nil
+}
+
+// decimalText returns the decimal text of a Go value, rounding floats to scale decimals.
+func decimalText(value any, scale int) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "0", nil
+	case json.Number:
+		return string(v), nil
+	case string:
+		return v, nil
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		if math.IsNaN(rv.Float()) || math.IsInf(rv.Float(), 0) {
+			return "", fmt.Errorf("%w: %v", ErrInvalidValue, value)
+		}
+		return strconv.FormatFloat(rv.Float(), 'f', scale, 64), nil
+	case reflect.String:
+		return rv.String(), nil
+	}
+	return "", fmt.Errorf("%w: %T is not a number", ErrInvalidValue, value)
+}
+
+// numberOf returns the sign and digits of value for the numeric field f, checking they fit its PIC.
+func numberOf(f *Field, value any) (bool, string, error) {
+	text, err := decimalText(value, f.Scale)
+	if err != nil {
+		return false, "", err
+	}
+	negative, digits, err := parseDecimal(text, f.Scale)
+	switch {
+	case err != nil:
+		return false, "", err
+	case negative && !f.Signed:
+		return false, "", fmt.Errorf("%w: %s is negative for PIC %s", ErrOverflow, text, f.Picture)
+	case f.Usage != USAGE_NATIVE_BINARY && len(digits) > f.Digits:
+		return false, "", fmt.Errorf("%w: %s has more digits than PIC %s", ErrOverflow, text, f.Picture)
+	}
+	return negative, digits, nil
+}
+
+// Bytes of the zoned decimal digits and signs in EBCDIC and in ASCII.
+const (
+	ebcdicZone     = 0xF0
+	ebcdicPositive = 0xC0
+	ebcdicNegative = 0xD0
+	ebcdicPlus     = 0x4E
+	ebcdicMinus    = 0x60
+)
+
+// zonedDigit returns the digit of a zoned decimal byte and the sign it carries, 0 for none, 1 for a
+// positive and -1 for a negative overpunch.
+func zonedDigit(b byte, ebcdic bool) (int, int, bool) {
+	if ebcdic {
+		digit := int(b & 0x0F)
+		if digit > 9 {
+			return 0, 0, false
+		}
+		switch b >> 4 {
+		case 0xF:
+			return digit, 0, true
+		case 0xC, 0xA, 0xE:
+			return digit, 1, true
+		case 0xD, 0xB:
+			return digit, -1, true
+		}
+		return 0, 0, false
+	}
+	switch {
+	case b >= '0' && b <= '9':
+		return int(b - '0'), 0, true
+	case b == '{':
+		return 0, 1, true
+	case b >= 'A' && b <= 'I':
+		return int(b-'A') + 1, 1, true
+	case b == '}':
+		return 0, -1, true
+	case b >= 'J' && b <= 'R':
+		return int(b-'J') + 1, -1, true
+	case b >= 'p' && b <= 'y':
+		return int(b - 'p'), -1, true
+	}
+	return 0, 0, false
+}
+
+// zonedByte returns the byte of a zoned decimal digit, with the overpunch of sign if it is not 0.
+func zonedByte(digit byte, sign int, ebcdic bool) byte {
+	if ebcdic {
+		switch sign {
+		case 1:
+			return ebcdicPositive | digit
+		case -1:
+			return ebcdicNegative | digit
+		}
+		return ebcdicZone | digit
+	}
+	switch {
+	case sign == 1 && digit == 0:
+		return '{'
+	case sign == 1:
+		return 'A' + digit - 1
+	case sign == -1 && digit == 0:
+		return '}'
+	case sign == -1:
+		return 'J' + digit - 1
+	}
+	return '0' + digit
+}
+
+func decodeZoned(f *Field, b []byte, ebcdic bool) (json.Number, error) {
+	plus, minus := byte('+'), byte('-')
+	if ebcdic {
+		plus, minus = ebcdicPlus, ebcdicMinus
+	}
+	negative := false
+	if f.Signed && f.SignSeparate {
+		sign := b[len(b)-1]
+		if f.SignLeading {
+			sign, b = b[0], b[1:]
+		} else {
+			b = b[:len(b)-1]
+		}
+		switch sign {
+		case plus:
+		case minus:
+			negative = true
+		default:
+			return "", fmt.Errorf("%w: sign byte %02X", ErrInvalidData, sign)
+		}
+	}
+
+	signAt := len(b) - 1
+	if f.SignLeading {
+		signAt = 0
+	}
+	digits := make([]byte, len(b))
+	for i, c := range b {
+		digit, sign, ok := zonedDigit(c, ebcdic)
+		if !ok || sign != 0 && (i != signAt || f.SignSeparate) {
+			return "", fmt.Errorf("%w: zoned decimal byte %02X", ErrInvalidData, c)
+		}
+		negative = negative || sign < 0 && f.Signed
+		digits[i] = '0' + byte(digit)
+	}
+	return formatDecimal(negative, string(digits), f.Scale), nil
+}
+
+func encodeZoned(f *Field, b []byte, negative bool, digits string, ebcdic bool) {
+	n := f.Digits
+	out := b
+	if f.Signed && f.SignSeparate {
+		plus, minus := byte('+'), byte('-')
+		if ebcdic {
+			plus, minus = ebcdicPlus, ebcdicMinus
+		}
+		sign := plus
+		if negative {
+			sign = minus
+		}
+		if f.SignLeading {
+			b[0], out = sign, b[1:]
+		} else {
+			b[n], out = sign, b[:n]
+		}
+	}
+
+	digits = strings.Repeat("0", n-len(digits)) + digits
+	signAt := n - 1
+	if f.SignLeading {
+		signAt = 0
+	}
+	for i := 0; i < n; i++ {
+		sign := 0
+		if i == signAt && f.Signed && !f.SignSeparate {
+			sign = 1
+			if negative {
+				sign = -1
+			}
+		}
+		out[i] = zonedByte(digits[i]-'0', sign, ebcdic)
+	}
+}
+
+func decodePacked(f *Field, b []byte) (json.Number, error) {
+	digits := make([]byte, 0, 2*len(b))
+	for i, c := range b {
+		high, low := c>>4, c&0x0F
+		if high > 9 || low > 9 && i != len(b)-1 {
+			return "", fmt.Errorf("%w: packed decimal byte %02X", ErrInvalidData, c)
+		}
+		digits = append(digits, '0'+high)
+		if i != len(b)-1 {
+			digits = append(digits, '0'+low)
+		}
+	}
+	negative := false
+	switch b[len(b)-1] & 0x0F {
+	case 0xC, 0xA, 0xE, 0xF:
+	case 0xD, 0xB:
+		negative = true
+	default:
+		return "", fmt.Errorf("%w: packed decimal sign %X", ErrInvalidData, b[len(b)-1]&0x0F)
+	}
+	return formatDecimal(negative, string(digits), f.Scale), nil
+}
+
+func encodePacked(f *Field, b []byte, negative bool, digits string) {
+	digits = strings.Repeat("0", 2*len(b)-1-len(digits)) + digits
+	sign := byte(0xF)
+	if f.Signed {
+		sign = 0xC
+		if negative {
+			sign = 0xD
+		}
+	}
+	for i := range b {
+		high := digits[2*i] - '0'
+		low := sign
+		if i != len(b)-1 {
+			low = digits[2*i+1] - '0'
+		}
+		b[i] = high<<4 | low
+	}
+}
+
+func decodeBinary(f *Field, b []byte) json.Number {
+	var n uint64
+	for _, c := range b {
+		n = n<<8 | uint64(c)
+	}
+	negative := false
+	if f.Signed && b[0]&0x80 != 0 {
+		// Sign extend, then take the magnitude of the two's complement.
+		if len(b) < 8 {
+			n |= ^uint64(0) << (8 * len(b))
+		}
+		n, negative = -n, true
+	}
+	return formatDecimal(negative, strconv.FormatUint(n, 10), f.Scale)
+}
+
+func encodeBinary(f *Field, b []byte, negative bool, digits string) error {
+	n, err := strconv.ParseUint(digits, 10, 64)
+	limit := new(big.Int).Lsh(big.NewInt(1), uint(8*len(b)))
+	if f.Signed {
+		limit.Rsh(limit, 1)
+		if !negative {
+			limit.Sub(limit, big.NewInt(1))
+		}
+	} else {
+		limit.Sub(limit, big.NewInt(1))
+	}
+	if err != nil || new(big.Int).SetUint64(n).Cmp(limit) > 0 {
+		return fmt.Errorf("%w: %s is out of range of %d bytes", ErrOverflow, digits, len(b))
+	}
+	if negative {
+		n = -n
+	}
+	var full [8]byte
+	binary.BigEndian.PutUint64(full[:], n)
+	copy(b, full[8-len(b):])
+	return nil
+}
+
+// decodeHexFloat decodes an IBM hexadecimal floating point number: a sign bit, a 7 bits exponent of 16
+// in excess 64 and a fraction.
+func decodeHexFloat(b []byte) json.Number {
+	var fraction uint64
+	for _, c := range b[1:] {
+		fraction = fraction<<8 | uint64(c)
+	}
+	exponent := int(b[0]&0x7F) - 64
+	v := math.Ldexp(float64(fraction), 4*exponent-8*(len(b)-1))
+	if b[0]&0x80 != 0 {
+		v = -v
+	}
+	return json.Number(strconv.FormatFloat(v, 'g', -1, 64))
+}
+
+func encodeHexFloat(b []byte, value any) error {
+	text, err := decimalText(value, -1)
+	if err != nil {
+		return err
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+	if err != nil {
+		return fmt.Errorf("%w: %q is not a number", ErrInvalidValue, text)
+	}
+	for i := range b {
+		b[i] = 0
+	}
+	if v == 0 {
+		return nil
+	}
+
+	var sign byte
+	if v < 0 {
+		sign, v = 0x80, -v
+	}
+	// v is fraction * 16^exponent, with fraction in [1/16, 1).
+	_, e := math.Frexp(v)
+	exponent := int(math.Ceil(float64(e) / 4))
+	bits := 8 * (len(b) - 1)
+	fraction := uint64(math.Round(math.Ldexp(v, bits-4*exponent)))
+	if fraction >= 1<<bits {
+		fraction >>= 4
+		exponent++
+	}
+	if exponent+64 < 0 || exponent+64 > 0x7F {
+		return fmt.Errorf("%w: %v is out of range of a hexadecimal floating point number", ErrOverflow, v)
+	}
+	b[0] = sign | byte(exponent+64)
+	for i := len(b) - 1; i > 0; i-- {
+		b[i] = byte(fraction)
+		fraction >>= 8
+	}
+	return nil
+}
@@ -0,0 +1,609 @@
+package copybook
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type ParseArgs struct {
+	// The copybook is in free format: its lines have no sequence number area in columns 1 to 6, no
+	// indicator area in column 7 and no identification area after column 72. Comments start with *>.
+	FreeFormat bool
+}
+
+// Parse parses the data description entries of a copybook, in the fixed format of COBOL source unless
+// args tells otherwise.
+//
+// Level 88 condition names and VALUE clauses are ignored, and so are JUSTIFIED, SYNCHRONIZED and BLANK
+// WHEN ZERO, which do not change the bytes of the fields. Level 66 RENAMES, USAGE POINTER and INDEX,
+// and PIC P scaling are not supported.
+func Parse(r io.Reader, args *ParseArgs) (*Copybook, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if args == nil {
+		args = &ParseArgs{}
+	}
+
+	sentences, err := lex(string(src), args.FreeFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	book := &Copybook{}
+	var stack []*Field
+	for _, s := range sentences {
+		level, err := strconv.Atoi(s[0].text)
+		if err != nil || s[0].quoted {
+			if isHeader(s) {
+				continue
+			}
+			return nil, &SyntaxError{Line: s[0].line, Msg: fmt.Sprintf("expected a level number, got %q", s[0].text)}
+		}
+
+		switch {
+		case level == 88:
+			continue
+		case level == 66:
+			return nil, &SyntaxError{Line: s[0].line, Msg: "level 66 RENAMES is not supported"}
+		case level == 1 || level == 77:
+			f, err := parseEntry(level, s)
+			if err != nil {
+				return nil, err
+			}
+			book.Records = append(book.Records, f)
+			stack = append(stack[:0], f)
+		case level > 1 && level < 50:
+			f, err := parseEntry(level, s)
+			if err != nil {
+				return nil, err
+			}
+			for len(stack) > 0 && stack[len(stack)-1].Level >= level {
+				stack = stack[:len(stack)-1]
+			}
+			if len(stack) == 0 {
+				return nil, &SyntaxError{Line: s[0].line, Msg: fmt.Sprintf("level %02d is outside of a record", level)}
+			}
+			parent := stack[len(stack)-1]
+			if parent.Level == 77 {
+				return nil, &SyntaxError{Line: s[0].line, Msg: "a level 77 item cannot have subordinate items"}
+			}
+			f.Parent = parent
+			parent.Children = append(parent.Children, f)
+			stack = append(stack, f)
+		default:
+			return nil, &SyntaxError{Line: s[0].line, Msg: fmt.Sprintf("invalid level number %d", level)}
+		}
+	}
+
+	if len(book.Records) == 0 {
+		return nil, &SyntaxError{Line: 1, Msg: "no record description"}
+	}
+	for _, r := range book.Records {
+		if err := resolve(r, r, USAGE_DISPLAY); err != nil {
+			return nil, err
+		}
+		r.layout(0)
+	}
+	return book, nil
+}
+
+// isHeader tells whether s is a division or section header, as found around the entries of some
+// copybooks.
+func isHeader(s sentence) bool {
+	last := strings.ToUpper(s[len(s)-1].text)
+	return last == "SECTION" || last == "DIVISION"
+}
+
+type token struct {
+	text   string
+	line   int
+	quoted bool
+}
+
+// sentence is the tokens of an entry, up to its separator period.
+type sentence []token
+
+// lex splits src in sentences.
+func lex(src string, free bool) ([]sentence, error) {
+	var sentences []sentence
+	var current sentence
+	var word strings.Builder
+	wordLine := 0
+
+	endWord := func() {
+		if word.Len() > 0 {
+			current = append(current, token{text: word.String(), line: wordLine})
+			word.Reset()
+		}
+	}
+	endSentence := func() {
+		endWord()
+		if len(current) > 0 {
+			sentences = append(sentences, current)
+			current = nil
+		}
+	}
+
+	for n, line := range strings.Split(src, "\n") {
+		number := n + 1
+		line = strings.TrimRight(line, "\r")
+		if !free {
+			if len(line) > 72 {
+				line = line[:72]
+			}
+			if len(line) < 7 {
+				continue
+			}
+			switch line[6] {
+			case '*', '/', 'D', 'd':
+				continue
+			case ' ', '-':
+			default:
+				return nil, &SyntaxError{Line: number, Msg: fmt.Sprintf("invalid indicator %q in column 7", line[6])}
+			}
+			line = line[7:]
+		}
+
+		for i := 0; i < len(line); i++ {
+			c := line[i]
+			separatorFollows := i+1 == len(line) || line[i+1] == ' ' || line[i+1] == '\t'
+			switch {
+			case c == '*' && i+1 < len(line) && line[i+1] == '>':
+				i = len(line)
+			case c == ' ' || c == '\t':
+				endWord()
+			case (c == ',' || c == ';') && separatorFollows:
+				endWord()
+			case c == '.' && separatorFollows:
+				endSentence()
+			case (c == '\'' || c == '"') && word.Len() == 0:
+				// A literal ends at its closing quote, a doubled quote standing for a quote, or at the
+				// end of the line if it is continued on the next one.
+				var literal strings.Builder
+				j := i + 1
+				for ; j < len(line); j++ {
+					if line[j] == c {
+						if j+1 < len(line) && line[j+1] == c {
+							literal.WriteByte(c)
+							j++
+							continue
+						}
+						break
+					}
+					literal.WriteByte(line[j])
+				}
+				current = append(current, token{text: literal.String(), line: number, quoted: true})
+				i = j
+			default:
+				if word.Len() == 0 {
+					wordLine = number
+				}
+				word.WriteByte(c)
+			}
+		}
+		endWord()
+	}
+	endSentence()
+	return sentences, nil
+}
+
+// Words starting a clause of a data description entry.
+var clauseWords = map[string]bool{
+	"PIC": true, "PICTURE": true, "USAGE": true, "OCCURS": true, "REDEFINES": true, "SIGN": true,
+	"LEADING": true, "TRAILING": true, "VALUE": true, "VALUES": true, "JUST": true, "JUSTIFIED": true,
+	"SYNC": true, "SYNCHRONIZED": true, "BLANK": true, "GLOBAL": true, "EXTERNAL": true, "RENAMES": true,
+	"POINTER": true, "INDEX": true, "ASCENDING": true, "DESCENDING": true, "INDEXED": true,
+	"DEPENDING": true, "TIMES": true,
+}
+
+// Usages, as they are spelled in a USAGE clause.
+var usageWords = map[string]Usage{
+	"DISPLAY":         USAGE_DISPLAY,
+	"NATIONAL":        USAGE_NATIONAL,
+	"COMP":            USAGE_BINARY,
+	"COMPUTATIONAL":   USAGE_BINARY,
+	"COMP-4":          USAGE_BINARY,
+	"COMPUTATIONAL-4": USAGE_BINARY,
+	"BINARY":          USAGE_BINARY,
+	"COMP-5":          USAGE_NATIVE_BINARY,
+	"COMPUTATIONAL-5": USAGE_NATIVE_BINARY,
+	"COMP-3":          USAGE_PACKED,
+	"COMPUTATIONAL-3": USAGE_PACKED,
+	"PACKED-DECIMAL":  USAGE_PACKED,
+	"COMP-1":          USAGE_FLOAT,
+	"COMPUTATIONAL-1": USAGE_FLOAT,
+	"COMP-2":          USAGE_DOUBLE,
+	"COMPUTATIONAL-2": USAGE_DOUBLE,
+}
+
+func isClauseWord(t token) bool {
+	if t.quoted {
+		return false
+	}
+	word := strings.ToUpper(t.text)
+	_, usage := usageWords[word]
+	return clauseWords[word] || usage
+}
+
+// entryParser reads the tokens of an entry.
+type entryParser struct {
+	tokens sentence
+	pos    int
+}
+
+func (p *entryParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+// peek returns the next word, upper-cased, or "" at the end of the entry.
+func (p *entryParser) peek() string {
+	if p.done() || p.tokens[p.pos].quoted {
+		return ""
+	}
+	return strings.ToUpper(p.tokens[p.pos].text)
+}
+
+// accept skips the next word if it is one of words.
+func (p *entryParser) accept(words ...string) bool {
+	next := p.peek()
+	for _, w := range words {
+		if next == w {
+			p.pos++
+			return true
+		}
+	}
+	return false
+}
+
+func (p *entryParser) errorf(format string, args ...any) error {
+	line := p.tokens[len(p.tokens)-1].line
+	if !p.done() {
+		line = p.tokens[p.pos].line
+	}
+	return &SyntaxError{Line: line, Msg: fmt.Sprintf(format, args...)}
+}
+
+// next returns the next token, which must be a word.
+func (p *entryParser) next(what string) (string, error) {
+	if p.done() || p.tokens[p.pos].quoted {
+		return "", p.errorf("expected %s", what)
+	}
+	p.pos++
+	return p.tokens[p.pos-1].text, nil
+}
+
+func (p *entryParser) number(what string) (int, error) {
+	text, err := p.next(what)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.Atoi(text)
+	if err != nil || n < 0 {
+		return 0, p.errorf("expected %s, got %q", what, text)
+	}
+	return n, nil
+}
+
+// parseEntry parses the name and the clauses of a data description entry.
+func parseEntry(level int, s sentence) (*Field, error) {
+	f := &Field{Level: level, line: s[0].line}
+	p := &entryParser{tokens: s, pos: 1}
+	if !p.done() && !isClauseWord(p.tokens[p.pos]) {
+		name, err := p.next("a data name")
+		if err != nil {
+			return nil, err
+		}
+		if !strings.EqualFold(name, "FILLER") {
+			f.Name = name
+		}
+	}
+
+	for !p.done() {
+		word := p.peek()
+		p.pos++
+		switch {
+		case word == "PIC" || word == "PICTURE":
+			p.accept("IS")
+			picture, err := p.next("a picture string")
+			if err != nil {
+				return nil, err
+			}
+			f.Picture = picture
+		case word == "USAGE":
+			p.accept("IS")
+			usage, ok := usageWords[p.peek()]
+			if !ok {
+				if p.peek() == "POINTER" || p.peek() == "INDEX" {
+					return nil, p.errorf("USAGE %s is not supported", p.peek())
+				}
+				return nil, p.errorf("expected a usage")
+			}
+			p.pos++
+			f.Usage = usage
+		case usageWords[word] != "":
+			f.Usage = usageWords[word]
+		case word == "POINTER" || word == "INDEX":
+			return nil, p.errorf("USAGE %s is not supported", word)
+		case word == "OCCURS":
+			if err := p.parseOccurs(f); err != nil {
+				return nil, err
+			}
+		case word == "REDEFINES":
+			name, err := p.next("the name of a redefined item")
+			if err != nil {
+				return nil, err
+			}
+			f.Redefines = name
+		case word == "SIGN" || word == "LEADING" || word == "TRAILING":
+			if word == "SIGN" {
+				p.accept("IS")
+				word = p.peek()
+				if !p.accept("LEADING", "TRAILING") {
+					return nil, p.errorf("expected LEADING or TRAILING")
+				}
+			}
+			f.SignLeading = word == "LEADING"
+			if p.accept("SEPARATE") {
+				f.SignSeparate = true
+				p.accept("CHARACTER")
+			}
+		case word == "VALUE" || word == "VALUES":
+			p.accept("IS", "ARE")
+			for !p.done() && !isClauseWord(p.tokens[p.pos]) {
+				p.pos++
+			}
+		case word == "JUST" || word == "JUSTIFIED":
+			p.accept("RIGHT")
+		case word == "SYNC" || word == "SYNCHRONIZED":
+			p.accept("LEFT", "RIGHT")
+		case word == "BLANK":
+			p.accept("WHEN")
+			if !p.accept("ZERO", "ZEROS", "ZEROES") {
+				return nil, p.errorf("expected BLANK WHEN ZERO")
+			}
+		case word == "GLOBAL" || word == "EXTERNAL":
+		default:
+			p.pos--
+			return nil, p.errorf("unexpected %q", p.tokens[p.pos].text)
+		}
+	}
+	return f, nil
+}
+
+// parseOccurs parses an OCCURS clause, after its OCCURS.
+func (p *entryParser) parseOccurs(f *Field) error {
+	n, err := p.number("the number of occurrences")
+	if err != nil {
+		return err
+	}
+	f.MinOccurs, f.Occurs = n, n
+	if p.accept("TO") {
+		if f.Occurs, err = p.number("the maximum number of occurrences"); err != nil {
+			return err
+		}
+	}
+	p.accept("TIMES")
+	if p.accept("DEPENDING") {
+		p.accept("ON")
+		if f.DependingOn, err = p.next("the name of the item the occurrences depend on"); err != nil {
+			return err
+		}
+	}
+	if f.Occurs == 0 || f.MinOccurs > f.Occurs || f.MinOccurs != f.Occurs && f.DependingOn == "" {
+		return p.errorf("invalid occurrences %d TO %d", f.MinOccurs, f.Occurs)
+	}
+
+	// Keys and indexes only matter to SEARCH statements.
+	for {
+		switch {
+		case p.accept("ASCENDING", "DESCENDING"):
+			p.accept("KEY")
+			p.accept("IS")
+		case p.accept("INDEXED"):
+			p.accept("BY")
+		default:
+			return nil
+		}
+		for !p.done() && !isClauseWord(p.tokens[p.pos]) {
+			p.pos++
+		}
+	}
+}
+
+// resolve checks the fields of record from f down, and computes the size of the elementary ones from
+// their picture and their usage, usage being that of the group of f.
+func resolve(record, f *Field, usage Usage) error {
+	errorf := func(format string, args ...any) error {
+		return &SyntaxError{Line: f.line, Msg: fmt.Sprintf(format, args...)}
+	}
+	if f.Usage == "" {
+		f.Usage = usage
+	}
+
+	if f.DependingOn != "" {
+		object := record.Find(f.DependingOn)
+		if object == nil || object.IsGroup() || object.Category != CATEGORY_NUMERIC || object.Occurs > 0 {
+			return errorf("OCCURS DEPENDING ON %s does not name a numeric field", f.DependingOn)
+		}
+	}
+	if f.Redefines != "" && f.Parent != nil {
+		var redefined *Field
+		for _, sibling := range f.Parent.Children {
+			if sibling == f {
+				break
+			}
+			if strings.EqualFold(sibling.Name, f.Redefines) {
+				redefined = sibling
+			}
+		}
+		if redefined == nil || redefined.Level != f.Level {
+			return errorf("REDEFINES %s does not name a preceding item of level %02d", f.Redefines, f.Level)
+		}
+	}
+
+	if f.IsGroup() {
+		if f.Picture != "" {
+			return errorf("the group %s cannot have a PIC", f.Name)
+		}
+		f.Category = CATEGORY_GROUP
+		names := map[string]bool{}
+		for _, c := range f.Children {
+			if c.Name != "" && names[strings.ToUpper(c.Name)] {
+				return &SyntaxError{Line: c.line, Msg: fmt.Sprintf("duplicate name %s in %s", c.Name, f.Name)}
+			}
+			names[strings.ToUpper(c.Name)] = true
+			if err := resolve(record, c, f.Usage); err != nil {
+				return err
+			}
+		}
+		if f.Usage != USAGE_DISPLAY && f.Usage != USAGE_NATIONAL {
+			// The usage of a group applies to its fields, the group itself is alphanumeric.
+			f.Usage = USAGE_DISPLAY
+		}
+		return nil
+	}
+
+	if f.Usage == USAGE_FLOAT || f.Usage == USAGE_DOUBLE {
+		if f.Picture != "" {
+			return errorf("a %s field cannot have a PIC", f.Usage)
+		}
+		f.Category, f.Signed, f.Size = CATEGORY_NUMERIC, true, 4
+		if f.Usage == USAGE_DOUBLE {
+			f.Size = 8
+		}
+		return nil
+	}
+	if f.Picture == "" {
+		return errorf("the elementary item %s has no PIC", f.Name)
+	}
+	if err := f.parsePicture(); err != nil {
+		return errorf("PIC %s: %v", f.Picture, err)
+	}
+
+	if f.national && f.Usage == USAGE_DISPLAY {
+		f.Usage = USAGE_NATIONAL
+	}
+	switch {
+	case f.Category == CATEGORY_NUMERIC && f.Usage == USAGE_DISPLAY:
+		f.Size = f.Digits
+		if f.SignSeparate && f.Signed {
+			f.Size++
+		}
+	case f.Category == CATEGORY_NUMERIC && (f.Usage == USAGE_BINARY || f.Usage == USAGE_NATIVE_BINARY):
+		switch {
+		case f.Digits <= 4:
+			f.Size = 2
+		case f.Digits <= 9:
+			f.Size = 4
+		case f.Digits <= 18:
+			f.Size = 8
+		default:
+			return errorf("a binary field has at most 18 digits")
+		}
+	case f.Category == CATEGORY_NUMERIC && f.Usage == USAGE_PACKED:
+		f.Size = f.Digits/2 + 1
+	case f.Category != CATEGORY_NUMERIC && f.Usage == USAGE_NATIONAL && f.national:
+	case f.Category == CATEGORY_NUMERIC || f.Usage != USAGE_DISPLAY || f.national:
+		return errorf("PIC %s cannot have USAGE %s", f.Picture, f.Usage)
+	}
+	return nil
+}
+
+// Symbols of picture strings.
+var pictureSymbols = map[string]bool{
+	"X": true, "A": true, "9": true, "S": true, "V": true, "P": true, "N": true, "G": true, "Z": true,
+	"*": true, "B": true, "0": true, "/": true, ",": true, ".": true, "+": true, "-": true, "$": true,
+	"CR": true, "DB": true, "E": true,
+}
+
+// parsePicture sets the category and the digits of f from its picture string, and the size of its
+// DISPLAY or NATIONAL text.
+func (f *Field) parsePicture() error {
+	picture := strings.ToUpper(f.Picture)
+	type run struct {
+		symbol string
+		n      int
+	}
+	var runs []run
+	counts := map[string]int{}
+	size := 0
+	for i := 0; i < len(picture); i++ {
+		symbol := picture[i : i+1]
+		if strings.HasPrefix(picture[i:], "CR") || strings.HasPrefix(picture[i:], "DB") {
+			symbol = picture[i : i+2]
+			i++
+		}
+		if !pictureSymbols[symbol] {
+			return fmt.Errorf("invalid symbol %q", symbol)
+		}
+		n := 1
+		if i+1 < len(picture) && picture[i+1] == '(' {
+			end := strings.IndexByte(picture[i:], ')')
+			if end < 0 {
+				return fmt.Errorf("unbalanced parenthesis")
+			}
+			var err error
+			n, err = strconv.Atoi(picture[i+2 : i+end])
+			if err != nil || n <= 0 {
+				return fmt.Errorf("invalid repetition %q", picture[i+1:i+end+1])
+			}
+			i += end
+		}
+		runs = append(runs, run{symbol, n})
+		counts[symbol] += n
+		if symbol != "S" && symbol != "V" {
+			size += n * len(symbol)
+		}
+	}
+
+	only := func(allowed ...string) bool {
+		for s := range counts {
+			found := false
+			for _, a := range allowed {
+				found = found || s == a
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	}
+	switch {
+	case counts["P"] > 0:
+		return fmt.Errorf("P scaling is not supported")
+	case counts["G"] > 0:
+		return fmt.Errorf("DBCS items are not supported")
+	case counts["N"] > 0:
+		if !only("N") {
+			return fmt.Errorf("a national item only has N symbols")
+		}
+		f.Category, f.Size, f.national = CATEGORY_ALPHANUMERIC, 2*counts["N"], true
+	case only("X", "A", "9") && (counts["X"] > 0 || counts["A"] > 0):
+		f.Category, f.Size = CATEGORY_ALPHANUMERIC, size
+	case only("S", "9", "V"):
+		if counts["S"] > 1 || counts["V"] > 1 || counts["S"] == 1 && runs[0].symbol != "S" || counts["9"] == 0 {
+			return fmt.Errorf("invalid numeric picture")
+		}
+		f.Category, f.Digits, f.Signed = CATEGORY_NUMERIC, counts["9"], counts["S"] == 1
+		afterPoint := false
+		for _, r := range runs {
+			if r.symbol == "V" {
+				afterPoint = true
+			} else if r.symbol == "9" && afterPoint {
+				f.Scale += r.n
+			}
+		}
+		if f.Digits > 31 {
+			return fmt.Errorf("a numeric item has at most 31 digits")
+		}
+	case counts["S"] > 0 || counts["V"] > 0:
+		return fmt.Errorf("an edited picture cannot have S or V")
+	default:
+		f.Category, f.Size = CATEGORY_EDITED, size
+	}
+	return nil
+}
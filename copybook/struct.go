@@ -0,0 +1,275 @@
+package copybook
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Unmarshal decodes record into the struct pointed to by v.
+//
+// A field of the struct is the field of the copybook named by its copybook tag, or else the field
+// whose name matches its own ignoring case, hyphens and underscores: CustName, CUST_NAME and
+// `copybook:"CUST-NAME"` all stand for CUST-NAME. A group is a struct, a pointer to a struct or a
+// map[string]any, a table is a slice or an array, and a numeric field is any integer, float or string
+// type, json.Number included. Fields of the copybook the struct has not are ignored, and so are the
+// fields of the struct tagged "-".
+func (c *Codec) Unmarshal(record []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("copybook: Unmarshal needs a non-nil pointer, not %T", v)
+	}
+	values, err := c.Decode(record)
+	if err != nil {
+		return err
+	}
+	if !c.record.IsGroup() {
+		return assign(c.record, c.record.Name, values[c.record.Name], rv.Elem())
+	}
+	return assignGroup(c.record, "", values, rv.Elem())
+}
+
+// Marshal encodes the struct v, or a pointer to it, as Unmarshal decodes it.
+func (c *Codec) Marshal(v any) ([]byte, error) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if !c.record.IsGroup() {
+		value, err := valueOf(c.record, c.record.Name, rv)
+		if err != nil {
+			return nil, err
+		}
+		return c.Encode(map[string]any{c.record.Name: value})
+	}
+	values := map[string]any{}
+	if err := groupValues(c.record, "", rv, values); err != nil {
+		return nil, err
+	}
+	return c.Encode(values)
+}
+
+// normalizeName returns name upper-cased without its hyphens and underscores.
+func normalizeName(name string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "", "_", "").Replace(name))
+}
+
+// structField returns the field of the struct v standing for the copybook field name.
+func structField(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("copybook")
+		if !sf.IsExported() || tag == "-" {
+			continue
+		}
+		if tag != "" && strings.EqualFold(tag, name) || tag == "" && normalizeName(sf.Name) == normalizeName(name) {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+func typeError(path string, value any, v reflect.Value) error {
+	return &FieldError{Field: path, Err: fmt.Errorf("%w: cannot convert %T to %s", ErrInvalidValue, value, v.Type())}
+}
+
+// assignGroup assigns the values of the fields of g to v.
+func assignGroup(g *Field, path string, values map[string]any, v reflect.Value) error {
+	switch {
+	case v.Kind() == reflect.Pointer:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return assignGroup(g, path, values, v.Elem())
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String || v.Kind() == reflect.Interface && v.NumMethod() == 0:
+		if v.Kind() == reflect.Map && !reflect.TypeOf(values).AssignableTo(v.Type()) {
+			return typeError(path, values, v)
+		}
+		v.Set(reflect.ValueOf(values))
+		return nil
+	case v.Kind() != reflect.Struct:
+		return typeError(path, values, v)
+	}
+
+	for _, c := range g.Children {
+		if c.Name == "" {
+			if c.IsGroup() && c.Occurs == 0 {
+				if err := assignGroup(c, path, values, v); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		field, ok := structField(v, c.Name)
+		if !ok {
+			continue
+		}
+		if err := assign(c, qualify(path, c.Name), values[c.Name], field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// assign assigns value, all the occurrences of f, to v.
+func assign(f *Field, path string, value any, v reflect.Value) error {
+	if f.Occurs == 0 {
+		return assignOne(f, path, value, v)
+	}
+	table, _ := value.([]any)
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.NumMethod() != 0 {
+			return typeError(path, value, v)
+		}
+		v.Set(reflect.ValueOf(value))
+		return nil
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(v.Type(), len(table), len(table)))
+	case reflect.Array:
+		v.Set(reflect.Zero(v.Type()))
+	default:
+		return typeError(path, value, v)
+	}
+	for i := 0; i < len(table) && i < v.Len(); i++ {
+		if err := assignOne(f, fmt.Sprintf("%s(%d)", path, i+1), table[i], v.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// assignOne assigns value, an occurrence of f, to v.
+func assignOne(f *Field, path string, value any, v reflect.Value) error {
+	if f.IsGroup() {
+		values, _ := value.(map[string]any)
+		return assignGroup(f, path, values, v)
+	}
+	if v.Kind() == reflect.Pointer {
+		if value == nil {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	if value == nil {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+	if v.Kind() == reflect.Interface && v.NumMethod() == 0 {
+		v.Set(reflect.ValueOf(value))
+		return nil
+	}
+
+	text, isText := value.(string)
+	number, _ := value.(json.Number)
+	switch v.Kind() {
+	case reflect.String:
+		if !isText {
+			text = string(number)
+		}
+		v.SetString(text)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := number.Int64()
+		if err != nil || isText || v.OverflowInt(n) {
+			return &FieldError{Field: path, Err: fmt.Errorf("%w: %v does not fit %s", ErrInvalidValue, value, v.Type())}
+		}
+		v.SetInt(n)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := number.Int64()
+		if err != nil || isText || n < 0 || v.OverflowUint(uint64(n)) {
+			return &FieldError{Field: path, Err: fmt.Errorf("%w: %v does not fit %s", ErrInvalidValue, value, v.Type())}
+		}
+		v.SetUint(uint64(n))
+		return nil
+	case reflect.Float32, reflect.Float64:
+		n, err := number.Float64()
+		if err != nil || isText {
+			return typeError(path, value, v)
+		}
+		v.SetFloat(n)
+		return nil
+	}
+	return typeError(path, value, v)
+}
+
+// groupValues puts the values of the fields of g found in v into values.
+func groupValues(g *Field, path string, v reflect.Value, values map[string]any) error {
+	switch {
+	case v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return groupValues(g, path, v.Elem(), values)
+	case v.Kind() == reflect.Map:
+		m, ok := v.Interface().(map[string]any)
+		if !ok {
+			return typeError(path, v.Interface(), reflect.ValueOf(values))
+		}
+		for k, value := range m {
+			values[k] = value
+		}
+		return nil
+	case v.Kind() != reflect.Struct:
+		return typeError(path, v.Interface(), reflect.ValueOf(values))
+	}
+
+	for _, c := range g.Children {
+		if c.Name == "" {
+			if c.IsGroup() && c.Occurs == 0 {
+				if err := groupValues(c, path, v, values); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		field, ok := structField(v, c.Name)
+		if !ok {
+			continue
+		}
+		value, err := valueOf(c, qualify(path, c.Name), field)
+		if err != nil {
+			return err
+		}
+		values[c.Name] = value
+	}
+	return nil
+}
+
+// valueOf returns the value of v, all the occurrences of f, for Encode.
+func valueOf(f *Field, path string, v reflect.Value) (any, error) {
+	if f.Occurs > 0 && (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) {
+		table := make([]any, v.Len())
+		for i := range table {
+			value, err := valueOne(f, fmt.Sprintf("%s(%d)", path, i+1), v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			table[i] = value
+		}
+		return table, nil
+	}
+	return valueOne(f, path, v)
+}
+
+// valueOne returns the value of v, an occurrence of f.
+func valueOne(f *Field, path string, v reflect.Value) (any, error) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	if f.IsGroup() {
+		values := map[string]any{}
+		if err := groupValues(f, path, v, values); err != nil {
+			return nil, err
+		}
+		return values, nil
+	}
+	return v.Interface(), nil
+}
@@ -0,0 +1,268 @@
+package zoau
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidName is returned when a dataset, member or HFS file name breaks the z/OS naming rules.
+var ErrInvalidName = errors.New("zoau: invalid name")
+
+const (
+	// Longest dataset name, qualifiers and periods included.
+	maxDatasetNameLength = 44
+
+	// Longest relative generation of a GDG, (+255) or (-255).
+	maxRelativeGeneration = 255
+)
+
+var (
+	qualifierRegex = regexp.MustCompile(`^[A-Z@#$][A-Z0-9@#$-]{0,7}$`)
+	memberRegex    = regexp.MustCompile(`^[A-Z@#$][A-Z0-9@#$]{0,7}$`)
+)
+
+// DatasetName is the parsed name of a dataset, a member of a partitioned dataset, a relative
+// generation of a GDG or an HFS file. The zero value is not a valid name.
+//
+// CreateName, CopyName, MoveName, ReadName and WriteName take a DatasetName in place of a string, so
+// that a name is validated once, when it is parsed.
+type DatasetName struct {
+	path       string
+	qualifiers []string
+	member     string
+	generation *int
+}
+
+// ParseDatasetName parses a dataset name ("USER.DATA"), a member ("USER.PDS(MEMBER)"), a relative
+// generation ("USER.GDG(+1)", "USER.GDG(0)", "USER.GDG(-1)"), the HFS reference to a dataset
+// ("//'USER.DATA'") or an absolute HFS path ("/u/user/file"). Dataset names are upper cased.
+func ParseDatasetName(s string) (DatasetName, error) {
+	s = strings.TrimSpace(s)
+	invalid := func(reason string, a ...any) (DatasetName, error) {
+		return DatasetName{}, fmt.Errorf("%w %q: %s", ErrInvalidName, s, fmt.Sprintf(reason, a...))
+	}
+
+	ref := s
+	if strings.HasPrefix(s, "//") {
+		if len(s) < 5 || s[2] != '\'' || s[len(s)-1] != '\'' {
+			return invalid("expected //'DATASET.NAME'")
+		}
+		ref = s[3 : len(s)-1]
+	} else if strings.HasPrefix(s, "/") {
+		if strings.ContainsRune(s, 0) {
+			return invalid("NUL character in path")
+		}
+		return DatasetName{path: s}, nil
+	} else if strings.ContainsRune(s, '/') {
+		return invalid("an HFS path must be absolute")
+	}
+
+	if ref == "" {
+		return invalid("empty name")
+	}
+	ref = strings.ToUpper(ref)
+	name := DatasetName{}
+
+	if open := strings.IndexByte(ref, '('); open >= 0 {
+		if ref[len(ref)-1] != ')' {
+			return invalid("missing closing parenthesis")
+		}
+		inner := ref[open+1 : len(ref)-1]
+		ref = ref[:open]
+		if generation, err := strconv.Atoi(inner); err == nil {
+			if generation < -maxRelativeGeneration || generation > maxRelativeGeneration {
+				return invalid("relative generation %s out of range", inner)
+			}
+			name.generation = &generation
+		} else if !memberRegex.MatchString(inner) {
+			return invalid("member %q must be 1 to 8 letters, digits or national characters, starting with a letter or national character", inner)
+		} else {
+			name.member = inner
+		}
+	}
+
+	if len(ref) > maxDatasetNameLength {
+		return invalid("longer than %d characters", maxDatasetNameLength)
+	}
+	name.qualifiers = strings.Split(ref, ".")
+	for _, q := range name.qualifiers {
+		if !qualifierRegex.MatchString(q) {
+			return invalid("qualifier %q must be 1 to 8 letters, digits, national characters or hyphens, starting with a letter or national character", q)
+		}
+	}
+	return name, nil
+}
+
+// MustParseDatasetName is like ParseDatasetName but panics if s is not a valid name.
+func MustParseDatasetName(s string) DatasetName {
+	name, err := ParseDatasetName(s)
+	if err != nil {
+		panic(err)
+	}
+	return name
+}
+
+// String returns the name in the form expected by the ZOAU utilities: the HFS path, or the dataset name
+// followed by the member or relative generation between parentheses.
+func (n DatasetName) String() string {
+	if n.path != "" {
+		return n.path
+	}
+	s := n.Name()
+	if n.member != "" {
+		s += "(" + n.member + ")"
+	} else if n.generation != nil {
+		if *n.generation > 0 {
+			s += fmt.Sprintf("(+%d)", *n.generation)
+		} else {
+			s += fmt.Sprintf("(%d)", *n.generation)
+		}
+	}
+	return s
+}
+
+// HFSReference returns the //'DATASET.NAME' form used to refer to a dataset from HFS programs.
+// HFS paths are returned unchanged.
+func (n DatasetName) HFSReference() string {
+	if n.path != "" {
+		return n.path
+	}
+	return "//'" + n.String() + "'"
+}
+
+// IsPath reports whether the name is an HFS path.
+func (n DatasetName) IsPath() bool {
+	return n.path != ""
+}
+
+// Name returns the dataset name, without member nor relative generation. Empty for HFS paths.
+func (n DatasetName) Name() string {
+	return strings.Join(n.qualifiers, ".")
+}
+
+// HLQ returns the high level qualifier. Empty for HFS paths.
+func (n DatasetName) HLQ() string {
+	if len(n.qualifiers) == 0 {
+		return ""
+	}
+	return n.qualifiers[0]
+}
+
+// Qualifiers returns the qualifiers of the dataset name. nil for HFS paths.
+func (n DatasetName) Qualifiers() []string {
+	if n.qualifiers == nil {
+		return nil
+	}
+	return append([]string{}, n.qualifiers...)
+}
+
+// Member returns the member name, empty if the name has no member.
+func (n DatasetName) Member() string {
+	return n.member
+}
+
+// Generation returns the relative generation of a GDG reference and whether the name has one.
+func (n DatasetName) Generation() (int, bool) {
+	if n.generation == nil {
+		return 0, false
+	}
+	return *n.generation, true
+}
+
+// WithMember returns the name of the member of the dataset.
+func (n DatasetName) WithMember(member string) (DatasetName, error) {
+	if n.path != "" {
+		return DatasetName{}, fmt.Errorf("%w %q: HFS paths have no members", ErrInvalidName, n.path)
+	}
+	return ParseDatasetName(n.Name() + "(" + member + ")")
+}
+
+func (n DatasetName) MarshalText() ([]byte, error) {
+	return []byte(n.String()), nil
+}
+
+func (n *DatasetName) UnmarshalText(text []byte) error {
+	name, err := ParseDatasetName(string(text))
+	if err != nil {
+		return err
+	}
+	*n = name
+	return nil
+}
+
+// validateNames returns an error wrapping ErrInvalidName for the first invalid name.
+func validateNames(names ...string) error {
+	for _, name := range names {
+		if _, err := ParseDatasetName(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// catalogEntryName validates the name of a catalog entry of the given kind (e.g. "GDG base"),
+// which can be neither a path, a member nor a relative generation.
+func catalogEntryName(name string, kind string) (string, error) {
+	parsed, err := ParseDatasetName(name)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := parsed.Generation(); ok || parsed.Member() != "" || parsed.IsPath() {
+		return "", fmt.Errorf("%w %q: a %s name is expected", ErrInvalidName, name, kind)
+	}
+	return parsed.Name(), nil
+}
+
+// CreateName runs Client.CreateName on the default client.
+func CreateName(name DatasetName, args *CreateArgs) (*Dataset, error) {
+	return DefaultClient().CreateName(context.Background(), name, args)
+}
+
+// CreateName is Create for a name validated by ParseDatasetName.
+func (c *Client) CreateName(ctx context.Context, name DatasetName, args *CreateArgs) (*Dataset, error) {
+	return c.Create(ctx, name.String(), args)
+}
+
+// CopyName runs Client.CopyName on the default client.
+func CopyName(source DatasetName, target DatasetName, args *CopyArgs) error {
+	return DefaultClient().CopyName(context.Background(), source, target, args)
+}
+
+// CopyName is Copy for names validated by ParseDatasetName.
+func (c *Client) CopyName(ctx context.Context, source DatasetName, target DatasetName, args *CopyArgs) error {
+	return c.Copy(ctx, source.String(), target.String(), args)
+}
+
+// MoveName runs Client.MoveName on the default client.
+func MoveName(source DatasetName, target DatasetName) error {
+	return DefaultClient().MoveName(context.Background(), source, target)
+}
+
+// MoveName is Move for names validated by ParseDatasetName.
+func (c *Client) MoveName(ctx context.Context, source DatasetName, target DatasetName) error {
+	return c.Move(ctx, source.String(), target.String())
+}
+
+// ReadName runs Client.ReadName on the default client.
+func ReadName(dataset DatasetName, args *ReadArgs) (string, error) {
+	return DefaultClient().ReadName(context.Background(), dataset, args)
+}
+
+// ReadName is Read for a name validated by ParseDatasetName.
+func (c *Client) ReadName(ctx context.Context, dataset DatasetName, args *ReadArgs) (string, error) {
+	return c.Read(ctx, dataset.String(), args)
+}
+
+// WriteName runs Client.WriteName on the default client.
+func WriteName(dataset DatasetName, content string, _append bool) error {
+	return DefaultClient().WriteName(context.Background(), dataset, content, _append)
+}
+
+// WriteName is Write for a name validated by ParseDatasetName.
+func (c *Client) WriteName(ctx context.Context, dataset DatasetName, content string, _append bool) error {
+	return c.Write(ctx, dataset.String(), content, _append)
+}
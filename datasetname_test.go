@@ -0,0 +1,134 @@
+package zoau_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/Stolkerve/zoau-go"
+	"github.com/Stolkerve/zoau-go/zoautest"
+)
+
+func TestParseDatasetName(t *testing.T) {
+	tests := []struct {
+		input      string
+		str        string
+		hlq        string
+		qualifiers []string
+		member     string
+		generation string
+		path       bool
+	}{
+		{"user.data", "USER.DATA", "USER", []string{"USER", "DATA"}, "", "", false},
+		{"USER.PDS(MEM#1)", "USER.PDS(MEM#1)", "USER", []string{"USER", "PDS"}, "MEM#1", "", false},
+		{"//'SYS1.PARMLIB(IEASYS00)'", "SYS1.PARMLIB(IEASYS00)", "SYS1", []string{"SYS1", "PARMLIB"}, "IEASYS00", "", false},
+		{"USER.GDG(+1)", "USER.GDG(+1)", "USER", []string{"USER", "GDG"}, "", "+1", false},
+		{"USER.GDG(0)", "USER.GDG(0)", "USER", []string{"USER", "GDG"}, "", "+0", false},
+		{"USER.GDG(-1)", "USER.GDG(-1)", "USER", []string{"USER", "GDG"}, "", "-1", false},
+		{"@#$.A-B.G0001V00", "@#$.A-B.G0001V00", "@#$", []string{"@#$", "A-B", "G0001V00"}, "", "", false},
+		{"A.B.C.D.E.F.G.H.I.J.K.L.M.N.O.P.Q.R.S.T.U.V", "A.B.C.D.E.F.G.H.I.J.K.L.M.N.O.P.Q.R.S.T.U.V", "A", nil, "", "", false},
+		{"/u/user/file.txt", "/u/user/file.txt", "", nil, "", "", true},
+		{"/u/user/../data/USER(1)", "/u/user/../data/USER(1)", "", nil, "", "", true},
+	}
+	for _, test := range tests {
+		name, err := zoau.ParseDatasetName(test.input)
+		if err != nil {
+			t.Fatalf("%q: %v", test.input, err)
+		}
+		generation := ""
+		if g, ok := name.Generation(); ok {
+			generation = fmt.Sprintf("%+d", g)
+		}
+		if name.String() != test.str || name.HLQ() != test.hlq || name.Member() != test.member ||
+			generation != test.generation || name.IsPath() != test.path {
+			t.Fatalf("%q: unexpected %q, hlq %q, member %q, generation %q", test.input, name, name.HLQ(), name.Member(), generation)
+		}
+		if test.qualifiers != nil && !reflect.DeepEqual(name.Qualifiers(), test.qualifiers) {
+			t.Fatalf("%q: expected: %q, got %q", test.input, test.qualifiers, name.Qualifiers())
+		}
+	}
+
+	invalid := []string{
+		"",
+		"USER..DATA",
+		"1USER.DATA",
+		"USER.TOOLONGQUAL",
+		"USER.DA_TA",
+		"USER.DATA.QUALIFIER.QUALIFIER.QUALIFIER.QUALIF",
+		"USER.PDS(MEMBER123)",
+		"USER.PDS(1MEM)",
+		"USER.PDS(MEM",
+		"USER.GDG(+256)",
+		"//USER.DATA",
+		"//'USER.DATA",
+		"tmp/file",
+		"./x",
+		"USER.DATA/X",
+	}
+	for _, input := range invalid {
+		if _, err := zoau.ParseDatasetName(input); !errors.Is(err, zoau.ErrInvalidName) {
+			t.Fatalf("%q expected: %v, got %v", input, zoau.ErrInvalidName, err)
+		}
+	}
+
+	name := zoau.MustParseDatasetName("USER.PDS")
+	if member, err := name.WithMember("new"); err != nil || member.String() != "USER.PDS(NEW)" || member.HFSReference() != "//'USER.PDS(NEW)'" {
+		t.Fatalf("expected: USER.PDS(NEW), got %v, %v", member, err)
+	}
+
+	var decoded struct{ Name zoau.DatasetName }
+	if err := json.Unmarshal([]byte(`{"Name":"user.pds(new)"}`), &decoded); err != nil || decoded.Name.String() != "USER.PDS(NEW)" {
+		t.Fatalf("expected: USER.PDS(NEW), got %v, %v", decoded.Name, err)
+	}
+	if err := json.Unmarshal([]byte(`{"Name":"USER..PDS"}`), &decoded); !errors.Is(err, zoau.ErrInvalidName) {
+		t.Fatalf("expected: %v, got %v", zoau.ErrInvalidName, err)
+	}
+}
+
+func TestValidateNames(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeExecutor{}
+	c := zoau.NewClient(&zoau.ClientArgs{Executor: fake})
+
+	if _, err := c.Create(ctx, "USER.BAD_NAME", nil); !errors.Is(err, zoau.ErrInvalidName) {
+		t.Fatalf("expected: %v, got %v", zoau.ErrInvalidName, err)
+	}
+	if err := c.Copy(ctx, "USER.SOURCE", "USER.PDS(TOOLONGNAME)", nil); !errors.Is(err, zoau.ErrInvalidName) {
+		t.Fatalf("expected: %v, got %v", zoau.ErrInvalidName, err)
+	}
+	if err := c.Write(ctx, "USER..DATA", "A", false); !errors.Is(err, zoau.ErrInvalidName) {
+		t.Fatalf("expected: %v, got %v", zoau.ErrInvalidName, err)
+	}
+	if len(fake.commands) != 0 {
+		t.Fatalf("Invalid names must not reach ZOAU, got %v", fake.commands)
+	}
+	if err := c.CopyName(ctx, zoau.DatasetName{}, zoau.MustParseDatasetName("USER.DATA"), nil); !errors.Is(err, zoau.ErrInvalidName) {
+		t.Fatalf("The zero DatasetName must be rejected, got %v", err)
+	}
+	if len(fake.commands) != 0 {
+		t.Fatalf("Invalid names must not reach ZOAU, got %v", fake.commands)
+	}
+
+	sim := zoau.NewClient(&zoau.ClientArgs{Executor: zoautest.NewSimulator("USER")})
+	name := zoau.MustParseDatasetName("user.data")
+	if _, err := sim.CreateName(ctx, name, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := sim.WriteName(ctx, name, "A", false); err != nil {
+		t.Fatal(err)
+	}
+	copied := zoau.MustParseDatasetName("USER.COPY")
+	if err := sim.CopyName(ctx, name, copied, nil); err != nil {
+		t.Fatal(err)
+	}
+	moved := zoau.MustParseDatasetName("USER.MOVED")
+	if err := sim.MoveName(ctx, copied, moved); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := sim.ReadName(ctx, moved, nil); err != nil || out != "A" {
+		t.Fatalf("expected: A, got %q, %v", out, err)
+	}
+}
@@ -1,93 +1,100 @@
 package zoau
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"strconv"
 	"strings"
 )
 
-// ZOAU dmod function to be used by zos_blockinfile Ansible module
-func BlockInFile(dataset string, args *BlockInFileArgs) error {
+// BlockInFile runs Client.BlockInFile on the default client.
+func BlockInFile(dataset string, args *BlockInFileArgs) (*EditResult, error) {
+	return DefaultClient().BlockInFile(context.Background(), dataset, args)
+}
+
+// ZOAU dmod function to be used by zos_blockinfile Ansible module. The result tells whether the block was
+// inserted, replaced or removed, and how the content changed.
+func (c *Client) BlockInFile(ctx context.Context, dataset string, args *BlockInFileArgs) (*EditResult, error) {
+	if err := validateNames(dataset); err != nil {
+		return nil, err
+	}
 	options := []string{"-b"}
 	state := true
 
-	if args != nil {
-		if args.Lock {
-			options = append(options, "-l")
-		}
-		if args.Force {
-			options = append(options, "-f")
-		}
-		if args.State != nil {
-			state = *args.State
-		}
-		if args.Encoding != nil {
-			options = append(options, "-c", *args.Encoding)
-		}
-		if args.Market != nil {
-			options = append(options, "-m", *args.Market)
-		}
+	if args == nil {
+		args = &BlockInFileArgs{}
+	}
+	if args.InsAft == nil && args.InsBef == nil {
+		copied := *args
+		copied.InsAft = String("EOF")
+		args = &copied
+	}
+
+	if args.Lock {
+		options = append(options, "-l")
+	}
+	if args.Force {
+		options = append(options, "-f")
+	}
+	if args.State != nil {
+		state = *args.State
+	}
+	if args.Encoding != nil {
+		options = append(options, "-c", *args.Encoding)
+	}
+	if args.Market != nil {
+		options = append(options, "-m", strings.ReplaceAll(*args.Market, "\n", `\n`))
 	}
 
 	if state {
 		if args.Block == nil {
-			return errors.New("Block is required when state=true.")
-		} else if args.InsAft != nil {
-			if *args.InsAft == "EOF" {
-				options = append(
-					options,
-					fmt.Sprintf(`"$ a\%s"`, *args.Block),
-					dataset,
-				)
-			} else {
-				options = append(options,
-					"-s",
-					"-e",
-					fmt.Sprintf(`"/%s/a\\%s/$"`, *args.InsAft, *args.Block),
-					"-e",
-					fmt.Sprintf(`"$ a\%s"`, *args.Block),
-					dataset,
-				)
-			}
-		} else if args.InsBef != nil {
-			if *args.InsBef == "BOF" {
-				options = append(
-					options,
-					fmt.Sprintf(`"1 i\\%s"`, *args.Block),
-					dataset,
-				)
-			} else {
-				options = append(
-					options,
-					"-s",
-					"-e",
-					fmt.Sprintf(`"/%s/i\\%s/$"`, *args.InsBef, *args.Block),
-					"-e",
-					fmt.Sprintf(`"$ a\\%s"`, *args.Block),
-					dataset,
-				)
-			}
-		} else {
-			return errors.New("InsAft or InsBef is required when state=true")
+			return nil, errors.New("Block is required when state=true.")
+		}
+		b := newSedBuilder(optionalValues(args.InsAft, args.InsBef)...)
+		switch {
+		case args.InsAft != nil && *args.InsAft == "EOF":
+			options = append(options, b.text("$", 'a', *args.Block, ""))
+		case args.InsAft != nil:
+			options = append(options, "-s",
+				"-e", b.text(b.address(*args.InsAft), 'a', *args.Block, "$"),
+				"-e", b.text("$", 'a', *args.Block, ""))
+		case args.InsBef != nil && *args.InsBef == "BOF":
+			options = append(options, b.text("1", 'i', *args.Block, ""))
+		case args.InsBef != nil:
+			options = append(options, "-s",
+				"-e", b.text(b.address(*args.InsBef), 'i', *args.Block, "$"),
+				"-e", b.text("$", 'a', *args.Block, ""))
 		}
 	} else {
-		options = append(
-			options,
-			"//d",
-			dataset,
-		)
+		// With -b, dmod applies the expression to the block between the markers.
+		options = append(options, "//d")
 	}
+	options = append(options, dataset)
 
-	_, _, err := execZaouCmd("dmod", options)
+	before, err := c.readContent(ctx, dataset)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	predict := func(records []string) ([]string, error) {
+		return editBlock(records, args)
 	}
-	return nil
+	return c.edit(ctx, dataset, before, predict, args.CheckMode, args.Backup, "dmod", options)
 }
 
-// Compare two datasets, output the ISRSUPC output.
-func Compare(source string, target string, args *CompareArgs) (*string, error) {
+// Compare runs Client.Compare on the default client.
+func Compare(source string, target string, args *CompareArgs) (*CompareResult, error) {
+	return DefaultClient().Compare(context.Background(), source, target, args)
+}
+
+// Compare two datasets, members, files or partitioned datasets member by member. The source is the
+// OLD file and the target the NEW file of the SuperC (ISRSUPC) listing the result is parsed from,
+// see ParseCompareListing.
+func (c *Client) Compare(ctx context.Context, source string, target string, args *CompareArgs) (*CompareResult, error) {
+	if err := validateNames(source, target); err != nil {
+		return nil, err
+	}
 	options := make([]string, 0)
 	if args != nil {
 		if args.IgnoreCase {
@@ -102,23 +109,39 @@ func Compare(source string, target string, args *CompareArgs) (*string, error) {
 	}
 	options = append(options, source, target)
 
-	stdout, rc, err := execZaouCmd("ddiff", options)
+	stdout, rc, err := c.execZaouCmd(ctx, "ddiff", options)
+	if err != nil && rc != 1 {
+		return nil, err
+	}
 
-	if rc == 0 {
-		return nil, nil
-	} else if rc == 1 {
-		return &stdout, nil
+	result := ParseCompareListing(stdout)
+	result.Equal = rc == 0
+	if result.Old == "" {
+		result.Old, result.New = source, target
 	}
-	return nil, err
+	return result, nil
 }
 
-// Copy a z/OS source (dataset, HFS file) to a z/OS target.
+// Copy runs Client.Copy on the default client.
 func Copy(source string, target string, args *CopyArgs) error {
+	return DefaultClient().Copy(context.Background(), source, target, args)
+}
+
+// Copy a z/OS source (dataset, HFS file) to a z/OS target.
+func (c *Client) Copy(ctx context.Context, source string, target string, args *CopyArgs) error {
+	if err := validateNames(source, target); err != nil {
+		return err
+	}
 	options := make([]string, 0)
 	if args != nil {
 		if args.Force {
 			options = append(options, "-f")
 		}
+		if args.Alias || args.Executable {
+			if err := c.config.require(CAPABILITY_COPY_ALIAS); err != nil {
+				return err
+			}
+		}
 		if args.Alias {
 			options = append(options, "-I")
 		}
@@ -135,12 +158,20 @@ func Copy(source string, target string, args *CopyArgs) error {
 
 	options = append(options, source, target)
 
-	_, _, err := execZaouCmd("dcp", options)
+	_, _, err := c.execZaouCmd(ctx, "dcp", options)
 	return err
 }
 
-// Create a z/OS dataset.
+// Create runs Client.Create on the default client.
 func Create(name string, args *CreateArgs) (*Dataset, error) {
+	return DefaultClient().Create(context.Background(), name, args)
+}
+
+// Create a z/OS dataset.
+func (c *Client) Create(ctx context.Context, name string, args *CreateArgs) (*Dataset, error) {
+	if err := validateNames(name); err != nil {
+		return nil, err
+	}
 	options := make([]string, 0)
 
 	if args != nil {
@@ -181,22 +212,30 @@ func Create(name string, args *CreateArgs) (*Dataset, error) {
 
 	options = append(options, name)
 
-	_, rc, err := execZaouCmd("dtouch", options)
+	_, rc, err := c.execZaouCmd(ctx, "dtouch", options)
 	if rc >= 8 {
 		return nil, err
 	}
-	if out, err := ListingDataset(name, nil); err != nil {
+	if c.plan != nil {
+		return &Dataset{Name: name}, nil
+	}
+	if out, err := c.ListingDataset(ctx, name, nil); err != nil {
 		return nil, err
 	} else {
 		return &out[0], nil
 	}
 }
 
+// Delete runs Client.Delete on the default client.
+func Delete(datasets ...string) (bool, error) {
+	return DefaultClient().Delete(context.Background(), datasets...)
+}
+
 // Delete a z/OS dataset.
 // Return false if dataset specified by dataset pattern does not exist.
 // Otherwise task completed without error.
-func Delete(datasets ...string) (bool, error) {
-	_, returnCode, err := execZaouCmd("drm", datasets)
+func (c *Client) Delete(ctx context.Context, datasets ...string) (bool, error) {
+	_, returnCode, err := c.execZaouCmd(ctx, "drm", datasets)
 
 	if returnCode == 1 {
 		return false, nil
@@ -209,14 +248,19 @@ func Delete(datasets ...string) (bool, error) {
 	return true, nil
 }
 
+// DeleteMember runs Client.DeleteMember on the default client.
+func DeleteMember(pattern string) (bool, error) {
+	return DefaultClient().DeleteMember(context.Background(), pattern)
+}
+
 // Delete members contained in a dataset.
 // Return false if dataset specified by dataset pattern does not exist.
 // Otherwise task completed without error.
-func DeleteMember(pattern string) (bool, error) {
+func (c *Client) DeleteMember(ctx context.Context, pattern string) (bool, error) {
 	options := make([]string, 0)
 	options = append(options, pattern)
 
-	_, returnCode, err := execZaouCmd("mrm", options)
+	_, returnCode, err := c.execZaouCmd(ctx, "mrm", options)
 
 	if returnCode == 1 {
 		return false, nil
@@ -229,33 +273,81 @@ func DeleteMember(pattern string) (bool, error) {
 	return true, nil
 }
 
-// Check whether or not a dataset exists.
+// Exist runs Client.Exist on the default client.
 func Exist(dataset string) (bool, error) {
-	if out, err := ListingDataset(dataset, nil); err != nil {
+	return DefaultClient().Exist(context.Background(), dataset)
+}
+
+// Check whether or not a dataset exists.
+func (c *Client) Exist(ctx context.Context, dataset string) (bool, error) {
+	if out, err := c.ListingDataset(ctx, dataset, nil); err != nil {
 		return false, err
 	} else {
 		return len(out) > 0, nil
 	}
 }
 
+// FindMember runs Client.FindMember on the default client.
+func FindMember(member string, concatentation string) (string, error) {
+	return DefaultClient().FindMember(context.Background(), member, concatentation)
+}
+
 // Find dataset that contains member within a concatenation. Returns the first dataset that contains member.
 // Return the dataset containing the member.
-func FindMember(member string, concatentation string) (string, error) {
-	return execSimpleStringCmd("dwhence", []string{member, concatentation})
+func (c *Client) FindMember(ctx context.Context, member string, concatentation string) (string, error) {
+	return c.execSimpleStringCmd(ctx, "dwhence", []string{member, concatentation})
+}
+
+// FindReplace runs Client.FindReplace on the default client.
+func FindReplace(dataset string, find string, replace string, args *FindReplaceArgs) (string, error) {
+	return DefaultClient().FindReplace(context.Background(), dataset, find, replace, args)
 }
 
-// Replace text within a dataset.
-func FindReplace(dataset string, find string, replace string) error {
-	options := []string{
-		fmt.Sprintf(`"s/%s/%s/g"`, find, replace),
-		dataset,
+// Replace text within a dataset. find is a regular expression, and replace may refer to the match
+// with & and to its groups with \1 to \9, unless args.Literal is set. Returns the name of the backup
+// taken when args.Backup is set.
+func (c *Client) FindReplace(ctx context.Context, dataset string, find string, replace string, args *FindReplaceArgs) (string, error) {
+	if err := validateNames(dataset); err != nil {
+		return "", err
 	}
-	_, _, err := execZaouCmd("dsed", options)
-	return err
+	if args == nil {
+		args = &FindReplaceArgs{}
+	}
+	expression, err := newSedBuilder(find, replace).substitute(find, replace, args.Literal, "g")
+	if err != nil {
+		return "", err
+	}
+	backup := ""
+	if args.Backup != nil {
+		if backup, err = c.Backup(ctx, dataset, args.Backup); err != nil {
+			return "", err
+		}
+	}
+	if _, _, err := c.execZaouCmd(ctx, "dsed", []string{expression, dataset}); err != nil {
+		return backup, err
+	}
+	return backup, nil
+}
+
+// LineInFile runs Client.LineInFile on the default client.
+func LineInFile(dataset string, line string, args *LineInFileArgs) (*EditResult, error) {
+	return DefaultClient().LineInFile(context.Background(), dataset, line, args)
 }
 
-// ZOAU dsed function to be used by zos_lineinfile Ansible module.
-func LineInFile(dataset string, line string, args *LineInFileArgs) error {
+// ZOAU dsed function to be used by zos_lineinfile Ansible module. The result tells whether the line was
+// inserted, replaced or removed, and how the content changed.
+func (c *Client) LineInFile(ctx context.Context, dataset string, line string, args *LineInFileArgs) (*EditResult, error) {
+	if err := validateNames(dataset); err != nil {
+		return nil, err
+	}
+	if args == nil {
+		args = &LineInFileArgs{}
+	}
+	if args.InsAft == nil && args.InsBef == nil {
+		copied := *args
+		copied.InsAft = String("EOF")
+		args = &copied
+	}
 	options := make([]string, 0)
 	state := true
 	matchCharacter := "$"
@@ -280,131 +372,73 @@ func LineInFile(dataset string, line string, args *LineInFileArgs) error {
 		matchCharacter = "1"
 	}
 
-	if state {
+	before, err := c.readContent(ctx, dataset)
+	if err != nil {
+		return nil, err
+	}
+
+	b := newSedBuilder(optionalValues(args.Regex, args.InsAft, args.InsBef)...)
+	expressions := make([]string, 0)
+	text := strings.Split(line, "\n")
+	switch {
+	case state && containsLines(splitRecords(before), text):
+		// The line is not inserted again: the line matching the regular expression is replaced, or
+		// the line replaced by itself.
 		if args.Regex != nil {
-			if args.InsAft != nil {
-				if *args.InsAft == "EOF" {
-					options = append(options,
-						"-s",
-						"-e",
-						fmt.Sprintf(`"/%s/c\\%s/%s"`, *args.Regex, line, matchCharacter),
-						"-e",
-						fmt.Sprintf(`"$ a\\%s"`, line),
-						dataset,
-					)
-				} else {
-					options = append(options,
-						"-s",
-						"-e",
-						fmt.Sprintf(`"/%s/c\\%s/%s"`, *args.Regex, line, matchCharacter),
-						"-e",
-						fmt.Sprintf(`"/%s/a\\%s/%s"`, *args.InsAft, line, matchCharacter),
-						"-e",
-						fmt.Sprintf(`"$ a\\%s"`, line),
-						dataset,
-					)
-				}
-			} else if args.InsBef != nil {
-				if *args.InsBef == "BOF" {
-					options = append(options,
-						"-s",
-						"-e",
-						fmt.Sprintf(`"/%s/c\\%s/%s"`, *args.Regex, line, matchCharacter),
-						"-e",
-						fmt.Sprintf(`"1 i\\%s"`, line),
-						dataset,
-					)
-				} else {
-					options = append(options,
-						"-s",
-						"-e",
-						fmt.Sprintf(`"/%s/c\\%s/%s"`, *args.Regex, line, matchCharacter),
-						"-e",
-						fmt.Sprintf(`"/%s/i\\%s/%s"`, *args.InsBef, line, matchCharacter),
-						"-e",
-						fmt.Sprintf(`"$ a\\%s"`, line),
-						dataset,
-					)
-				}
-			} else {
-				options = append(options,
-					fmt.Sprintf(`"/%s/c\\%s/%s"`, *args.Regex, line, matchCharacter),
-					dataset,
-				)
-			}
+			expressions = append(expressions, b.text(b.address(*args.Regex), 'c', line, matchCharacter))
 		} else {
-			if args.InsAft != nil {
-				if *args.InsAft == "EOF" {
-					options = append(options,
-						fmt.Sprintf(`"$ a\\%s"`, line),
-						dataset,
-					)
-				} else {
-					options = append(options,
-						"-s",
-						"-e",
-						fmt.Sprintf(`"/%s/a\\%s/%s"`, *args.InsAft, line, matchCharacter),
-						"-e",
-						fmt.Sprintf(`"$ a\\%s"`, line),
-						dataset,
-					)
-				}
-			} else if args.InsBef != nil {
-				if *args.InsBef == "BOF" {
-					options = append(options,
-						fmt.Sprintf(`"1 i\\%s"`, line),
-						dataset,
-					)
-				} else {
-					options = append(options,
-						"-s",
-						"-e",
-						fmt.Sprintf(`"/%s/i\\%s/%s"`, *args.InsBef, line, matchCharacter),
-						"-e",
-						fmt.Sprintf(`"$ a\\%s"`, line),
-						dataset,
-					)
-				}
-			} else {
-				return errors.New("Incorrect parameters")
-			}
+			expressions = append(expressions, b.text(b.lineAddress(text[0]), 'c', text[0], "1"))
 		}
-	} else {
+	case state:
 		if args.Regex != nil {
-			if len(line) != 0 {
-				options = append(options,
-					"-s",
-					"-e",
-					fmt.Sprintf(`"/%s/d"`, *args.Regex),
-					"-e",
-					fmt.Sprintf(`"/%s/d"`, line),
-					dataset,
-				)
-			} else {
-				options = append(options,
-					fmt.Sprintf(
-						`"/%s/d"`,
-						*args.Regex,
-					),
-					dataset,
-				)
-			}
-		} else {
-			options = append(options,
-				fmt.Sprintf(`"/%s/d"`, line),
-				dataset,
-			)
+			expressions = append(expressions, b.text(b.address(*args.Regex), 'c', line, matchCharacter))
+		}
+		switch {
+		case args.InsAft != nil && *args.InsAft == "EOF":
+			expressions = append(expressions, b.text("$", 'a', line, ""))
+		case args.InsAft != nil:
+			expressions = append(expressions, b.text(b.address(*args.InsAft), 'a', line, matchCharacter), b.text("$", 'a', line, ""))
+		case args.InsBef != nil && *args.InsBef == "BOF":
+			expressions = append(expressions, b.text("1", 'i', line, ""))
+		case args.InsBef != nil:
+			expressions = append(expressions, b.text(b.address(*args.InsBef), 'i', line, matchCharacter), b.text("$", 'a', line, ""))
+		}
+	default:
+		if args.Regex != nil {
+			expressions = append(expressions, b.delete(b.address(*args.Regex)))
+		}
+		if len(line) != 0 || args.Regex == nil {
+			expressions = append(expressions, b.delete(b.lineAddress(line)))
 		}
 	}
 
-	_, _, err := execZaouCmd("dsed", options)
-	return err
+	if len(expressions) == 1 {
+		options = append(options, expressions[0])
+	} else {
+		// The expressions are alternatives, dsed stops after the first one that changes the dataset.
+		// The line to replace differs from the new one, as a line already there is not inserted.
+		options = append(options, "-s")
+		for _, expression := range expressions {
+			options = append(options, "-e", expression)
+		}
+	}
+	options = append(options, dataset)
+
+	predict := func(records []string) ([]string, error) {
+		return editLine(records, line, args)
+	}
+	return c.edit(ctx, dataset, before, predict, args.CheckMode, args.Backup, "dsed", options)
 }
 
-// Get a list of members from a dataset.
+// ListMembers runs Client.ListMembers on the default client.
 func ListMembers(pattern string) ([]string, error) {
+	return DefaultClient().ListMembers(context.Background(), pattern)
+}
+
+// Get a list of members from a dataset.
+func (c *Client) ListMembers(ctx context.Context, pattern string) ([]string, error) {
 	options := []string{pattern}
-	stdout, returnCode, err := execZaouCmd("mls", options)
+	stdout, returnCode, err := c.execZaouCmd(ctx, "mls", options)
 	if err != nil {
 		return nil, err
 	}
@@ -416,23 +450,28 @@ func ListMembers(pattern string) ([]string, error) {
 	return strings.Split(stdout, "\n"), nil
 }
 
-// Returns a listing of the datasets matching the supplied pattern.
+// ListingDataset runs Client.ListingDataset on the default client.
 func ListingDataset(pattern string, args *ListingArgs) ([]Dataset, error) {
+	return DefaultClient().ListingDataset(context.Background(), pattern, args)
+}
+
+// Returns a listing of the datasets matching the supplied pattern.
+func (c *Client) ListingDataset(ctx context.Context, pattern string, args *ListingArgs) ([]Dataset, error) {
 	options := []string{"-l", "-u", "-s", "-b"}
 	if args != nil {
-		if args.NameOnly {
-			options = []string{}
+		if args.Migrate && !args.NameOnly {
+			return nil, errors.New("To display migrated datasets, requires NameOnly to be true.")
 		}
-		if args.Migrate && args.NameOnly {
+		if args.Migrate {
 			options = []string{"-m"}
-		} else {
-			return nil, errors.New("To display migrated datasets, requires NameOnly to be true.")
+		} else if args.NameOnly {
+			options = []string{}
 		}
 	}
 
 	options = append(options, pattern)
 
-	stdout, returnCode, err := execZaouCmd("dls", options)
+	stdout, returnCode, err := c.execZaouCmd(ctx, "dls", options)
 	if returnCode == 1 {
 		return []Dataset{}, nil
 	}
@@ -471,24 +510,48 @@ func ListingDataset(pattern string, args *ListingArgs) ([]Dataset, error) {
 	return output, nil
 }
 
-// Move (rename) a dataset.
+// Move runs Client.Move on the default client.
 func Move(source string, target string) error {
+	return DefaultClient().Move(context.Background(), source, target)
+}
+
+// Move (rename) a dataset.
+func (c *Client) Move(ctx context.Context, source string, target string) error {
+	if err := validateNames(source, target); err != nil {
+		return err
+	}
 	options := []string{source, target}
 
-	_, _, err := execZaouCmd("dmv", options)
+	_, _, err := c.execZaouCmd(ctx, "dmv", options)
 	return err
 }
 
-// Move (rename) a member.
+// MoveMember runs Client.MoveMember on the default client.
 func MoveMember(dataset string, source string, target string) error {
+	return DefaultClient().MoveMember(context.Background(), dataset, source, target)
+}
+
+// Move (rename) a member.
+func (c *Client) MoveMember(ctx context.Context, dataset string, source string, target string) error {
+	if err := validateNames(dataset, dataset+"("+source+")", dataset+"("+target+")"); err != nil {
+		return err
+	}
 	options := []string{dataset, source, target}
 
-	_, _, err := execZaouCmd("mmv", options)
+	_, _, err := c.execZaouCmd(ctx, "mmv", options)
 	return err
 }
 
-// Get the string contents of a dataset.
+// Read runs Client.Read on the default client.
 func Read(dataset string, args *ReadArgs) (string, error) {
+	return DefaultClient().Read(context.Background(), dataset, args)
+}
+
+// Get the string contents of a dataset.
+func (c *Client) Read(ctx context.Context, dataset string, args *ReadArgs) (string, error) {
+	if err := validateNames(dataset); err != nil {
+		return "", err
+	}
 	options := make([]string, 0)
 	if args != nil {
 		if args.FromLine != nil {
@@ -502,71 +565,119 @@ func Read(dataset string, args *ReadArgs) (string, error) {
 
 	options = append(options, dataset)
 
-	return execSimpleStringCmd("dtail", options)
+	return c.execSimpleStringCmd(ctx, "dtail", options)
+}
+
+// ReadHead runs Client.ReadHead on the default client.
+func ReadHead(dataset string, Nlines *uint) (string, error) {
+	return DefaultClient().ReadHead(context.Background(), dataset, Nlines)
 }
 
 // A function to display the head content of a non-VSAM dataset. Gets the head content of a dataset.
 // Nlines: Read the first nlines lines from the dataset.
-func ReadHead(dataset string, Nlines *uint) (string, error) {
-	options := []string{"-n", "+1"}
+func (c *Client) ReadHead(ctx context.Context, dataset string, Nlines *uint) (string, error) {
+	if err := validateNames(dataset); err != nil {
+		return "", err
+	}
+	if Nlines == nil {
+		return c.execSimpleStringCmd(ctx, "dtail", []string{"-n", "+1", dataset})
+	}
 
-	options = append(options, dataset)
+	reader, err := c.OpenReader(ctx, dataset, nil)
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
 
-	if Nlines != nil {
-		options = []string{"|", "head", "-n", fmt.Sprintf("%d", *Nlines)}
+	lines := make([]string, 0, *Nlines)
+	scanner := NewRecordScanner(reader)
+	for uint(len(lines)) < *Nlines && scanner.Scan() {
+		lines = append(lines, scanner.Record())
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
 	}
+	return strings.Join(lines, "\n"), nil
+}
 
-	return execSimpleStringCmd("dtail", options)
+// Search runs Client.Search on the default client.
+func Search(dataset string, value string, args *SearchArgs) (*SearchResult, error) {
+	return DefaultClient().Search(context.Background(), dataset, value, args)
 }
 
-// Search a dataset using ISRSUPC.
-func Search(dataset string, value string, args *SearchArgs) (*string, error) {
-	options := make([]string, 0)
-	if args != nil {
-		if args.DisplayLines {
-			options = append(options, "-n")
-		}
-		if args.IgnoreCase {
-			options = append(options, "-i")
-		}
-		if args.PrintDatasets {
-			options = append(options, "-v")
-		}
-		if args.Lines != nil {
-			options = append(options, "-C", fmt.Sprintf("%d", *args.Lines))
-		}
+// Search a dataset, the members of a partitioned dataset or the datasets matching a pattern for the
+// lines matching value, with dgrep. value is a regular expression unless args.Literal is set.
+// Finding no line is not an error.
+func (c *Client) Search(ctx context.Context, dataset string, value string, args *SearchArgs) (*SearchResult, error) {
+	if args == nil {
+		args = &SearchArgs{}
+	}
+	options := []string{"-n", "-v"}
+	if args.IgnoreCase {
+		options = append(options, "-i")
+	}
+	around := uint(0)
+	if args.Lines != nil && !args.CountLines {
+		around = *args.Lines
+		options = append(options, "-C", strconv.FormatUint(uint64(around), 10))
+	}
+	if args.Literal {
+		value = quoteBRE(value)
 	}
-
 	options = append(options, value, dataset)
+	options = append(options, args.Concatenation...)
 
-	stdout, _, err := execZaouCmd("dgrep", options)
+	stdout, rc, err := c.execZaouCmd(ctx, "dgrep", options)
+	if rc == 1 && strings.TrimSpace(stdout) == "" {
+		return &SearchResult{Matches: make([]SearchMatch, 0)}, nil
+	}
 	if err != nil {
 		return nil, err
 	}
+	result := parseSearchOutput(stdout, around)
 	if args.CountLines {
-		out := strconv.Itoa(strings.Count(stdout, "\n"))
-		return &out, nil
+		result.Matches = nil
 	}
-	return &stdout, nil
+	return result, nil
 }
 
-// Return the high level qualifier (HLQ) of the active TSO environment
+// Hlq runs Client.Hlq on the default client.
 func Hlq() (string, error) {
-	return execSimpleStringCmd("hlq", nil)
+	return DefaultClient().Hlq(context.Background())
+}
+
+// Return the high level qualifier (HLQ) of the active TSO environment
+func (c *Client) Hlq(ctx context.Context) (string, error) {
+	if c.hlq != "" {
+		return c.hlq, nil
+	}
+	return c.execSimpleStringCmd(ctx, "hlq", nil)
+}
+
+// TmpName runs Client.TmpName on the default client.
+func TmpName(hlq *string) (string, error) {
+	return DefaultClient().TmpName(context.Background(), hlq)
 }
 
 // Creates a temporary dataset name.
 // hlq:     The HLQ of the temporary dataset name.
-func TmpName(hlq *string) (string, error) {
+func (c *Client) TmpName(ctx context.Context, hlq *string) (string, error) {
 	options := make([]string, 0)
 	if hlq != nil {
 		options = append(options, *hlq)
+	} else if c.hlq != "" {
+		options = append(options, c.hlq)
 	}
-	return execSimpleStringCmd("mvstmp", options)
+	return c.execSimpleStringCmd(ctx, "mvstmp", options)
 }
 
-// Unzips a .dzp file.
+// UnZip runs Client.UnZip on the default client.
 func UnZip(file string, hlq string, args *UnZipArgs) error {
+	return DefaultClient().UnZip(context.Background(), file, hlq, args)
+}
+
+// Unzips a .dzp file.
+func (c *Client) UnZip(ctx context.Context, file string, hlq string, args *UnZipArgs) error {
 	options := make([]string, 0)
 	if args != nil {
 		if args.Size != nil {
@@ -610,23 +721,39 @@ func UnZip(file string, hlq string, args *UnZipArgs) error {
 		}
 	}
 
-	_, _, err := execZaouCmd("dunzip", options)
+	_, _, err := c.execZaouCmd(ctx, "dunzip", options)
 	return err
 }
 
-// Write content to a z/OS data set.
+// Write runs Client.Write on the default client.
 func Write(dataset string, content string, _append bool) error {
-	options := make([]string, 0)
+	return DefaultClient().Write(context.Background(), dataset, content, _append)
+}
+
+// Write content to a z/OS data set.
+func (c *Client) Write(ctx context.Context, dataset string, content string, _append bool) error {
+	mode := WRITE_TRUNCATE
 	if _append {
-		options = append(options, "-a")
+		mode = WRITE_APPEND
 	}
-	options = append(options, content, dataset)
-	_, _, err := execZaouCmd("decho", options)
-	return err
+	writer, err := c.OpenWriter(ctx, dataset, mode, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(writer, content); err != nil {
+		writer.Close()
+		return err
+	}
+	return writer.Close()
 }
 
-// Zip datasets into an HFS file.
+// Zip runs Client.Zip on the default client.
 func Zip(file string, target string, args *ZipArgs) error {
+	return DefaultClient().Zip(context.Background(), file, target, args)
+}
+
+// Zip datasets into an HFS file.
+func (c *Client) Zip(ctx context.Context, file string, target string, args *ZipArgs) error {
 	options := make([]string, 0)
 	if args != nil {
 		if args.Size != nil {
@@ -666,6 +793,6 @@ func Zip(file string, target string, args *ZipArgs) error {
 		}
 	}
 
-	_, _, err := execZaouCmd("dzip", options)
+	_, _, err := c.execZaouCmd(ctx, "dzip", options)
 	return err
 }
@@ -0,0 +1,308 @@
+package zoau
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Default marker lines of BlockInFile, as in the zos_blockinfile Ansible module.
+const (
+	defaultMarker      = "# {mark} MANAGED BLOCK"
+	defaultMarkerBegin = "BEGIN"
+	defaultMarkerEnd   = "END"
+)
+
+// UnifiedDiff renders the changes of the edit as a unified diff without context lines.
+func (r *EditResult) UnifiedDiff() string {
+	var out strings.Builder
+	writeUnifiedDiff(&out, r.Dataset, r.Dataset, r.Hunks)
+	return out.String()
+}
+
+func newEditResult(dataset string, before string, after string) *EditResult {
+	hunks := diffLines(splitRecords(before), splitRecords(after))
+	return &EditResult{Dataset: dataset, Changed: len(hunks) > 0, Before: before, After: after, Hunks: hunks}
+}
+
+// readContent reads the whole content of dataset.
+func (c *Client) readContent(ctx context.Context, dataset string) (string, error) {
+	return c.Read(ctx, dataset, &ReadArgs{})
+}
+
+// edit runs cmd on dataset, whose content is before, after backing the dataset up when backup is set.
+// The content is read again once cmd ran, so that the result reflects what the utility did.
+//
+// In check mode cmd does not run, and in dry-run mode it is only recorded: the result is then the one
+// predicted by predict, which models dsed or dmod over the records of before. A dry-run records cmd even
+// when predict cannot tell the edit, such as for a regular expression with back-references, and reports
+// no change; check mode fails instead.
+func (c *Client) edit(ctx context.Context, dataset string, before string, predict func(records []string) ([]string, error), checkMode bool, backup *BackupArgs, cmd string, options []string) (*EditResult, error) {
+	var result *EditResult
+	if checkMode || c.plan != nil {
+		after, err := predict(splitRecords(before))
+		switch {
+		case err == nil:
+			result = newEditResult(dataset, before, strings.Join(after, "\n"))
+		case checkMode:
+			return nil, err
+		default:
+			result = newEditResult(dataset, before, before)
+		}
+		if checkMode {
+			return result, nil
+		}
+	}
+	name := ""
+	if backup != nil {
+		var err error
+		if name, err = c.Backup(ctx, dataset, backup); err != nil {
+			return nil, err
+		}
+	}
+	if _, _, err := c.execZaouCmd(ctx, cmd, options); err != nil {
+		return nil, err
+	}
+	if c.plan != nil {
+		result.Backup = name
+		return result, nil
+	}
+	content, err := c.readContent(ctx, dataset)
+	if err != nil {
+		return nil, err
+	}
+	result = newEditResult(dataset, before, content)
+	result.Backup = name
+	return result, nil
+}
+
+func splitRecords(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return strings.Split(content, "\n")
+}
+
+// diffLines returns the hunks turning before into after, with the line numbers of a SuperC listing.
+func diffLines(before []string, after []string) []DiffHunk {
+	prefix := 0
+	for prefix < len(before) && prefix < len(after) && before[prefix] == after[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(before)-prefix && suffix < len(after)-prefix && before[len(before)-1-suffix] == after[len(after)-1-suffix] {
+		suffix++
+	}
+	old, new := before[prefix:len(before)-suffix], after[prefix:len(after)-suffix]
+
+	// lcs[i][j] is the length of the longest common subsequence of old[i:] and new[j:].
+	lcs := make([][]int, len(old)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(new)+1)
+	}
+	for i := len(old) - 1; i >= 0; i-- {
+		for j := len(new) - 1; j >= 0; j-- {
+			if old[i] == new[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	hunks := make([]DiffHunk, 0)
+	var hunk *DiffHunk
+	flush := func() {
+		if hunk == nil {
+			return
+		}
+		switch {
+		case len(hunk.Inserted) > 0 && len(hunk.Deleted) > 0:
+			hunk.Kind = DIFF_REPLACE
+		case len(hunk.Inserted) > 0:
+			hunk.Kind = DIFF_INSERT
+		default:
+			hunk.Kind = DIFF_DELETE
+		}
+		hunks = append(hunks, *hunk)
+		hunk = nil
+	}
+	i, j := 0, 0
+	for i < len(old) || j < len(new) {
+		if i < len(old) && j < len(new) && old[i] == new[j] {
+			flush()
+			i, j = i+1, j+1
+			continue
+		}
+		if hunk == nil {
+			hunk = &DiffHunk{NewLine: uint(prefix + j + 1), OldLine: uint(prefix + i + 1)}
+		}
+		if j < len(new) && (i == len(old) || lcs[i][j+1] >= lcs[i+1][j]) {
+			hunk.Inserted = append(hunk.Inserted, new[j])
+			j++
+		} else {
+			hunk.Deleted = append(hunk.Deleted, old[i])
+			i++
+		}
+	}
+	flush()
+	return hunks
+}
+
+// compileAddress compiles the regular expression of a LineInFile or BlockInFile argument, nil if unset
+// or special.
+func compileAddress(re *string, special string) (*regexp.Regexp, error) {
+	if re == nil || *re == special {
+		return nil, nil
+	}
+	return CompileBRE(*re, false)
+}
+
+// matchedLine returns the index of the first or last record matching re, -1 if none does.
+func matchedLine(records []string, re *regexp.Regexp, first bool) int {
+	index := -1
+	for i, r := range records {
+		if re.MatchString(r) {
+			index = i
+			if first {
+				break
+			}
+		}
+	}
+	return index
+}
+
+// insertionPoint returns where lines are inserted after the insAft or before the insBef matching line,
+// at the end of records when neither matches.
+func insertionPoint(records []string, insAft *string, insBef *string, first bool) (int, error) {
+	switch {
+	case insAft != nil:
+		re, err := compileAddress(insAft, "EOF")
+		if err != nil || re == nil {
+			return len(records), err
+		}
+		if i := matchedLine(records, re, first); i >= 0 {
+			return i + 1, nil
+		}
+	case insBef != nil && *insBef == "BOF":
+		return 0, nil
+	case insBef != nil:
+		re, err := compileAddress(insBef, "")
+		if err != nil {
+			return 0, err
+		}
+		if i := matchedLine(records, re, first); i >= 0 {
+			return i, nil
+		}
+	}
+	return len(records), nil
+}
+
+// editLine returns records as LineInFile leaves them, with the semantics of the zos_lineinfile
+// Ansible module: a present line is replaced or inserted only when it is not already there, an absent
+// line is removed.
+func editLine(records []string, line string, args *LineInFileArgs) ([]string, error) {
+	regex, err := compileAddress(args.Regex, "")
+	if err != nil {
+		return nil, err
+	}
+	text := strings.Split(line, "\n")
+
+	if args.State != nil && !*args.State {
+		deleted := func(match func(string) bool) []string {
+			return slices.DeleteFunc(slices.Clone(records), match)
+		}
+		if regex != nil {
+			if edited := deleted(regex.MatchString); len(edited) != len(records) || line == "" {
+				return edited, nil
+			}
+		}
+		return deleted(func(r string) bool { return r == line }), nil
+	}
+
+	if regex != nil {
+		if i := matchedLine(records, regex, args.FirstMatch); i >= 0 {
+			return concatLines(records[:i], text, records[i+1:]), nil
+		}
+	}
+	if containsLines(records, text) {
+		return records, nil
+	}
+	at, err := insertionPoint(records, args.InsAft, args.InsBef, args.FirstMatch)
+	if err != nil {
+		return nil, err
+	}
+	return concatLines(records[:at], text, records[at:]), nil
+}
+
+// containsLines reports whether lines appear in records, one after the other.
+func containsLines(records []string, lines []string) bool {
+	for i := 0; i+len(lines) <= len(records); i++ {
+		if slices.Equal(records[i:i+len(lines)], lines) {
+			return true
+		}
+	}
+	return false
+}
+
+// blockMarkers returns the marker lines surrounding the block of BlockInFile.
+func blockMarkers(args *BlockInFileArgs) (string, string, error) {
+	marker, begin, end := defaultMarker, defaultMarkerBegin, defaultMarkerEnd
+	if args.Market != nil {
+		parts := strings.Split(strings.ReplaceAll(*args.Market, "\n", `\n`), `\n`)
+		switch len(parts) {
+		case 1:
+			marker = parts[0]
+		case 3:
+			begin, end, marker = parts[0], parts[1], parts[2]
+		default:
+			return "", "", errors.New("Market must be <marker_begin>\\n<marker_end>\\n<marker>")
+		}
+	}
+	return strings.ReplaceAll(marker, "{mark}", begin), strings.ReplaceAll(marker, "{mark}", end), nil
+}
+
+// editBlock returns records as BlockInFile leaves them, with the semantics of the zos_blockinfile
+// Ansible module: a block between the marker lines is replaced where it is, and inserted otherwise.
+func editBlock(records []string, args *BlockInFileArgs) ([]string, error) {
+	begin, end, err := blockMarkers(args)
+	if err != nil {
+		return nil, err
+	}
+	first, last := -1, -1
+	for i, r := range records {
+		switch strings.TrimRight(r, " ") {
+		case begin:
+			first = i
+		case end:
+			last = i
+		}
+	}
+
+	at := -1
+	if first >= 0 && last >= first {
+		at = first
+		records = concatLines(records[:first], records[last+1:])
+	}
+	if args.State != nil && !*args.State {
+		return records, nil
+	}
+	if at < 0 {
+		if at, err = insertionPoint(records, args.InsAft, args.InsBef, false); err != nil {
+			return nil, err
+		}
+	}
+	block := concatLines([]string{begin}, strings.Split(*args.Block, "\n"), []string{end})
+	return concatLines(records[:at], block, records[at:]), nil
+}
+
+// concatLines returns a new slice holding the lines of every part.
+func concatLines(parts ...[]string) []string {
+	lines := make([]string, 0)
+	for _, p := range parts {
+		lines = append(lines, p...)
+	}
+	return lines
+}
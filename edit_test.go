@@ -0,0 +1,210 @@
+package zoau_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Stolkerve/zoau-go"
+	"github.com/Stolkerve/zoau-go/zoautest"
+)
+
+func TestLineInFileResult(t *testing.T) {
+	ctx := context.Background()
+	c, ds := newSedDataset(t, "A=1\nB=2")
+
+	res, err := c.LineInFile(ctx, ds, "B=2", &zoau.LineInFileArgs{Regex: zoau.String("^B=")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Changed || res.Before != "A=1\nB=2" || res.After != res.Before || res.UnifiedDiff() != "" {
+		t.Fatalf("expected: no change, got %+v", res)
+	}
+
+	res, err = c.LineInFile(ctx, ds, "B=3", &zoau.LineInFileArgs{Regex: zoau.String("^B="), CheckMode: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff := `--- USER.SED
++++ USER.SED
+@@ -2 +2 @@
+-B=2
++B=3
+`
+	if !res.Changed || res.After != "A=1\nB=3" || res.UnifiedDiff() != diff {
+		t.Fatalf("expected: %s, got %+v", diff, res)
+	}
+	if out, err := c.Read(ctx, ds, nil); err != nil || out != "A=1\nB=2" {
+		t.Fatalf("Check mode must not change the dataset, got %q, %v", out, err)
+	}
+
+	res, err = c.LineInFile(ctx, ds, "B=3", &zoau.LineInFileArgs{Regex: zoau.String("^B=")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Changed || res.After != "A=1\nB=3" {
+		t.Fatalf("expected: B replaced, got %+v", res)
+	}
+
+	// A line already present is not added again, the line is appended at the end by default.
+	for i, changed := range []bool{true, false} {
+		res, err = c.LineInFile(ctx, ds, "C=4", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Changed != changed || res.After != "A=1\nB=3\nC=4" {
+			t.Fatalf("#%d: unexpected result %+v", i, res)
+		}
+	}
+	expected := []zoau.DiffHunk{{Kind: zoau.DIFF_INSERT, NewLine: 3, OldLine: 3, Inserted: []string{"Z=0"}}}
+	if res, _ := c.LineInFile(ctx, ds, "C=4", &zoau.LineInFileArgs{InsBef: zoau.String("BOF"), CheckMode: true}); res.Changed {
+		t.Fatalf("expected: no change, got %+v", res)
+	}
+	if res, _ := c.LineInFile(ctx, ds, "Z=0", &zoau.LineInFileArgs{InsAft: zoau.String("^B="), CheckMode: true}); !reflect.DeepEqual(res.Hunks, expected) || res.After != "A=1\nB=3\nZ=0\nC=4" {
+		t.Fatalf("expected: %+v, got %+v", expected, res)
+	}
+
+	for i, changed := range []bool{true, false} {
+		res, err = c.LineInFile(ctx, ds, "", &zoau.LineInFileArgs{Regex: zoau.String("^A="), State: zoau.Bool(false)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Changed != changed || res.After != "B=3\nC=4" {
+			t.Fatalf("#%d: unexpected result %+v", i, res)
+		}
+	}
+	if len(res.Hunks) != 0 {
+		t.Fatalf("expected: no hunk, got %+v", res.Hunks)
+	}
+
+	if _, err := c.LineInFile(ctx, ds, "X", &zoau.LineInFileArgs{Regex: zoau.String(`\(`)}); err == nil {
+		t.Fatal("An invalid regular expression must be rejected")
+	}
+}
+
+func TestBlockInFileResult(t *testing.T) {
+	ctx := context.Background()
+	c, ds := newSedDataset(t, "A=1\nB=2")
+
+	block := "A=1\n# BEGIN MANAGED BLOCK\nX\nY\n# END MANAGED BLOCK\nB=2"
+	for i, changed := range []bool{true, false} {
+		res, err := c.BlockInFile(ctx, ds, &zoau.BlockInFileArgs{Block: zoau.String("X\nY"), InsAft: zoau.String("^A=")})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Changed != changed || res.After != block {
+			t.Fatalf("#%d: unexpected result %+v", i, res)
+		}
+	}
+
+	// An existing block is replaced where it is.
+	res, err := c.BlockInFile(ctx, ds, &zoau.BlockInFileArgs{Block: zoau.String("Z"), InsBef: zoau.String("BOF")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []zoau.DiffHunk{{Kind: zoau.DIFF_REPLACE, NewLine: 3, OldLine: 3, Inserted: []string{"Z"}, Deleted: []string{"X", "Y"}}}
+	if !res.Changed || res.After != "A=1\n# BEGIN MANAGED BLOCK\nZ\n# END MANAGED BLOCK\nB=2" || !reflect.DeepEqual(res.Hunks, expected) {
+		t.Fatalf("expected: %+v, got %+v", expected, res)
+	}
+
+	res, err = c.BlockInFile(ctx, ds, &zoau.BlockInFileArgs{Block: zoau.String("JCL"), Market: zoau.String("OPEN\nCLOSE\n//* {mark} IBM"), InsBef: zoau.String("BOF")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Changed || res.After != "//* OPEN IBM\nJCL\n//* CLOSE IBM\nA=1\n# BEGIN MANAGED BLOCK\nZ\n# END MANAGED BLOCK\nB=2" {
+		t.Fatalf("Unexpected result %+v", res)
+	}
+
+	res, err = c.BlockInFile(ctx, ds, &zoau.BlockInFileArgs{State: zoau.Bool(false), CheckMode: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Changed || res.After != "//* OPEN IBM\nJCL\n//* CLOSE IBM\nA=1\nB=2" {
+		t.Fatalf("Unexpected result %+v", res)
+	}
+	for i, changed := range []bool{true, false} {
+		res, err = c.BlockInFile(ctx, ds, &zoau.BlockInFileArgs{State: zoau.Bool(false)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Changed != changed || res.After != "//* OPEN IBM\nJCL\n//* CLOSE IBM\nA=1\nB=2" {
+			t.Fatalf("#%d: unexpected result %+v", i, res)
+		}
+	}
+}
+
+func TestLineInFileDryRun(t *testing.T) {
+	ctx := context.Background()
+	sim := zoautest.NewSimulator("USER")
+	live := zoau.NewClient(&zoau.ClientArgs{Executor: sim})
+	if err := live.Write(ctx, "USER.SED", "A=1\nB=2", false); err != nil {
+		t.Fatal(err)
+	}
+
+	plan := &zoau.Plan{}
+	dry := zoau.NewClient(&zoau.ClientArgs{Executor: sim, DryRun: plan})
+	res, err := dry.LineInFile(ctx, "USER.SED", "B=3", &zoau.LineInFileArgs{Regex: zoau.String("^B=")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Changed || res.After != "A=1\nB=3" || len(res.Hunks) != 1 {
+		t.Fatalf("expected: the predicted change, got %+v", res)
+	}
+	if out, err := live.Read(ctx, "USER.SED", nil); err != nil || out != "A=1\nB=2" || len(plan.Commands()) != 1 {
+		t.Fatalf("The dry run must only record dsed, got %q, %v, %v", out, err, plan.Commands())
+	}
+}
+
+// hostSedExecutor returns an Executor whose dtail and dsed run over files of a temporary directory with
+// the sed of the host, for the expressions the simulator cannot run. dsed only takes a single expression.
+func hostSedExecutor(t *testing.T, dataset string, content string) zoau.Executor {
+	t.Helper()
+	if err := exec.Command("sed", "--posix", "-e", "p", "/dev/null").Run(); err != nil {
+		t.Skipf("No sed on the host: %v", err)
+	}
+	dir := t.TempDir()
+	scripts := map[string]string{
+		"dtail": `eval "f=\${$#}"; cat "$(dirname "$0")/$f"`,
+		"dsed":  `eval "f=\${$#}"; sed --posix -i -e "$1" "$(dirname "$0")/$f"`,
+	}
+	for name, script := range scripts {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script+"\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, dataset), []byte(content+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return zoau.ExecExecutor{Config: &zoau.Config{BinDir: dir, LibDir: dir}}
+}
+
+func TestLineInFileBackReference(t *testing.T) {
+	ctx := context.Background()
+	c := zoau.NewClient(&zoau.ClientArgs{Executor: hostSedExecutor(t, "USER.SED", "AA=1\nAB=2")})
+	args := &zoau.LineInFileArgs{Regex: zoau.String(`^\(A\)\1`), State: zoau.Bool(false)}
+
+	// The Go model does not support back-references, dsed runs anyway.
+	res, err := c.LineInFile(ctx, "USER.SED", "", args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []zoau.DiffHunk{{Kind: zoau.DIFF_DELETE, NewLine: 1, OldLine: 1, Deleted: []string{"AA=1"}}}
+	if !res.Changed || res.After != "AB=2" || !reflect.DeepEqual(res.Hunks, expected) {
+		t.Fatalf("expected: %+v, got %+v", expected, res)
+	}
+
+	copied := *args
+	copied.CheckMode = true
+	if _, err := c.LineInFile(ctx, "USER.SED", "", &copied); err == nil {
+		t.Fatal("Check mode cannot predict a back-reference")
+	}
+
+	plan := &zoau.Plan{}
+	dry := zoau.NewClient(&zoau.ClientArgs{Executor: hostSedExecutor(t, "USER.SED", "AA=1\nAB=2"), DryRun: plan})
+	if res, err := dry.LineInFile(ctx, "USER.SED", "", args); err != nil || res.Changed || len(plan.Commands()) != 1 {
+		t.Fatalf("expected: dsed recorded, got %+v, %v, %v", res, err, plan.Commands())
+	}
+}
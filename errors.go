@@ -0,0 +1,180 @@
+package zoau
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// The dataset, member or catalog entry does not exist.
+	ErrNotFound = errors.New("zoau: not found")
+
+	// The dataset is held by another job or user.
+	ErrInUse = errors.New("zoau: in use")
+
+	// The dataset or volume ran out of space.
+	ErrNoSpace = errors.New("zoau: no space")
+
+	// The user is not authorized to access the resource.
+	ErrNotAuthorized = errors.New("zoau: not authorized")
+)
+
+// Severity of an IBM message, taken from the last character of its identifier.
+type Severity byte
+
+const (
+	SEVERITY_INFO     Severity = 'I'
+	SEVERITY_WARNING  Severity = 'W'
+	SEVERITY_ERROR    Severity = 'E'
+	SEVERITY_SEVERE   Severity = 'S'
+	SEVERITY_ACTION   Severity = 'A'
+	SEVERITY_DECISION Severity = 'D'
+)
+
+func (s Severity) String() string {
+	return string(rune(s))
+}
+
+// Message is an IBM message found in the output of a ZOAU utility (e.g. "BGYSC2006I Unable to obtain ...").
+type Message struct {
+	// Message identifier (e.g. BGYSC2006I, IEC141I, IEF212I).
+	Id string
+
+	// Severity of the message.
+	Severity Severity
+
+	// Text following the identifier, up to the end of the line.
+	Text string
+}
+
+// CommandError is returned when a ZOAU utility ends with a non-zero return code or could not be run.
+type CommandError struct {
+	// Name of the ZOAU utility.
+	Command string
+
+	// Arguments passed to the utility.
+	Args []string
+
+	// Return code of the utility. -1 if it did not run to completion.
+	Rc int
+
+	// Standard output of the utility.
+	Stdout string
+
+	// Standard error of the utility.
+	Stderr string
+
+	// IBM messages found in the standard error and standard output.
+	Messages []Message
+
+	// Error reported by the Executor when the utility could not be run, or the context error when
+	// ctx was done while waiting to retry. Nil otherwise.
+	Err error
+
+	// Number of times the utility was run, greater than 1 when it was retried.
+	Attempts int
+}
+
+func (e *CommandError) Error() string {
+	var msg string
+	if e.Err != nil {
+		msg = fmt.Sprintf("%s: %v", e.Command, e.Err)
+	} else {
+		output := strings.TrimSpace(e.Stderr)
+		if output == "" {
+			output = strings.TrimSpace(e.Stdout)
+		}
+		msg = fmt.Sprintf("%s: rc=%d: %s", e.Command, e.Rc, output)
+	}
+	if e.Attempts > 1 {
+		msg += fmt.Sprintf(" (after %d attempts)", e.Attempts)
+	}
+	return msg
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the messages of the command match one of the sentinel errors
+// ErrNotFound, ErrInUse, ErrNoSpace or ErrNotAuthorized.
+func (e *CommandError) Is(target error) bool {
+	for _, m := range e.Messages {
+		if classifyMessage(m) == target {
+			return true
+		}
+	}
+	return false
+}
+
+// Message identifier prefixes of the components whose messages show up in the ZOAU utilities output.
+var messageRegex = regexp.MustCompile(`\b((?:BGYSC|ADR|ARC|CSV|IDC|IEA|IEC|IEF|IEW|IGD|IGW|IKJ|ICH|IRR)\d{3,5}([IWESAD]))\b[ \t]*(.*)`)
+
+// ParseMessages returns the IBM messages found in the output of a ZOAU utility, in order of appearance.
+func ParseMessages(output string) []Message {
+	messages := make([]Message, 0)
+	for _, match := range messageRegex.FindAllStringSubmatch(output, -1) {
+		messages = append(messages, Message{
+			Id:       match[1],
+			Severity: Severity(match[2][0]),
+			Text:     strings.TrimSpace(match[3]),
+		})
+	}
+	return messages
+}
+
+// Messages identifiers with a known meaning.
+var messageErrors = map[string]error{
+	"IDC3012I":  ErrNotFound,
+	"IEF212I":   ErrNotFound,
+	"IKJ56228I": ErrNotFound,
+	"IKJ56225I": ErrInUse,
+	"IEF099I":   ErrInUse,
+	"IEF861I":   ErrInUse,
+	"IEF863I":   ErrInUse,
+	"IEC028I":   ErrNoSpace,
+	"IEC030I":   ErrNoSpace,
+	"IEC031I":   ErrNoSpace,
+	"IEC032I":   ErrNoSpace,
+	"IEF257I":   ErrNoSpace,
+	"IGD17272I": ErrNoSpace,
+	"ICH408I":   ErrNotAuthorized,
+	"IEC150I":   ErrNotAuthorized,
+}
+
+// Text fragments used to classify the ZOAU (BGYSC) messages, whose identifiers vary between releases.
+var messageTexts = []struct {
+	Fragment string
+	Err      error
+}{
+	{"not authorized", ErrNotAuthorized},
+	{"insufficient authority", ErrNotAuthorized},
+	{"not found", ErrNotFound},
+	{"does not exist", ErrNotFound},
+	{"no datasets match", ErrNotFound},
+	{"not in catalog", ErrNotFound},
+	{"in use", ErrInUse},
+	{"held by", ErrInUse},
+	{"enqueue", ErrInUse},
+	{"out of space", ErrNoSpace},
+	{"no space", ErrNoSpace},
+	{"insufficient space", ErrNoSpace},
+}
+
+func classifyMessage(m Message) error {
+	if err, ok := messageErrors[m.Id]; ok {
+		return err
+	}
+	if m.Severity == SEVERITY_INFO || !strings.HasPrefix(m.Id, "BGYSC") {
+		return nil
+	}
+	text := strings.ToLower(m.Text)
+	for _, t := range messageTexts {
+		if strings.Contains(text, t.Fragment) {
+			return t.Err
+		}
+	}
+	return nil
+}
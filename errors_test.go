@@ -0,0 +1,65 @@
+package zoau_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/Stolkerve/zoau-go"
+)
+
+func TestParseMessages(t *testing.T) {
+	output := `BGYSC2006I Unable to obtain dataset information for dataset Z38816.P0397638.T0524969.C0000001 on volume ZXPM06.
+IEC141I 013-18,IGG0191B,Z38816,DTAIL,SYS00001,0A96,ZXPM01,Z38816.PDS(NOPE)
+IKJ56228I DATA SET Z38816.NOPE NOT IN CATALOG OR CATALOG CAN NOT BE ACCESSED`
+
+	expected := []zoau.Message{
+		{Id: "BGYSC2006I", Severity: zoau.SEVERITY_INFO, Text: "Unable to obtain dataset information for dataset Z38816.P0397638.T0524969.C0000001 on volume ZXPM06."},
+		{Id: "IEC141I", Severity: zoau.SEVERITY_INFO, Text: "013-18,IGG0191B,Z38816,DTAIL,SYS00001,0A96,ZXPM01,Z38816.PDS(NOPE)"},
+		{Id: "IKJ56228I", Severity: zoau.SEVERITY_INFO, Text: "DATA SET Z38816.NOPE NOT IN CATALOG OR CATALOG CAN NOT BE ACCESSED"},
+	}
+	if messages := zoau.ParseMessages(output); !reflect.DeepEqual(messages, expected) {
+		t.Fatalf("expected: %v, got %v", expected, messages)
+	}
+
+	if messages := zoau.ParseMessages("USER.TEST1234E.DATA"); len(messages) != 0 {
+		t.Fatalf("Dataset qualifiers must not be parsed as messages, got %v", messages)
+	}
+}
+
+func TestCommandError(t *testing.T) {
+	tests := []struct {
+		Stderr   string
+		Sentinel error
+	}{
+		{"IKJ56228I DATA SET USER.NOPE NOT IN CATALOG OR CATALOG CAN NOT BE ACCESSED", zoau.ErrNotFound},
+		{"BGYSC1501E Dataset USER.NOPE does not exist.", zoau.ErrNotFound},
+		{"IKJ56225I DATA SET USER.DATA ALREADY IN USE, TRY LATER", zoau.ErrInUse},
+		{"IEC031I D37-04,IFG0554T,USER,DECHO,SYS00001,0A96,ZXPM01,USER.DATA", zoau.ErrNoSpace},
+		{"ICH408I USER(USER) GROUP(SYS1) NAME(USER)\n  SYS1.PARMLIB CL(DATASET ) VOL(ZXPM01)\n  INSUFFICIENT ACCESS AUTHORITY", zoau.ErrNotAuthorized},
+	}
+	sentinels := []error{zoau.ErrNotFound, zoau.ErrInUse, zoau.ErrNoSpace, zoau.ErrNotAuthorized}
+
+	for _, test := range tests {
+		useExecutor(t, &fakeExecutor{results: map[string]zoau.Result{
+			"dcp": {Stderr: test.Stderr, Rc: 8},
+		}})
+
+		err := zoau.Copy("USER.SOURCE", "USER.TARGET", nil)
+		var cmdErr *zoau.CommandError
+		if !errors.As(err, &cmdErr) {
+			t.Fatalf("expected a *CommandError, got %v", err)
+		}
+		if cmdErr.Command != "dcp" || cmdErr.Rc != 8 || cmdErr.Stderr != test.Stderr {
+			t.Fatalf("Unexpected error %+v", cmdErr)
+		}
+		if !reflect.DeepEqual(cmdErr.Args, []string{"USER.SOURCE", "USER.TARGET"}) {
+			t.Fatalf("Unexpected args %v", cmdErr.Args)
+		}
+		for _, sentinel := range sentinels {
+			if errors.Is(err, sentinel) != (sentinel == test.Sentinel) {
+				t.Fatalf("errors.Is(%q, %v) = %v", test.Stderr, sentinel, !(sentinel == test.Sentinel))
+			}
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package zoau
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// Command describes a single invocation of a ZOAU utility.
+type Command struct {
+	// Name of the ZOAU utility (e.g. "dls", "mvscmd").
+	Name string
+
+	// Arguments passed to the utility.
+	Args []string
+
+	// Data to feed the utility standard input. nil if the utility reads nothing.
+	Stdin io.Reader
+
+	// Extra environment variables in "KEY=value" form, added to the inherited environment.
+	Env []string
+}
+
+// Result of running a Command.
+type Result struct {
+	// Standard output of the utility.
+	Stdout string
+
+	// Standard error of the utility.
+	Stderr string
+
+	// Return code of the utility.
+	Rc int
+}
+
+// Executor runs ZOAU commands on behalf of a Client.
+//
+// Run returns a non-nil error only when the command could not be run at all or ctx was done
+// before it finished, in which case the error wraps ctx.Err(). A command that runs and ends
+// with a non-zero return code is reported through Result.Rc.
+type Executor interface {
+	Run(ctx context.Context, cmd Command) (Result, error)
+}
+
+// Stream is the standard output of a command started by a StreamExecutor.
+type Stream interface {
+	io.Reader
+
+	// Waits for the command to end and returns its result, whose Stdout is empty. It must be called
+	// once the output has been read until EOF, or after the context passed to Start is done.
+	Wait() (Result, error)
+}
+
+// StreamExecutor is implemented by the Executors able to run a command while its output is being read.
+// Clients use it for the functions returning a reader, such as OpenReader, and fall back to Run with
+// the other Executors, which hold the whole output in memory.
+type StreamExecutor interface {
+	Executor
+
+	// Starts cmd. Start returns an error under the same conditions as Run.
+	Start(ctx context.Context, cmd Command) (Stream, error)
+}
+
+// ExecExecutor is the default Executor. It runs the ZOAU utilities as local processes with os/exec.
+// Without a Config, the ZOAU binaries must be reachable through PATH and LIBPATH.
+// The process is killed when the context passed to Run is done.
+type ExecExecutor struct {
+	// Installation whose utilities are run, see LoadConfig.
+	Config *Config
+}
+
+// Time given to a killed process to release its output pipes before Run gives up on them.
+const execWaitDelay = time.Second
+
+func (e ExecExecutor) Run(ctx context.Context, cmd Command) (Result, error) {
+	var stdout, stderr bytes.Buffer
+
+	proc := e.command(ctx, cmd)
+	proc.Stdout = &stdout
+	proc.Stderr = &stderr
+
+	err := proc.Run()
+	result := Result{
+		Stdout: stdout.String(),
+		Stderr: stderr.String(),
+		Rc:     proc.ProcessState.ExitCode(),
+	}
+	return result, execError(ctx, cmd, proc.ProcessState, err)
+}
+
+// Start starts cmd with its standard output connected to a pipe.
+func (e ExecExecutor) Start(ctx context.Context, cmd Command) (Stream, error) {
+	stream := &execStream{ctx: ctx, cmd: cmd}
+	stream.proc = e.command(ctx, cmd)
+	stream.proc.Stderr = &stream.stderr
+
+	stdout, err := stream.proc.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stream.Reader = stdout
+	if err := stream.proc.Start(); err != nil {
+		return nil, execError(ctx, cmd, nil, err)
+	}
+	return stream, nil
+}
+
+// command returns the process running cmd.
+func (e ExecExecutor) command(ctx context.Context, cmd Command) *exec.Cmd {
+	name := cmd.Name
+	env := cmd.Env
+	if e.Config != nil {
+		if path := filepath.Join(e.Config.BinDir, cmd.Name); fileExists(path) {
+			name = path
+		}
+		env = append(e.Config.Env(), cmd.Env...)
+	}
+
+	proc := exec.CommandContext(ctx, name, cmd.Args...)
+	proc.WaitDelay = execWaitDelay
+	proc.Stdin = cmd.Stdin
+	if len(env) != 0 {
+		proc.Env = append(os.Environ(), env...)
+	}
+	return proc
+}
+
+// execError returns the error reported by Run for a process that ended with err. A process that
+// exited on its own is reported through its return code even if ctx is done since.
+func execError(ctx context.Context, cmd Command, state *os.ProcessState, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil && (state == nil || !state.Exited()) {
+		return fmt.Errorf("%s: %w", cmd.Name, ctxErr)
+	}
+
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return err
+	}
+	return nil
+}
+
+type execStream struct {
+	io.Reader
+	ctx    context.Context
+	cmd    Command
+	proc   *exec.Cmd
+	stderr bytes.Buffer
+}
+
+func (s *execStream) Wait() (Result, error) {
+	err := s.proc.Wait()
+	result := Result{
+		Stderr: s.stderr.String(),
+		Rc:     s.proc.ProcessState.ExitCode(),
+	}
+	return result, execError(s.ctx, s.cmd, s.proc.ProcessState, err)
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,186 @@
+package zoau_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Stolkerve/zoau-go"
+)
+
+type fakeExecutor struct {
+	commands []zoau.Command
+	results  map[string]zoau.Result
+}
+
+func (f *fakeExecutor) Run(ctx context.Context, cmd zoau.Command) (zoau.Result, error) {
+	f.commands = append(f.commands, cmd)
+	return f.results[cmd.Name], nil
+}
+
+func useExecutor(t *testing.T, e zoau.Executor) {
+	prev := zoau.DefaultClient()
+	zoau.SetDefaultClient(zoau.NewClient(&zoau.ClientArgs{Executor: e}))
+	t.Cleanup(func() { zoau.SetDefaultClient(prev) })
+}
+
+func TestExecutorCreate(t *testing.T) {
+	fake := &fakeExecutor{results: map[string]zoau.Result{
+		"dls": {Stdout: "USER.ZOAU1                                   2023/11/06 ps  FB      80 27920 ZXPM01            0        55340\n"},
+	}}
+	useExecutor(t, fake)
+
+	ds, err := zoau.Create("USER.ZOAU1", &zoau.CreateArgs{Type: zoau.String(zoau.DS_ORG_SEQ), PrimarySpace: zoau.String("10")})
+	if err != nil {
+		t.Fatalf("Fail to create: %v", err)
+	}
+	if ds.Name != "USER.ZOAU1" || ds.Lrecl != 80 {
+		t.Fatalf("Unexpected dataset %+v", ds)
+	}
+
+	expected := []zoau.Command{
+		{Name: "dtouch", Args: []string{"-t", "SEQ", "-s", "10", "USER.ZOAU1"}},
+		{Name: "dls", Args: []string{"-l", "-u", "-s", "-b", "USER.ZOAU1"}},
+	}
+	if !reflect.DeepEqual(fake.commands, expected) {
+		t.Fatalf("expected: %v, got %v", expected, fake.commands)
+	}
+}
+
+func TestExecutorReturnCode(t *testing.T) {
+	fake := &fakeExecutor{results: map[string]zoau.Result{
+		"drm": {Stderr: "BGYSC1103E No datasets match pattern: USER.NONE.", Rc: 1},
+		"mrm": {Stderr: "BGYSC1208E Unable to delete member.", Rc: 8},
+	}}
+	useExecutor(t, fake)
+
+	if deleted, err := zoau.Delete("USER.NONE"); err != nil || deleted {
+		t.Fatalf("expected: false, <nil>, got %v, %v", deleted, err)
+	}
+	if _, err := zoau.DeleteMember("USER.PDS(MEM)"); err == nil {
+		t.Fatal("DeleteMember must fail on rc 8")
+	}
+}
+
+func TestExecutorListingDataset(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeExecutor{results: map[string]zoau.Result{"dls": {Stdout: "USER.ZOAU1\nUSER.ZOAU2\n"}}}
+	c := zoau.NewClient(&zoau.ClientArgs{Executor: fake})
+
+	datasets, err := c.ListingDataset(ctx, "USER.*", &zoau.ListingArgs{NameOnly: true})
+	if err != nil || len(datasets) != 2 || datasets[1].Name != "USER.ZOAU2" {
+		t.Fatalf("Unexpected datasets %+v, %v", datasets, err)
+	}
+	if _, err := c.ListingDataset(ctx, "USER.*", &zoau.ListingArgs{NameOnly: true, Migrate: true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ListingDataset(ctx, "USER.*", &zoau.ListingArgs{Migrate: true}); err == nil {
+		t.Fatal("Migrate without NameOnly must be rejected")
+	}
+
+	expected := []zoau.Command{
+		{Name: "dls", Args: []string{"USER.*"}},
+		{Name: "dls", Args: []string{"-m", "USER.*"}},
+	}
+	if !reflect.DeepEqual(fake.commands, expected) {
+		t.Fatalf("expected: %v, got %v", expected, fake.commands)
+	}
+}
+
+func TestExecutorReadConsole(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeExecutor{results: map[string]zoau.Result{"pcon": {Stdout: "IEF403I JOB1 - STARTED"}}}
+	c := zoau.NewClient(&zoau.ClientArgs{Executor: fake})
+
+	if out, err := c.ReadConsole(ctx, nil); err != nil || out != "IEF403I JOB1 - STARTED" {
+		t.Fatalf("Unexpected console %q, %v", out, err)
+	}
+	last := 'l'
+	if _, err := c.ReadConsole(ctx, &last); err != nil {
+		t.Fatal(err)
+	}
+	invalid := 'x'
+	if _, err := c.ReadConsole(ctx, &invalid); err == nil {
+		t.Fatal("An unknown option must be rejected")
+	}
+
+	expected := []zoau.Command{
+		{Name: "pcon", Args: []string{"-r"}},
+		{Name: "pcon", Args: []string{"-l"}},
+	}
+	if !reflect.DeepEqual(fake.commands, expected) {
+		t.Fatalf("expected: %v, got %v", expected, fake.commands)
+	}
+}
+
+func TestExecutorApf(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeExecutor{}
+	c := zoau.NewClient(&zoau.ClientArgs{Executor: fake})
+
+	if _, _, err := c.Apf(ctx, zoau.ApfArgs{Opt: zoau.Uint(zoau.OPT_ADD), DsName: zoau.String("USER.LOAD"), Sms: true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.Apf(ctx, zoau.ApfArgs{Opt: zoau.Uint(zoau.OPT_SET_DYNAMIC)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.Apf(ctx, zoau.ApfArgs{Opt: zoau.Uint(zoau.OPT_DEL)}); err == nil {
+		t.Fatal("OPT_DEL without DsName must be rejected")
+	}
+
+	expected := []zoau.Command{
+		{Name: "apfadm", Args: []string{"-A", "USER.LOAD,sms"}},
+		{Name: "apfadm", Args: []string{"-F", "DYNAMIC"}},
+	}
+	if !reflect.DeepEqual(fake.commands, expected) {
+		t.Fatalf("expected: %v, got %v", expected, fake.commands)
+	}
+}
+
+func TestExecExecutor(t *testing.T) {
+	res, err := zoau.ExecExecutor{}.Run(context.Background(), zoau.Command{
+		Name: "sh",
+		Args: []string{"-c", `echo out; echo err >&2; echo "$ZOAU_TEST"; exit 3`},
+		Env:  []string{"ZOAU_TEST=env"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Stdout != "out\nenv\n" || res.Stderr != "err\n" || res.Rc != 3 {
+		t.Fatalf("Unexpected result %+v", res)
+	}
+
+	if _, err := (zoau.ExecExecutor{}).Run(context.Background(), zoau.Command{Name: "zoau-missing-utility"}); err == nil {
+		t.Fatal("Running a missing utility must fail")
+	}
+}
+
+func TestExecExecutorCancel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := zoau.ExecExecutor{}.Run(ctx, zoau.Command{Name: "sh", Args: []string{"-c", "sleep 10"}})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected: %v, got %v", context.DeadlineExceeded, err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("The process was not killed, Run took %v", elapsed)
+	}
+}
+
+func TestClientContext(t *testing.T) {
+	client := zoau.NewClient(&zoau.ClientArgs{Executor: zoau.ExecExecutor{}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := client.Read(ctx, "USER.ZOAU1", nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected: %v, got %v", context.Canceled, err)
+	}
+	if _, err := client.SubmitJob(ctx, "USER.JCL(JOB)", &zoau.SubmitArgs{Wait: true}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected: %v, got %v", context.Canceled, err)
+	}
+}
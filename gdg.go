@@ -0,0 +1,222 @@
+package zoau
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// DefineGdg runs Client.DefineGdg on the default client.
+func DefineGdg(name string, args *GdgArgs) error {
+	return DefaultClient().DefineGdg(context.Background(), name, args)
+}
+
+// Define a Generation Data Group (GDG) base with IDCAMS.
+func (c *Client) DefineGdg(ctx context.Context, name string, args *GdgArgs) error {
+	base, err := gdgBaseName(name)
+	if err != nil {
+		return err
+	}
+	if args == nil || args.Limit == 0 {
+		return errors.New("Limit is required to define a GDG")
+	}
+
+	params := []string{fmt.Sprintf("NAME(%s)", base), fmt.Sprintf("LIMIT(%d)", args.Limit)}
+	params = append(params, idcamsFlag(args.Scratch, "SCRATCH", "NOSCRATCH"))
+	params = append(params, idcamsFlag(args.Empty, "EMPTY", "NOEMPTY"))
+	if args.Purge {
+		params = append(params, "PURGE")
+	}
+	if args.Order != nil {
+		params = append(params, *args.Order)
+	}
+	if args.Extended {
+		params = append(params, "EXTENDED")
+	}
+
+	_, err = c.idcams(ctx, false, idcamsStatement("DEFINE GENERATIONDATAGROUP (", append(params, ")")...))
+	return err
+}
+
+// AlterGdg runs Client.AlterGdg on the default client.
+func AlterGdg(name string, args *AlterGdgArgs) error {
+	return DefaultClient().AlterGdg(context.Background(), name, args)
+}
+
+// Change the attributes of a GDG base with IDCAMS. Only the attributes set in args are changed.
+func (c *Client) AlterGdg(ctx context.Context, name string, args *AlterGdgArgs) error {
+	base, err := gdgBaseName(name)
+	if err != nil {
+		return err
+	}
+
+	params := make([]string, 0)
+	if args != nil {
+		if args.Limit != nil {
+			params = append(params, fmt.Sprintf("LIMIT(%d)", *args.Limit))
+		}
+		if args.Scratch != nil {
+			params = append(params, idcamsFlag(*args.Scratch, "SCRATCH", "NOSCRATCH"))
+		}
+		if args.Empty != nil {
+			params = append(params, idcamsFlag(*args.Empty, "EMPTY", "NOEMPTY"))
+		}
+		if args.Purge != nil {
+			params = append(params, idcamsFlag(*args.Purge, "PURGE", "NOPURGE"))
+		}
+		if args.Order != nil {
+			params = append(params, *args.Order)
+		}
+		if args.Extended {
+			params = append(params, "EXTENDED")
+		}
+	}
+	if len(params) == 0 {
+		return errors.New("At least one attribute is required to alter a GDG")
+	}
+
+	_, err = c.idcams(ctx, false, idcamsStatement("ALTER "+base, params...))
+	return err
+}
+
+// DeleteGdg runs Client.DeleteGdg on the default client.
+func DeleteGdg(name string, args *DeleteGdgArgs) error {
+	return DefaultClient().DeleteGdg(context.Background(), name, args)
+}
+
+// Delete a GDG base with IDCAMS. The base must be empty unless args.Force is set.
+func (c *Client) DeleteGdg(ctx context.Context, name string, args *DeleteGdgArgs) error {
+	base, err := gdgBaseName(name)
+	if err != nil {
+		return err
+	}
+
+	params := []string{"GENERATIONDATAGROUP"}
+	if args != nil {
+		if args.Force {
+			params = append(params, "FORCE")
+		}
+		if args.Purge {
+			params = append(params, "PURGE")
+		}
+	}
+
+	_, err = c.idcams(ctx, false, idcamsStatement("DELETE "+base, params...))
+	return err
+}
+
+// ListGenerations runs Client.ListGenerations on the default client.
+func ListGenerations(name string) ([]Dataset, error) {
+	return DefaultClient().ListGenerations(context.Background(), name)
+}
+
+// Returns the generations of a GDG, oldest first.
+func (c *Client) ListGenerations(ctx context.Context, name string) ([]Dataset, error) {
+	base, err := gdgBaseName(name)
+	if err != nil {
+		return nil, err
+	}
+	datasets, err := c.ListingDataset(ctx, base+".G*V*", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	generations := make([]Dataset, 0, len(datasets))
+	for _, ds := range datasets {
+		if _, ok := parseGeneration(base, ds.Name); ok {
+			generations = append(generations, ds)
+		}
+	}
+	return sortGenerations(base, generations), nil
+}
+
+// Highest absolute generation number, after which z/OS wraps to G0001.
+const maxGeneration = 9999
+
+// sortGenerations orders the generations of base oldest first. The absolute generation numbers wrap
+// from G9999 to G0001, and a GDG holds at most 999 generations, so the oldest generation is the one
+// after the widest gap between the numbers in use.
+func sortGenerations(base string, generations []Dataset) []Dataset {
+	sort.Slice(generations, func(i, j int) bool {
+		return generations[i].Name < generations[j].Name
+	})
+	start, widest := 0, 0
+	for i := range generations {
+		n, _ := parseGeneration(base, generations[i].Name)
+		gap := 0
+		if i == 0 {
+			last, _ := parseGeneration(base, generations[len(generations)-1].Name)
+			gap = n + maxGeneration - last
+		} else {
+			previous, _ := parseGeneration(base, generations[i-1].Name)
+			gap = n - previous
+		}
+		if gap > widest {
+			start, widest = i, gap
+		}
+	}
+	return append(append(make([]Dataset, 0, len(generations)), generations[start:]...), generations[:start]...)
+}
+
+// ResolveGeneration runs Client.ResolveGeneration on the default client.
+func ResolveGeneration(name string) (string, error) {
+	return DefaultClient().ResolveGeneration(context.Background(), name)
+}
+
+// Resolve a relative generation of a GDG (e.g. "USER.GDG(0)", "USER.GDG(-1)", "USER.GDG(+1)") to
+// its absolute name (e.g. "USER.GDG.G0042V00"), usable by Read, Write or Copy. (0) is the newest
+// generation, negative generations are older ones and positive generations are the next ones to be created.
+// Names without relative generation are returned unchanged.
+func (c *Client) ResolveGeneration(ctx context.Context, name string) (string, error) {
+	parsed, err := ParseDatasetName(name)
+	if err != nil {
+		return "", err
+	}
+	relative, ok := parsed.Generation()
+	if !ok {
+		return parsed.String(), nil
+	}
+
+	base := parsed.Name()
+	generations, err := c.ListGenerations(ctx, base)
+	if err != nil {
+		return "", err
+	}
+
+	if relative > 0 {
+		last := 0
+		if len(generations) > 0 {
+			last, _ = parseGeneration(base, generations[len(generations)-1].Name)
+		}
+		return fmt.Sprintf("%s.G%04dV00", base, (last-1+relative)%maxGeneration+1), nil
+	}
+
+	index := len(generations) - 1 + relative
+	if index < 0 {
+		return "", fmt.Errorf("%s: generation %d of %d: %w", base, relative, len(generations), ErrNotFound)
+	}
+	return generations[index].Name, nil
+}
+
+// gdgBaseName validates the name of a GDG base.
+func gdgBaseName(name string) (string, error) {
+	return catalogEntryName(name, "GDG base")
+}
+
+var absoluteGenerationRegex = regexp.MustCompile(`^\.G([0-9]{4})V[0-9]{2}$`)
+
+// parseGeneration returns the generation number of an absolute generation name of base.
+func parseGeneration(base string, name string) (int, bool) {
+	if len(name) <= len(base) || name[:len(base)] != base {
+		return 0, false
+	}
+	m := absoluteGenerationRegex.FindStringSubmatch(name[len(base):])
+	if m == nil {
+		return 0, false
+	}
+	n, _ := strconv.Atoi(m[1])
+	return n, true
+}
@@ -0,0 +1,158 @@
+package zoau_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Stolkerve/zoau-go"
+	"github.com/Stolkerve/zoau-go/zoautest"
+)
+
+func TestGdg(t *testing.T) {
+	ctx := context.Background()
+	c := zoau.NewClient(&zoau.ClientArgs{Executor: zoautest.NewSimulator("USER")})
+
+	if err := c.DefineGdg(ctx, "USER.BACKUP", &zoau.GdgArgs{Limit: 3, Scratch: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DefineGdg(ctx, "USER.BACKUP", &zoau.GdgArgs{Limit: 3}); err == nil {
+		t.Fatal("Defining an existing GDG must fail")
+	}
+	if _, err := c.ResolveGeneration(ctx, "USER.BACKUP(0)"); !errors.Is(err, zoau.ErrNotFound) {
+		t.Fatalf("expected: %v, got %v", zoau.ErrNotFound, err)
+	}
+
+	for _, content := range []string{"ONE", "TWO", "THREE", "FOUR"} {
+		name, err := c.ResolveGeneration(ctx, "USER.BACKUP(+1)")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := c.Write(ctx, name, content, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	generations, err := c.ListGenerations(ctx, "USER.BACKUP")
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, g := range generations {
+		names = append(names, g.Name)
+	}
+	if strings.Join(names, " ") != "USER.BACKUP.G0002V00 USER.BACKUP.G0003V00 USER.BACKUP.G0004V00" {
+		t.Fatalf("Unexpected generations %v", names)
+	}
+
+	tests := map[string]string{
+		"USER.BACKUP(0)":  "USER.BACKUP.G0004V00",
+		"USER.BACKUP(-2)": "USER.BACKUP.G0002V00",
+		"USER.BACKUP(+2)": "USER.BACKUP.G0006V00",
+		"USER.OTHER":      "USER.OTHER",
+	}
+	for relative, expected := range tests {
+		if name, err := c.ResolveGeneration(ctx, relative); err != nil || name != expected {
+			t.Fatalf("%s expected: %s, got %s, %v", relative, expected, name, err)
+		}
+	}
+	name, _ := c.ResolveGeneration(ctx, "USER.BACKUP(-1)")
+	if out, err := c.Read(ctx, name, nil); err != nil || out != "THREE" {
+		t.Fatalf("expected: THREE, got %q, %v", out, err)
+	}
+
+	if err := c.AlterGdg(ctx, "USER.BACKUP", &zoau.AlterGdgArgs{Limit: zoau.Uint(1), Empty: zoau.Bool(false)}); err != nil {
+		t.Fatal(err)
+	}
+	if generations, err := c.ListGenerations(ctx, "USER.BACKUP"); err != nil || len(generations) != 1 {
+		t.Fatalf("expected: 1 generation, got %v, %v", generations, err)
+	}
+
+	if err := c.DeleteGdg(ctx, "USER.BACKUP", nil); err == nil {
+		t.Fatal("Deleting a GDG with generations must fail without Force")
+	}
+	if err := c.DeleteGdg(ctx, "USER.BACKUP", &zoau.DeleteGdgArgs{Force: true}); err != nil {
+		t.Fatal(err)
+	}
+	if exist, err := c.Exist(ctx, "USER.BACKUP*"); err != nil || exist {
+		t.Fatalf("expected: false, got %v, %v", exist, err)
+	}
+	if err := c.DeleteGdg(ctx, "USER.BACKUP", nil); !errors.Is(err, zoau.ErrNotFound) {
+		t.Fatalf("expected: %v, got %v", zoau.ErrNotFound, err)
+	}
+}
+
+func TestGdgDryRun(t *testing.T) {
+	plan := &zoau.Plan{}
+	c := zoau.NewClient(&zoau.ClientArgs{Executor: zoautest.NewSimulator("USER"), DryRun: plan})
+	order := zoau.GDG_ORDER_LIFO
+	if err := c.DefineGdg(context.Background(), "USER.BACKUP", &zoau.GdgArgs{Limit: 7, Empty: true, Order: &order}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "mvscmd --pgm=IDCAMS '--sysprint=*' --sysin=stdin <<'EOF'\n" +
+		" DEFINE GENERATIONDATAGROUP ( -\n" +
+		"   NAME(USER.BACKUP) -\n" +
+		"   LIMIT(7) -\n" +
+		"   NOSCRATCH -\n" +
+		"   EMPTY -\n" +
+		"   LIFO -\n" +
+		"   )\n" +
+		"EOF\n"
+	if plan.String() != expected {
+		t.Fatalf("expected: %s, got %s", expected, plan.String())
+	}
+}
+
+func TestGdgWrap(t *testing.T) {
+	ctx := context.Background()
+	c := zoau.NewClient(&zoau.ClientArgs{Executor: zoautest.NewSimulator("USER")})
+	if err := c.DefineGdg(ctx, "USER.LOG", &zoau.GdgArgs{Limit: 3}); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"USER.LOG.G9998V00", "USER.LOG.G9999V00"} {
+		if err := c.Write(ctx, name, name, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, expected := range []string{"USER.LOG.G0001V00", "USER.LOG.G0002V00"} {
+		name, err := c.ResolveGeneration(ctx, "USER.LOG(+1)")
+		if err != nil || name != expected {
+			t.Fatalf("expected: %s, got %s, %v", expected, name, err)
+		}
+		if err := c.Write(ctx, name, name, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	generations, err := c.ListGenerations(ctx, "USER.LOG")
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, g := range generations {
+		names = append(names, g.Name)
+	}
+	if strings.Join(names, " ") != "USER.LOG.G9999V00 USER.LOG.G0001V00 USER.LOG.G0002V00" {
+		t.Fatalf("Unexpected generations %v", names)
+	}
+	if name, err := c.ResolveGeneration(ctx, "USER.LOG(-2)"); err != nil || name != "USER.LOG.G9999V00" {
+		t.Fatalf("expected: USER.LOG.G9999V00, got %s, %v", name, err)
+	}
+}
+
+func TestIdcamsWarning(t *testing.T) {
+	fake := &fakeExecutor{results: map[string]zoau.Result{
+		"mvscmd": {Rc: 4, Stdout: "IDC0001I FUNCTION COMPLETED, HIGHEST CONDITION CODE WAS 4\n"},
+	}}
+	c := zoau.NewClient(&zoau.ClientArgs{Executor: fake})
+	if err := c.AlterGdg(context.Background(), "USER.LOG", &zoau.AlterGdgArgs{Limit: zoau.Uint(5)}); err != nil {
+		t.Fatalf("Condition code 4 must not fail, got %v", err)
+	}
+
+	fake.results["mvscmd"] = zoau.Result{Rc: 8, Stdout: "IDC0001I FUNCTION COMPLETED, HIGHEST CONDITION CODE WAS 8\n"}
+	if err := c.AlterGdg(context.Background(), "USER.LOG", &zoau.AlterGdgArgs{Limit: zoau.Uint(5)}); err == nil {
+		t.Fatal("Condition code 8 must fail")
+	}
+}
@@ -1,3 +1,5 @@
 module github.com/Stolkerve/zoau-go
 
 go 1.21.1
+
+require golang.org/x/text v0.21.0
@@ -1,11 +1,17 @@
 package zoau
 
 import (
+	"context"
 	"strings"
 	"time"
 )
 
+// CancelJob runs Client.CancelJob on the default client.
 func CancelJob(jobId string, args *CancelJobArgs) error {
+	return DefaultClient().CancelJob(context.Background(), jobId, args)
+}
+
+func (c *Client) CancelJob(ctx context.Context, jobId string, args *CancelJobArgs) error {
 	options := make([]string, 0)
 	if args != nil {
 		if args.Purge {
@@ -23,12 +29,15 @@ func CancelJob(jobId string, args *CancelJobArgs) error {
 
 	options = append(options, jobId)
 
-	if _, _, err := execZaouCmd("jcan", options); err != nil {
+	if _, _, err := c.execZaouCmd(ctx, "jcan", options); err != nil {
 		return err
 	}
+	if c.plan != nil {
+		return nil
+	}
 
 	duration := time.Second * 10
-	if args.Timeout != nil {
+	if args != nil && args.Timeout != nil {
 		duration = *args.Timeout
 	}
 
@@ -36,14 +45,15 @@ func CancelJob(jobId string, args *CancelJobArgs) error {
 		Job *Job
 		Err error
 	}, 1)
-	timeOutChan := make(chan struct{}, 1)
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
 
 	asyncGetJob := func(jobId string, jobChan chan struct {
 		Job *Job
 		Err error
 	},
 	) {
-		job, err := GetJob(jobId)
+		job, err := c.GetJob(ctx, jobId)
 		jobChan <- struct {
 			Job *Job
 			Err error
@@ -54,7 +64,6 @@ func CancelJob(jobId string, args *CancelJobArgs) error {
 	}
 
 	go asyncGetJob(jobId, jobChan)
-	go timeOut(duration, timeOutChan)
 
 	for {
 		select {
@@ -63,21 +72,35 @@ func CancelJob(jobId string, args *CancelJobArgs) error {
 				return j.Err
 			}
 			go asyncGetJob(jobId, jobChan)
-		case <-timeOutChan:
+		case <-timer.C:
 			return nil
+		case <-ctx.Done():
+			return ctx.Err()
 		}
 	}
 }
 
+// GetJob runs Client.GetJob on the default client.
 func GetJob(jobId string) (*Job, error) {
-	if out, err := ListingJobs(&jobId, nil); err != nil {
+	return DefaultClient().GetJob(context.Background(), jobId)
+}
+
+func (c *Client) GetJob(ctx context.Context, jobId string) (*Job, error) {
+	if out, err := c.ListingJobs(ctx, &jobId, nil); err != nil {
 		return nil, err
+	} else if len(out) == 0 {
+		return nil, nil
 	} else {
 		return &out[0], nil
 	}
 }
 
+// ListingJobs runs Client.ListingJobs on the default client.
 func ListingJobs(jobId *string, jobOwner *string) ([]Job, error) {
+	return DefaultClient().ListingJobs(context.Background(), jobId, jobOwner)
+}
+
+func (c *Client) ListingJobs(ctx context.Context, jobId *string, jobOwner *string) ([]Job, error) {
 	pattern := ""
 	if jobId != nil {
 		pattern += "/" + *jobId
@@ -85,23 +108,26 @@ func ListingJobs(jobId *string, jobOwner *string) ([]Job, error) {
 		pattern += "/" + *jobOwner
 	}
 
-	stdout, _, err := execZaouCmd("jls", []string{pattern})
+	stdout, _, err := c.execZaouCmd(ctx, "jls", []string{pattern})
 	if err != nil {
 		return nil, err
 	}
 
 	lines := strings.Split(stdout, "\n")
 
-	jobs := make([]Job, len(lines))
-	for i, l := range lines {
+	jobs := make([]Job, 0, len(lines))
+	for _, l := range lines {
 		output := ParseLine(l)
-		jobs[i] = Job{
+		if len(output) < 5 {
+			continue
+		}
+		jobs = append(jobs, Job{
 			Owner:  defaultOrNil(output[0]),
 			Name:   defaultOrNil(output[1]),
 			Id:     defaultOrNil(output[2]),
 			Status: defaultOrNil(output[3]),
 			Rc:     defaultOrNil(output[4]),
-		}
+		})
 	}
 
 	return jobs, nil
@@ -114,7 +140,12 @@ func defaultOrNil(value string) *string {
 	return &value
 }
 
+// ListJobDDs runs Client.ListJobDDs on the default client.
 func ListJobDDs(jobId string, args *JobDDsArgs) ([]JobDDs, error) {
+	return DefaultClient().ListJobDDs(context.Background(), jobId, args)
+}
+
+func (c *Client) ListJobDDs(ctx context.Context, jobId string, args *JobDDsArgs) ([]JobDDs, error) {
 	options := []string{jobId}
 	if args != nil {
 		pattern := ""
@@ -127,16 +158,19 @@ func ListJobDDs(jobId string, args *JobDDsArgs) ([]JobDDs, error) {
 		options = append(options, pattern)
 	}
 
-	stdout, _, err := execZaouCmd("ddls", options)
+	stdout, _, err := c.execZaouCmd(ctx, "ddls", options)
 	if err != nil {
 		return nil, err
 	}
 
 	lines := strings.Split(stdout, "\n")
-	jobsDDs := make([]JobDDs, len(lines))
+	jobsDDs := make([]JobDDs, 0, len(lines))
 
-	for i, l := range lines {
+	for _, l := range lines {
 		output := ParseLine(l)
+		if len(output) < 6 {
+			continue
+		}
 		jobDDs := JobDDs{
 			StepName: output[0],
 			Dataset:  output[1],
@@ -149,13 +183,18 @@ func ListJobDDs(jobId string, args *JobDDsArgs) ([]JobDDs, error) {
 			jobDDs.ProcStep = &output[2]
 		}
 
-		jobsDDs[i] = jobDDs
+		jobsDDs = append(jobsDDs, jobDDs)
 	}
 
 	return jobsDDs, nil
 }
 
+// ReadJobOutput runs Client.ReadJobOutput on the default client.
 func ReadJobOutput(jobId string, stepname string, dataset string, args *ReadJobOutputArgs) (string, error) {
+	return DefaultClient().ReadJobOutput(context.Background(), jobId, stepname, dataset, args)
+}
+
+func (c *Client) ReadJobOutput(ctx context.Context, jobId string, stepname string, dataset string, args *ReadJobOutputArgs) (string, error) {
 	options := []string{jobId, stepname}
 
 	if args != nil {
@@ -177,14 +216,25 @@ func ReadJobOutput(jobId string, stepname string, dataset string, args *ReadJobO
 		options = append(options, pattern)
 	}
 
-	return execSimpleStringCmd("pjdd", options)
+	return c.execSimpleStringCmd(ctx, "pjdd", options)
 }
 
+// SubmitJob runs Client.SubmitJob on the default client.
 func SubmitJob(dataset string, args *SubmitArgs) (*Job, error) {
-	jobId, err := execSimpleStringCmd("jsub", []string{dataset})
+	return DefaultClient().SubmitJob(context.Background(), dataset, args)
+}
+
+// SubmitJob submits the JCL of dataset. In dry-run mode it returns a placeholder Job whose Status is
+// "PLANNED" and whose other fields are empty.
+func (c *Client) SubmitJob(ctx context.Context, dataset string, args *SubmitArgs) (*Job, error) {
+	jobId, err := c.execSimpleStringCmd(ctx, "jsub", []string{dataset})
 	if err != nil {
 		return nil, err
 	}
+	if c.plan != nil {
+		status := "PLANNED"
+		return &Job{Status: &status}, nil
+	}
 
 	duration := time.Second * 10
 	if args != nil {
@@ -196,9 +246,9 @@ func SubmitJob(dataset string, args *SubmitArgs) (*Job, error) {
 		}
 	}
 
-	timeOutChan := make(chan struct{}, 0)
-	timeOut(duration, timeOutChan)
-	<-timeOutChan
+	if err := sleepContext(ctx, duration); err != nil {
+		return nil, err
+	}
 
-	return GetJob(jobId)
+	return c.GetJob(ctx, jobId)
 }
@@ -0,0 +1,25 @@
+package zoau_test
+
+import (
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/Stolkerve/zoau-go"
+	"github.com/Stolkerve/zoau-go/zoautest"
+)
+
+// TestMain runs the tests against the ZOAU simulator when the ZOAU utilities are not installed.
+func TestMain(m *testing.M) {
+	if _, err := exec.LookPath("dls"); err != nil {
+		user := strings.ToUpper(os.Getenv("USER"))
+		if !regexp.MustCompile(`^[A-Z#$@][A-Z0-9#$@]{0,7}$`).MatchString(user) {
+			user = "ZOAUSIM"
+		}
+		os.Setenv("USER", user)
+		zoau.SetDefaultClient(zoau.NewClient(&zoau.ClientArgs{Executor: zoautest.NewSimulator(user)}))
+	}
+	os.Exit(m.Run())
+}
@@ -0,0 +1,171 @@
+package zoau
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// Event describes a ZOAU command that has been run by a Client.
+type Event struct {
+	// The command that was run.
+	Command Command
+
+	// Attempt number, counted from 1. Greater than 1 when the command is retried, see RetryPolicy.
+	Attempt int
+
+	// Return code of the command. -1 if it did not run to completion.
+	Rc int
+
+	// Time the command was started.
+	Start time.Time
+
+	// How long the command ran.
+	Duration time.Duration
+
+	// Error returned by the Client for the command, nil on success.
+	Err error
+}
+
+// Middleware observes every command run by a Client.
+//
+// The middlewares of a Client are composed as a chain: their Before hooks are called in order before
+// the command runs and their After hooks in reverse order once it has finished.
+type Middleware interface {
+	// Called before the command runs. The returned context is passed to the executor and to After,
+	// which lets a middleware carry per-command state such as a tracing span.
+	Before(ctx context.Context, cmd Command) context.Context
+
+	// Called once the command has finished.
+	After(ctx context.Context, event Event)
+}
+
+// MiddlewareFuncs adapts a pair of functions to the Middleware interface. Either function may be nil.
+type MiddlewareFuncs struct {
+	BeforeFunc func(ctx context.Context, cmd Command) context.Context
+	AfterFunc  func(ctx context.Context, event Event)
+}
+
+func (m MiddlewareFuncs) Before(ctx context.Context, cmd Command) context.Context {
+	if m.BeforeFunc == nil {
+		return ctx
+	}
+	return m.BeforeFunc(ctx, cmd)
+}
+
+func (m MiddlewareFuncs) After(ctx context.Context, event Event) {
+	if m.AfterFunc != nil {
+		m.AfterFunc(ctx, event)
+	}
+}
+
+type MiddlewareArgs struct {
+	// Masks secrets in the command arguments before they are written. Defaults to RedactArgs.
+	Redact func(args []string) []string
+}
+
+func (args *MiddlewareArgs) redact(params []string) []string {
+	if args != nil && args.Redact != nil {
+		return args.Redact(params)
+	}
+	return RedactArgs(params)
+}
+
+const redacted = "********"
+
+var (
+	// mvscmd DD statements and options whose name suggests a secret (e.g. --password=..., --pwfile=...).
+	secretOptionRegex = regexp.MustCompile(`(?i)^(--?[a-z0-9_#$@-]*(?:pass|pwd|pw|secret|token|key(?:lab|label)?|phrase)[a-z0-9_#$@-]*=).+$`)
+
+	// Keyword operands carrying passwords in control statements and TSO commands (e.g. PASSWORD(...), NEWPW=...).
+	secretKeywordRegex = regexp.MustCompile(`(?i)\b((?:PASSWORD|PASSWD|PASSPHRASE|NEWPASS|NEWPW|PWD|PW)(?:\(|=))[^)\s,]*`)
+)
+
+// RedactArgs returns a copy of args with the values of password-like options and keywords masked.
+func RedactArgs(args []string) []string {
+	out := make([]string, len(args))
+	for i, arg := range args {
+		if secretOptionRegex.MatchString(arg) {
+			out[i] = secretOptionRegex.ReplaceAllString(arg, "${1}"+redacted)
+			continue
+		}
+		out[i] = secretKeywordRegex.ReplaceAllString(arg, "${1}"+redacted)
+	}
+	return out
+}
+
+// NewSlogMiddleware returns a Middleware logging every command with its return code and duration.
+// Failed commands are logged at the error level, the others at the debug level.
+func NewSlogMiddleware(logger *slog.Logger, args *MiddlewareArgs) Middleware {
+	return MiddlewareFuncs{AfterFunc: func(ctx context.Context, event Event) {
+		level := slog.LevelDebug
+		attrs := []slog.Attr{
+			slog.String("command", event.Command.Name),
+			slog.Any("args", args.redact(event.Command.Args)),
+			slog.Int("rc", event.Rc),
+			slog.Int("attempt", event.Attempt),
+			slog.Duration("duration", event.Duration),
+		}
+		if event.Err != nil {
+			level = slog.LevelError
+			attrs = append(attrs, slog.String("error", event.Err.Error()))
+		}
+		logger.LogAttrs(ctx, level, "zoau command", attrs...)
+	}}
+}
+
+// AuditRecord is a line written by the audit middleware.
+type AuditRecord struct {
+	Time       time.Time `json:"time"`
+	Command    string    `json:"command"`
+	Args       []string  `json:"args"`
+	Attempt    int       `json:"attempt"`
+	Rc         int       `json:"rc"`
+	DurationMs int64     `json:"duration_ms"`
+	Error      string    `json:"error,omitempty"`
+}
+
+type auditMiddleware struct {
+	mu   sync.Mutex
+	w    io.Writer
+	args *MiddlewareArgs
+}
+
+// NewAuditMiddleware returns a Middleware writing a JSON line (an AuditRecord) to w for every command
+// that changes datasets, jobs or the system configuration. Read-only commands are not audited.
+func NewAuditMiddleware(w io.Writer, args *MiddlewareArgs) Middleware {
+	return &auditMiddleware{w: w, args: args}
+}
+
+func (m *auditMiddleware) Before(ctx context.Context, cmd Command) context.Context {
+	return ctx
+}
+
+func (m *auditMiddleware) After(ctx context.Context, event Event) {
+	if !isMutating(event.Command) {
+		return
+	}
+	record := AuditRecord{
+		Time:       event.Start.UTC(),
+		Command:    event.Command.Name,
+		Args:       m.args.redact(event.Command.Args),
+		Attempt:    event.Attempt,
+		Rc:         event.Rc,
+		DurationMs: event.Duration.Milliseconds(),
+	}
+	if event.Err != nil {
+		record.Error = event.Err.Error()
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.w.Write(append(line, '\n'))
+}
@@ -0,0 +1,107 @@
+package zoau_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Stolkerve/zoau-go"
+	"github.com/Stolkerve/zoau-go/zoautest"
+)
+
+type traceKey struct{}
+
+func TestMiddleware(t *testing.T) {
+	ctx := context.Background()
+	calls := []string{}
+	trace := func(name string) zoau.Middleware {
+		return zoau.MiddlewareFuncs{
+			BeforeFunc: func(ctx context.Context, cmd zoau.Command) context.Context {
+				calls = append(calls, name+" before "+cmd.Name)
+				return context.WithValue(ctx, traceKey{}, name)
+			},
+			AfterFunc: func(ctx context.Context, event zoau.Event) {
+				calls = append(calls, name+" after "+event.Command.Name+" "+ctx.Value(traceKey{}).(string))
+			},
+		}
+	}
+
+	var audit, logs bytes.Buffer
+	c := zoau.NewClient(&zoau.ClientArgs{
+		Executor: zoautest.NewSimulator("USER"),
+		Middlewares: []zoau.Middleware{
+			trace("outer"),
+			trace("inner"),
+			zoau.NewAuditMiddleware(&audit, nil),
+			zoau.NewSlogMiddleware(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})), nil),
+		},
+	})
+
+	if err := c.Write(ctx, "USER.PARMS", "A=1", false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Read(ctx, "USER.PARMS", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Read(ctx, "USER.MISSING", nil); !errors.Is(err, zoau.ErrNotFound) {
+		t.Fatalf("expected: %v, got %v", zoau.ErrNotFound, err)
+	}
+
+	expected := []string{
+		"outer before dcp", "inner before dcp", "inner after dcp inner", "outer after dcp inner",
+		"outer before dtail", "inner before dtail", "inner after dtail inner", "outer after dtail inner",
+		"outer before dtail", "inner before dtail", "inner after dtail inner", "outer after dtail inner",
+	}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Fatalf("expected: %q, got %q", expected, calls)
+	}
+
+	lines := strings.Split(strings.TrimSpace(audit.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Only the write must be audited, got %q", lines)
+	}
+	var record zoau.AuditRecord
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatal(err)
+	}
+	if record.Command != "dcp" || record.Rc != 0 || record.Args[len(record.Args)-1] != "USER.PARMS" {
+		t.Fatalf("Unexpected audit record %+v", record)
+	}
+
+	if n := strings.Count(logs.String(), "level=DEBUG"); n != 2 {
+		t.Fatalf("expected: 2 debug lines, got %d in %s", n, logs.String())
+	}
+	if !strings.Contains(logs.String(), "level=ERROR") || !strings.Contains(logs.String(), "rc=") {
+		t.Fatalf("The failed read must be logged as an error, got %s", logs.String())
+	}
+}
+
+func TestRedactArgs(t *testing.T) {
+	args := []string{
+		"--pgm=IKJEFT01",
+		"--password=hunter2",
+		"--pwfile=USER.SECRET",
+		"--sysin=USER.SYSIN",
+		"ALTUSER IBMUSER PASSWORD(hunter2) NOEXPIRED",
+		"LOGON PW=secret,OTHER",
+	}
+	expected := []string{
+		"--pgm=IKJEFT01",
+		"--password=********",
+		"--pwfile=********",
+		"--sysin=USER.SYSIN",
+		"ALTUSER IBMUSER PASSWORD(********) NOEXPIRED",
+		"LOGON PW=********,OTHER",
+	}
+	if got := zoau.RedactArgs(args); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected: %q, got %q", expected, got)
+	}
+	if args[1] != "--password=hunter2" {
+		t.Fatal("RedactArgs must not modify its argument")
+	}
+}
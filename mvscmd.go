@@ -1,45 +1,117 @@
 package zoau
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strings"
 )
 
+// Execute runs Client.Execute on the default client.
+func Execute(pgm string, pgmArgs *string, dds []DDStatement, args *Args) (string, int) {
+	return DefaultClient().Execute(context.Background(), pgm, pgmArgs, dds, args)
+}
+
 // Execute an MVS Program.
 // Returns the stdout or the stderr and the return code
-func Execute(pgm string, pgmArgs *string, dds []DDStatement, args *Args) (string, int) {
-	options := make([]string, 0)
+// The return code is -1 if ctx is done before the program ends.
+func (c *Client) Execute(ctx context.Context, pgm string, pgmArgs *string, dds []DDStatement, args *Args) (string, int) {
+	out, rc, _ := c.execCommand(ctx, mvscmdCommand("mvscmd", pgm, pgmArgs, dds, args))
+	return out, rc
+}
+
+// ExecuteAuthorized runs Client.ExecuteAuthorized on the default client.
+func ExecuteAuthorized(pgm string, pgmArgs *string, dds []DDStatement, args *Args) (string, int) {
+	return DefaultClient().ExecuteAuthorized(context.Background(), pgm, pgmArgs, dds, args)
+}
 
-	options = append(options, parseUniversalArgs(*args)...)
+// Execute an authorized MVS Program.
+// Returns the stdout or the stderr and the return code
+// The return code is -1 if ctx is done before the program ends.
+func (c *Client) ExecuteAuthorized(ctx context.Context, pgm string, pgmArgs *string, dds []DDStatement, args *Args) (string, int) {
+	out, rc, _ := c.execCommand(ctx, mvscmdCommand("mvscmdauth", pgm, pgmArgs, dds, args))
+	return out, rc
+}
 
+// mvscmdCommand returns the mvscmd or mvscmdauth command running pgm. The content of an InputDefinition
+// is fed to the standard input of the command.
+func mvscmdCommand(name string, pgm string, pgmArgs *string, dds []DDStatement, args *Args) Command {
+	options := make([]string, 0)
+
+	if args != nil {
+		options = append(options, parseUniversalArgs(*args)...)
+	}
 	if pgmArgs != nil {
 		options = append(options, "--args="+*pgmArgs)
 	}
 
 	options = append(options, "--pgm="+pgm)
 
+	cmd := Command{Name: name}
 	for _, dd := range dds {
+		if input, ok := dd.Definition.(*InputDefinition); ok && cmd.Stdin == nil {
+			cmd.Stdin = strings.NewReader(input.records())
+		}
 		options = append(options, fmt.Sprintf("--%s=%s", dd.Name, dd.Definition.buildArgsString()))
 	}
-	out, rc, _ := execZaouCmd("mvscmd", options)
-	return out, rc
+	cmd.Args = options
+	return cmd
 }
 
-// Execute an authorized MVS Program.
-// Returns the stdout or the stderr and the return code
-func ExecuteAuthorized(pgm string, pgmArgs *string, dds []DDStatement, args *Args) (string, int) {
-	options := make([]string, 0)
+// IDCAMS commands whose condition code 4 only reports warnings, such as the attributes adjusted by
+// DEFINE and ALTER or the entries LISTCAT could not describe in full. REPRO, BLDINDEX and DELETE are
+// not listed: their condition code 4 can mean skipped or dropped records or entries.
+var idcamsWarningCommands = map[string]bool{
+	"DEFINE":  true,
+	"ALTER":   true,
+	"LISTCAT": true,
+	"PRINT":   true,
+}
 
-	options = append(options, parseUniversalArgs(*args)...)
-	if pgmArgs != nil {
-		options = append(options, "--args="+*pgmArgs)
+// idcams runs IDCAMS with the control statements on SYSIN and returns its SYSPRINT. Condition code 4
+// is not an error for the commands of idcamsWarningCommands, unless an entry is not found (e.g. by
+// LISTCAT).
+func (c *Client) idcams(ctx context.Context, authorized bool, statements ...string) (string, error) {
+	name := "mvscmd"
+	if authorized {
+		name = "mvscmdauth"
+	}
+	dds := []DDStatement{
+		{Name: "sysprint", Definition: &ValueDefinition{V: "*"}},
+		{Name: "sysin", Definition: &InputDefinition{Content: strings.Join(statements, "\n")}},
+	}
+	stdout, _, err := c.execCommand(ctx, mvscmdCommand(name, "IDCAMS", nil, dds, nil))
+	var cmdErr *CommandError
+	if errors.As(err, &cmdErr) && cmdErr.Rc > 0 && cmdErr.Rc <= 4 && !errors.Is(err, ErrNotFound) && idcamsWarnings(statements) {
+		return stdout, nil
 	}
+	return stdout, err
+}
 
-	options = append(options, "--pgm="+pgm)
+// idcamsWarnings reports whether the condition code 4 of every statement only reports warnings.
+func idcamsWarnings(statements []string) bool {
+	for _, statement := range statements {
+		if fields := strings.Fields(statement); len(fields) == 0 || !idcamsWarningCommands[fields[0]] {
+			return false
+		}
+	}
+	return true
+}
 
-	for _, dd := range dds {
-		options = append(options, fmt.Sprintf("--%s=%s", dd.Name, dd.Definition.buildArgsString()))
+// idcamsStatement formats an IDCAMS command with one parameter per line, using continuations.
+func idcamsStatement(command string, params ...string) string {
+	var b strings.Builder
+	b.WriteString(" " + command)
+	for _, p := range params {
+		b.WriteString(" -\n   " + p)
 	}
+	return b.String()
+}
 
-	out, rc, _ := execZaouCmd("mvscmdauth", options)
-	return out, rc
+// idcamsFlag returns the IDCAMS keyword on if set is true, else its opposite off (e.g. SCRATCH and NOSCRATCH).
+func idcamsFlag(set bool, on string, off string) string {
+	if set {
+		return on
+	}
+	return off
 }
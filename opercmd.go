@@ -1,3 +1,31 @@
 package zoau
 
+import (
+	"context"
+	"fmt"
+)
+
+// Deprecated: ExecuteOperCmd does nothing, use OperCmd.
 func ExecuteOperCmd() {}
+
+// OperCmd runs Client.OperCmd on the default client.
+func OperCmd(command string, args *OperCmdArgs) (string, error) {
+	return DefaultClient().OperCmd(context.Background(), command, args)
+}
+
+// Issues an operator command (e.g. "D A,L") with opercmd and returns its response.
+func (c *Client) OperCmd(ctx context.Context, command string, args *OperCmdArgs) (string, error) {
+	options := make([]string, 0)
+	if args != nil {
+		if args.Timeout != nil {
+			options = append(options, "-t", fmt.Sprintf("%d", *args.Timeout))
+		}
+		if args.Verbose {
+			options = append(options, "-v")
+		}
+	}
+
+	options = append(options, command)
+
+	return c.execSimpleStringCmd(ctx, "opercmd", options)
+}
@@ -0,0 +1,181 @@
+package zoau
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Pattern is a compiled dataset or member name pattern, interpreted as dls, mls and the ISPF data set
+// list do:
+//
+//   - % matches a single character, other than a period.
+//   - * matches zero or more characters within a qualifier. A qualifier made of a single * matches
+//     exactly one qualifier.
+//   - ** matches zero or more characters across qualifiers. A qualifier made of ** matches zero or more
+//     qualifiers, so "USER.**" matches "USER", "USER.DATA" and "USER.DATA.SET".
+//
+// A Pattern is safe for concurrent use.
+type Pattern struct {
+	source  string
+	regex   *regexp.Regexp
+	prefix  string
+	literal bool
+}
+
+var (
+	patternQualifierRegex = regexp.MustCompile(`^[A-Z0-9@#$%*-]+$`)
+	patternMemberRegex    = regexp.MustCompile(`^[A-Z0-9@#$%*]+$`)
+)
+
+// CompilePattern compiles a dataset name pattern ("USER.*.DATA", "USER.**"), optionally followed by
+// a member pattern between parentheses ("USER.PDS(AB*)"). Patterns are upper cased.
+func CompilePattern(pattern string) (*Pattern, error) {
+	s := strings.ToUpper(strings.TrimSpace(pattern))
+	name, member, hasMember := s, "", false
+	if open := strings.IndexByte(s, '('); open >= 0 {
+		if s[len(s)-1] != ')' {
+			return nil, fmt.Errorf("%w %q: missing closing parenthesis", ErrInvalidName, pattern)
+		}
+		name, member, hasMember = s[:open], s[open+1:len(s)-1], true
+	}
+
+	expr, err := datasetPatternExpr(name)
+	if err == nil && hasMember {
+		var memberExpr string
+		memberExpr, err = memberPatternExpr(member)
+		expr += `\(` + memberExpr + `\)`
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %v", ErrInvalidName, pattern, err)
+	}
+	return newPattern(s, expr), nil
+}
+
+// CompileMemberPattern compiles a member name pattern ("AB*", "MEM%%"). Patterns are upper cased.
+func CompileMemberPattern(pattern string) (*Pattern, error) {
+	s := strings.ToUpper(strings.TrimSpace(pattern))
+	expr, err := memberPatternExpr(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %v", ErrInvalidName, pattern, err)
+	}
+	return newPattern(s, expr), nil
+}
+
+// MustCompilePattern is like CompilePattern but panics if the pattern is invalid.
+func MustCompilePattern(pattern string) *Pattern {
+	p, err := CompilePattern(pattern)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+func newPattern(source string, expr string) *Pattern {
+	p := &Pattern{source: source, regex: regexp.MustCompile("^" + expr + "$")}
+	wildcard := strings.IndexAny(source, "*%")
+	if wildcard < 0 {
+		p.prefix, p.literal = source, true
+		return p
+	}
+	p.prefix = source[:wildcard]
+	// A ** qualifier also matches no qualifier, the period before it is not part of every match.
+	if strings.HasSuffix(p.prefix, ".") && strings.HasPrefix(source[wildcard:], "**") &&
+		(wildcard+2 == len(source) || source[wildcard+2] == '.' || source[wildcard+2] == '(') {
+		p.prefix = p.prefix[:len(p.prefix)-1]
+	}
+	return p
+}
+
+// datasetPatternExpr translates a dataset name pattern into a regular expression.
+func datasetPatternExpr(pattern string) (string, error) {
+	if pattern == "" {
+		return "", fmt.Errorf("empty pattern")
+	}
+	qualifiers := make([]string, 0)
+	for _, q := range strings.Split(pattern, ".") {
+		// Consecutive ** qualifiers match the same as a single one.
+		if q != "**" || len(qualifiers) == 0 || qualifiers[len(qualifiers)-1] != "**" {
+			qualifiers = append(qualifiers, q)
+		}
+	}
+	var expr strings.Builder
+	separator := false
+	for i, q := range qualifiers {
+		switch {
+		case q == "":
+			return "", fmt.Errorf("empty qualifier")
+		case !patternQualifierRegex.MatchString(q):
+			return "", fmt.Errorf("qualifier %q must be letters, digits, national characters, hyphens or wildcards", q)
+		case strings.Contains(q, "***"):
+			return "", fmt.Errorf("qualifier %q has more than two consecutive *", q)
+		case q == "**" && len(qualifiers) == 1:
+			expr.WriteString(`[^.]+(\.[^.]+)*`)
+		case q == "**" && i == 0:
+			expr.WriteString(`([^.]+\.)*`)
+			separator = false
+			continue
+		case q == "**":
+			expr.WriteString(`(\.[^.]+)*`)
+			continue
+		default:
+			if separator {
+				expr.WriteString(`\.`)
+			}
+			if q == "*" {
+				expr.WriteString(`[^.]+`)
+			} else {
+				expr.WriteString(wildcardExpr(q, `.*`, `[^.]*`, `[^.]`))
+			}
+		}
+		separator = true
+	}
+	return expr.String(), nil
+}
+
+// memberPatternExpr translates a member name pattern into a regular expression.
+func memberPatternExpr(pattern string) (string, error) {
+	if !patternMemberRegex.MatchString(pattern) {
+		return "", fmt.Errorf("member pattern %q must be letters, digits, national characters or wildcards", pattern)
+	}
+	if len(strings.ReplaceAll(pattern, "*", "")) > 8 {
+		return "", fmt.Errorf("member pattern %q is longer than 8 characters", pattern)
+	}
+	return wildcardExpr(pattern, `.*`, `.*`, `.`), nil
+}
+
+// wildcardExpr quotes s, replacing its **, * and % wildcards by the given expressions.
+func wildcardExpr(s string, across string, star string, single string) string {
+	var expr strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case strings.HasPrefix(s[i:], "**"):
+			expr.WriteString(across)
+			i++
+		case s[i] == '*':
+			expr.WriteString(star)
+		case s[i] == '%':
+			expr.WriteString(single)
+		default:
+			expr.WriteString(regexp.QuoteMeta(s[i : i+1]))
+		}
+	}
+	return expr.String()
+}
+
+// Match reports whether name matches the pattern. Names are compared upper cased.
+func (p *Pattern) Match(name string) bool {
+	return p.regex.MatchString(strings.ToUpper(strings.TrimSpace(name)))
+}
+
+// LiteralPrefix returns the prefix shared by all the names matching the pattern, to narrow a query
+// made to ZOAU before filtering its results with Match. complete is true when the prefix is the whole
+// pattern, which has no wildcard.
+func (p *Pattern) LiteralPrefix() (prefix string, complete bool) {
+	return p.prefix, p.literal
+}
+
+// String returns the source of the pattern, upper cased.
+func (p *Pattern) String() string {
+	return p.source
+}
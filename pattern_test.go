@@ -0,0 +1,210 @@
+package zoau_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Stolkerve/zoau-go"
+)
+
+func TestPatternMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		match   bool
+	}{
+		// Literal names.
+		{"USER.DATA", "USER.DATA", true},
+		{"user.data", "USER.DATA", true},
+		{"USER.DATA", "user.data", true},
+		{"USER.DATA", "USER.DATA2", false},
+		{"USER.DATA", "USER.DAT", false},
+		{"USER.DATA", "USER.DATA.X", false},
+		{"USER.A-B", "USER.A-B", true},
+		{"@#$.DATA", "@#$.DATA", true},
+
+		// % matches one character within a qualifier.
+		{"USER.DAT%", "USER.DATA", true},
+		{"USER.DAT%", "USER.DAT", false},
+		{"USER.DAT%", "USER.DATAX", false},
+		{"USER.%%%%", "USER.DATA", true},
+		{"USER%DATA", "USER.DATA", false},
+		{"%", "A", true},
+
+		// * matches within a qualifier.
+		{"USER.*", "USER.DATA", true},
+		{"USER.*", "USER", false},
+		{"USER.*", "USER.DATA.SET", false},
+		{"USER.D*", "USER.DATA", true},
+		{"USER.D*", "USER.D", true},
+		{"USER.D*", "USER.XDATA", false},
+		{"USER.*A", "USER.DATA", true},
+		{"USER.*A", "USER.A", true},
+		{"USER.*A", "USER.DATAB", false},
+		{"USER.D*A", "USER.DA", true},
+		{"USER.D*A", "USER.DATA", true},
+		{"USER.D*A", "USER.D.A", false},
+		{"USER.*.SET", "USER.DATA.SET", true},
+		{"USER.*.SET", "USER.SET", false},
+		{"USER.*.SET", "USER.A.B.SET", false},
+		{"*.DATA", "USER.DATA", true},
+		{"*.DATA", "DATA", false},
+		{"*", "USER", true},
+		{"*", "USER.DATA", false},
+		{"U*R.*", "USER.DATA", true},
+		{"USER.D*T%", "USER.DATA", true},
+		{"USER.D*T%", "USER.DAT", false},
+
+		// ** as a qualifier matches zero or more qualifiers.
+		{"USER.**", "USER", true},
+		{"USER.**", "USER.DATA", true},
+		{"USER.**", "USER.DATA.SET", true},
+		{"USER.**", "USERX.DATA", false},
+		{"USER.**", "OTHER.USER", false},
+		{"**.SET", "SET", true},
+		{"**.SET", "USER.SET", true},
+		{"**.SET", "USER.DATA.SET", true},
+		{"**.SET", "USER.DATASET", false},
+		{"**.SET", "USER.SET.X", false},
+		{"USER.**.SET", "USER.SET", true},
+		{"USER.**.SET", "USER.DATA.SET", true},
+		{"USER.**.SET", "USER.A.B.C.SET", true},
+		{"USER.**.SET", "USER.DATASET", false},
+		{"USER.**.SET", "OTHER.DATA.SET", false},
+		{"USER.**.**", "USER.A.B", true},
+		{"**.**", "USER.DATA", true},
+		{"**", "USER", true},
+		{"**", "USER.DATA.SET", true},
+		{"**.DATA.**", "USER.DATA.SET", true},
+		{"**.DATA.**", "DATA", true},
+		{"**.DATA.**", "USER.DATASET", false},
+		{"USER.*.**", "USER", false},
+		{"USER.*.**", "USER.A", true},
+		{"USER.*.**", "USER.A.B.C", true},
+
+		// ** within a qualifier matches across qualifiers.
+		{"USER.D**", "USER.DATA", true},
+		{"USER.D**", "USER.DATA.SET", true},
+		{"USER.D**", "USER.XDATA", false},
+		{"USER.**T", "USER.DATA.SET", true},
+		{"USER.**T", "USER.DATA.SETS", false},
+		{"US**ET", "USER.DATA.SET", true},
+
+		// Members.
+		{"USER.PDS(MEM)", "USER.PDS(MEM)", true},
+		{"USER.PDS(MEM)", "USER.PDS(MEM2)", false},
+		{"USER.PDS(MEM)", "USER.PDS", false},
+		{"USER.PDS", "USER.PDS(MEM)", false},
+		{"USER.PDS(M*)", "USER.PDS(MEMBER)", true},
+		{"USER.PDS(M*)", "USER.PDS(XMEMBER)", false},
+		{"USER.PDS(*)", "USER.PDS(A)", true},
+		{"USER.PDS(MEM%)", "USER.PDS(MEM1)", true},
+		{"USER.PDS(MEM%)", "USER.PDS(MEM12)", false},
+		{"USER.*(A*)", "USER.PDS(ABC)", true},
+		{"USER.*(A*)", "USER.PDS.X(ABC)", false},
+		{"USER.**(A*)", "USER.PDS.X(ABC)", true},
+		{"USER.**(A*)", "USER.PDS.X", false},
+	}
+	for _, test := range tests {
+		p, err := zoau.CompilePattern(test.pattern)
+		if err != nil {
+			t.Fatalf("%q: %v", test.pattern, err)
+		}
+		if match := p.Match(test.name); match != test.match {
+			t.Errorf("%q matching %q: expected: %v, got %v", test.pattern, test.name, test.match, match)
+		}
+	}
+}
+
+func TestMemberPatternMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		match   bool
+	}{
+		{"MEMBER", "MEMBER", true},
+		{"member", "MEMBER", true},
+		{"MEMBER", "MEMBER1", false},
+		{"*", "A", true},
+		{"*", "ABCDEFGH", true},
+		{"A*", "ABC", true},
+		{"A*", "A", true},
+		{"A*", "BA", false},
+		{"*A", "BA", true},
+		{"*A*", "BAC", true},
+		{"*A*", "BCD", false},
+		{"A%C", "ABC", true},
+		{"A%C", "AC", false},
+		{"%%%", "ABC", true},
+		{"%%%", "ABCD", false},
+		{"A**", "ABC", true},
+		{"#@$*", "#@$1", true},
+	}
+	for _, test := range tests {
+		p, err := zoau.CompileMemberPattern(test.pattern)
+		if err != nil {
+			t.Fatalf("%q: %v", test.pattern, err)
+		}
+		if match := p.Match(test.name); match != test.match {
+			t.Errorf("%q matching %q: expected: %v, got %v", test.pattern, test.name, test.match, match)
+		}
+	}
+}
+
+func TestPatternLiteralPrefix(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		prefix   string
+		complete bool
+	}{
+		{"USER.DATA", "USER.DATA", true},
+		{"user.data", "USER.DATA", true},
+		{"USER.PDS(MEM)", "USER.PDS(MEM)", true},
+		{"USER.DATA.*", "USER.DATA.", false},
+		{"USER.DA*", "USER.DA", false},
+		{"USER.DA%A", "USER.DA", false},
+		{"USER.**", "USER", false},
+		{"USER.**.SET", "USER", false},
+		{"USER.**(A*)", "USER", false},
+		{"USER.D**", "USER.D", false},
+		{"USER.PDS(M*)", "USER.PDS(M", false},
+		{"**.SET", "", false},
+		{"*", "", false},
+	}
+	for _, test := range tests {
+		p := zoau.MustCompilePattern(test.pattern)
+		prefix, complete := p.LiteralPrefix()
+		if prefix != test.prefix || complete != test.complete {
+			t.Errorf("%q: expected: %q, %v, got %q, %v", test.pattern, test.prefix, test.complete, prefix, complete)
+		}
+		// Every name matching the pattern starts with its literal prefix.
+		if complete && !p.Match(prefix) {
+			t.Errorf("%q does not match its own literal prefix", test.pattern)
+		}
+	}
+}
+
+func TestPatternInvalid(t *testing.T) {
+	for _, pattern := range []string{
+		"",
+		"USER..DATA",
+		".USER",
+		"USER.",
+		"USER.DA TA",
+		"USER.D?TA",
+		"USER.***",
+		"USER.PDS(MEM",
+		"USER.PDS(MEM-1)",
+		"USER.PDS(ABCDEFGHI)",
+		"USER.PDS()",
+	} {
+		if _, err := zoau.CompilePattern(pattern); !errors.Is(err, zoau.ErrInvalidName) {
+			t.Errorf("%q: expected: %v, got %v", pattern, zoau.ErrInvalidName, err)
+		}
+	}
+	for _, pattern := range []string{"", "A.B", "ABCDEFGHI", "A(B)"} {
+		if _, err := zoau.CompileMemberPattern(pattern); !errors.Is(err, zoau.ErrInvalidName) {
+			t.Errorf("%q: expected: %v, got %v", pattern, zoau.ErrInvalidName, err)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package zoau
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Plan collects the commands a dry-run Client would have run. It is safe for concurrent use.
+type Plan struct {
+	mu       sync.Mutex
+	commands []Command
+}
+
+func (p *Plan) add(cmd Command) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.commands = append(p.commands, cmd)
+}
+
+// Commands returns the commands recorded so far, in order.
+func (p *Plan) Commands() []Command {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Command{}, p.commands...)
+}
+
+// String renders the plan as shell command lines, one per recorded command.
+func (p *Plan) String() string {
+	var b strings.Builder
+	for _, cmd := range p.Commands() {
+		b.WriteString(cmd.String())
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// String renders the command as a shell command line. A standard input that can be rewound
+// (e.g. the control statements of an InputDefinition) is rendered as a here-document.
+func (cmd Command) String() string {
+	words := make([]string, 0, len(cmd.Args)+1)
+	words = append(words, shellQuote(cmd.Name))
+	for _, arg := range cmd.Args {
+		words = append(words, shellQuote(arg))
+	}
+	line := strings.Join(words, " ")
+
+	if stdin, ok := cmd.Stdin.(io.ReadSeeker); ok {
+		if content, err := io.ReadAll(stdin); err == nil {
+			stdin.Seek(0, io.SeekStart)
+			if !strings.HasSuffix(string(content), "\n") {
+				content = append(content, '\n')
+			}
+			line += " <<'EOF'\n" + string(content) + "EOF"
+		}
+	}
+	return line
+}
+
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789@%+=:,./-_") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Utilities that change datasets, jobs or the system configuration.
+var mutatingCommands = map[string]bool{
+	"apfadm":     true,
+	"dcp":        true,
+	"decho":      true,
+	"dmod":       true,
+	"dmv":        true,
+	"drm":        true,
+	"dsed":       true,
+	"dtouch":     true,
+	"dunzip":     true,
+	"dzip":       true,
+	"jcan":       true,
+	"jsub":       true,
+	"mmv":        true,
+	"mrm":        true,
+	"mvscmd":     true,
+	"mvscmdauth": true,
+	"opercmd":    true,
+}
+
+// isMutating reports whether running cmd may change datasets, jobs or the system configuration.
+func isMutating(cmd Command) bool {
+	if cmd.Name == "apfadm" && len(cmd.Args) == 1 && (cmd.Args[0] == "-l" || cmd.Args[0] == "-lj" || cmd.Args[0] == "-F") {
+		return false
+	}
+	if (cmd.Name == "mvscmd" || cmd.Name == "mvscmdauth") && idcamsReadOnly(cmd) {
+		return false
+	}
+	if cmd.Name == "dcp" && len(cmd.Args) > 0 && isStagingFile(cmd.Args[len(cmd.Args)-1]) {
+		// OpenReader copies a dataset to a temporary file to read it.
+		return false
+	}
+	return mutatingCommands[cmd.Name]
+}
+
+// isStagingFile reports whether path is a temporary file created by OpenReader or OpenWriter.
+func isStagingFile(path string) bool {
+	return filepath.Dir(path) == filepath.Clean(os.TempDir()) && strings.HasPrefix(filepath.Base(path), "zoau-")
+}
+
+// IDCAMS commands that only report on datasets and catalog entries.
+var readOnlyIdcamsCommands = map[string]bool{
+	"LISTCAT":  true,
+	"LISTC":    true,
+	"PRINT":    true,
+	"EXAMINE":  true,
+	"PARM":     true,
+	"LISTDATA": true,
+}
+
+// OUTFILE parameter, abbreviated OFILE, that makes PRINT and LISTCAT write to a dataset.
+var idcamsOutfileRegex = regexp.MustCompile(`(?i)\b(OUTFILE|OFILE)\s*\(`)
+
+// idcamsReadOnly reports whether cmd runs IDCAMS with control statements that change nothing.
+func idcamsReadOnly(cmd Command) bool {
+	if !slices.Contains(cmd.Args, "--pgm=IDCAMS") {
+		return false
+	}
+	stdin, ok := cmd.Stdin.(io.ReadSeeker)
+	if !ok {
+		return false
+	}
+	content, err := io.ReadAll(stdin)
+	stdin.Seek(0, io.SeekStart)
+	if err != nil {
+		return false
+	}
+
+	continued := false
+	for _, line := range strings.Split(string(content), "\n") {
+		text := strings.TrimSpace(line)
+		if text == "" {
+			continue
+		}
+		if !continued {
+			if command, _, _ := strings.Cut(text, " "); !readOnlyIdcamsCommands[strings.ToUpper(command)] {
+				return false
+			}
+		}
+		if idcamsOutfileRegex.MatchString(text) {
+			return false
+		}
+		continued = strings.HasSuffix(text, "-") || strings.HasSuffix(text, "+")
+	}
+	return true
+}
@@ -0,0 +1,77 @@
+package zoau_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/Stolkerve/zoau-go"
+	"github.com/Stolkerve/zoau-go/zoautest"
+)
+
+func TestDryRun(t *testing.T) {
+	ctx := context.Background()
+	sim := zoautest.NewSimulator("USER")
+	live := zoau.NewClient(&zoau.ClientArgs{Executor: sim})
+	if err := live.Write(ctx, "USER.PARMS", "A=1", false); err != nil {
+		t.Fatal(err)
+	}
+
+	plan := &zoau.Plan{}
+	dry := zoau.NewClient(&zoau.ClientArgs{Executor: sim, DryRun: plan})
+
+	if ds, err := dry.Create(ctx, "USER.NEW", &zoau.CreateArgs{Type: zoau.String(zoau.DS_ORG_SEQ)}); err != nil || ds.Name != "USER.NEW" {
+		t.Fatalf("expected: USER.NEW, <nil>, got %v, %v", ds, err)
+	}
+	if err := dry.Write(ctx, "USER.PARMS", "it's B=2", true); err != nil {
+		t.Fatal(err)
+	}
+	if deleted, err := dry.Delete(ctx, "USER.PARMS"); err != nil || !deleted {
+		t.Fatalf("expected: true, <nil>, got %v, %v", deleted, err)
+	}
+	if out, rc := dry.Execute(ctx, "IEFBR14", nil, nil, &zoau.Args{}); out != "" || rc != 0 {
+		t.Fatalf("expected: \"\", 0, got %q, %d", out, rc)
+	}
+	if job, err := dry.SubmitJob(ctx, "USER.JCL(BACKUP)", &zoau.SubmitArgs{Wait: true}); err != nil || job == nil || job.Status == nil || *job.Status != "PLANNED" {
+		t.Fatalf("expected: a PLANNED job, <nil>, got %v, %v", job, err)
+	}
+
+	if out, err := dry.Read(ctx, "USER.PARMS", nil); err != nil || out != "A=1" {
+		t.Fatalf("Read-only calls must run, got %q, %v", out, err)
+	}
+	if exist, err := live.Exist(ctx, "USER.NEW"); err != nil || exist {
+		t.Fatalf("The dry run must not create USER.NEW, got %v, %v", exist, err)
+	}
+
+	expected := regexp.MustCompile(`^dtouch -t SEQ USER.NEW
+mvscmd --pgm=IEBGENER --sysprint=dummy --sysin=dummy --sysut1=/\S*/zoau-[0-9]+,filedata=text --sysut2=USER.PARMS,mod
+drm USER.PARMS
+mvscmd --pgm=IEFBR14
+jsub 'USER.JCL\(BACKUP\)'
+$`)
+	if !expected.MatchString(plan.String()) {
+		t.Fatalf("expected: %s, got %s", expected, plan.String())
+	}
+}
+
+func TestDryRunIdcamsOutfile(t *testing.T) {
+	ctx := context.Background()
+	plan := &zoau.Plan{}
+	dry := zoau.NewClient(&zoau.ClientArgs{Executor: zoautest.NewSimulator("USER"), DryRun: plan})
+
+	sysin := func(statements string) []zoau.DDStatement {
+		return []zoau.DDStatement{
+			{Name: "sysprint", Definition: &zoau.ValueDefinition{V: "*"}},
+			{Name: "out", Definition: &zoau.DatasetDefinition{DatasetName: "USER.PRINT.OUT"}},
+			{Name: "sysin", Definition: &zoau.InputDefinition{Content: statements}},
+		}
+	}
+	dry.Execute(ctx, "IDCAMS", nil, sysin(" PRINT INDATASET(USER.LOG) CHARACTER"), nil)
+	if plan.String() != "" {
+		t.Fatalf("PRINT to SYSPRINT must run, got %s", plan.String())
+	}
+	dry.Execute(ctx, "IDCAMS", nil, sysin(" PRINT INDATASET(USER.LOG) -\n   OUTFILE(OUT)"), nil)
+	if plan.String() == "" {
+		t.Fatal("PRINT with OUTFILE must be recorded")
+	}
+}
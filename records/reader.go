@@ -0,0 +1,218 @@
+package records
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Reader reads the records of a dataset image.
+type Reader struct {
+	layout layout
+	r      *bufio.Reader
+
+	// Offset of the next byte of the image, records and blocks read so far.
+	offset int64
+	record int
+	block  int
+
+	// Bytes of the current block not read yet, when the image has block descriptor words.
+	blockLeft int
+}
+
+// NewReader returns a Reader of the records of r in the format f.
+func NewReader(r io.Reader, f Format) (*Reader, error) {
+	l, err := f.parse()
+	if err != nil {
+		return nil, err
+	}
+	return &Reader{layout: l, r: bufio.NewReader(r)}, nil
+}
+
+// Read returns the next record, and io.EOF once all the records are read. A malformed image is
+// reported with a *FormatError wrapping one of the errors of the package.
+func (r *Reader) Read() ([]byte, error) {
+	switch r.layout.kind {
+	case 'F':
+		return r.readFixed()
+	case 'U':
+		return r.readUndefined()
+	}
+	return r.readVariable()
+}
+
+// ReadAll returns the remaining records.
+func (r *Reader) ReadAll() ([][]byte, error) {
+	var records [][]byte
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return records, err
+		}
+		records = append(records, record)
+	}
+}
+
+// errorAt returns a *FormatError about the next record.
+func (r *Reader) errorAt(offset int64, err error) error {
+	return &FormatError{Offset: offset, Record: r.record + 1, Block: r.block, Err: err}
+}
+
+// readFull reads exactly n bytes, returning io.EOF if the image ends before the first of them, and
+// io.ErrUnexpectedEOF if it ends after.
+func (r *Reader) readFull(n int) ([]byte, int, error) {
+	b := make([]byte, n)
+	k, err := io.ReadFull(r.r, b)
+	r.offset += int64(k)
+	return b, k, err
+}
+
+func (r *Reader) readFixed() ([]byte, error) {
+	start := r.offset
+	record, n, err := r.readFull(r.layout.Lrecl)
+	if err == io.ErrUnexpectedEOF {
+		return nil, r.errorAt(start, fmt.Errorf("%w: %d of %d bytes", ErrShortRecord, n, r.layout.Lrecl))
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.record++
+	return record, nil
+}
+
+// readUndefined reads a block, the last one of the image being shorter.
+func (r *Reader) readUndefined() ([]byte, error) {
+	record, n, err := r.readFull(r.layout.maxData())
+	if err == io.ErrUnexpectedEOF {
+		record, err = record[:n], nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.record++
+	return record, nil
+}
+
+func (r *Reader) readVariable() ([]byte, error) {
+	var record []byte
+	start := r.offset
+	spanning := false
+	for {
+		segment, code, at, err := r.readSegment()
+		if err == io.EOF && spanning {
+			return nil, r.errorAt(start, fmt.Errorf("%w: spanned record without its last segment", ErrShortRecord))
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case !spanning && (code == segmentComplete || code == segmentFirst):
+			if code == segmentComplete {
+				r.record++
+				return segment, nil
+			}
+			record = append(record, segment...)
+			spanning = true
+		case spanning && code == segmentMiddle:
+			record = append(record, segment...)
+		case spanning && code == segmentLast:
+			record = append(record, segment...)
+			if max := r.layout.maxData(); max > 0 && len(record) > max {
+				return nil, r.errorAt(start, fmt.Errorf("%w: %d bytes for an LRECL of %d", ErrRecordTooLong, len(record)+descriptorSize, r.layout.Lrecl))
+			}
+			r.record++
+			return record, nil
+		case spanning:
+			return nil, r.errorAt(at, fmt.Errorf("%w: segment code %d within a spanned record", ErrInvalidSegment, code))
+		default:
+			return nil, r.errorAt(at, fmt.Errorf("%w: segment code %d starts a record", ErrInvalidSegment, code))
+		}
+	}
+}
+
+// readSegment reads a record, or a segment of a spanned record, with its segment code and the offset
+// of its RDW.
+func (r *Reader) readSegment() ([]byte, byte, int64, error) {
+	if r.layout.Blocks && r.blockLeft == 0 {
+		if err := r.readBDW(); err != nil {
+			return nil, 0, 0, err
+		}
+	}
+
+	start := r.offset
+	if r.layout.Blocks && r.blockLeft < descriptorSize {
+		return nil, 0, start, r.errorAt(start, fmt.Errorf("%w: %d bytes left in the block for an RDW", ErrInvalidBDW, r.blockLeft))
+	}
+	rdw, n, err := r.readFull(descriptorSize)
+	if err == io.ErrUnexpectedEOF {
+		return nil, 0, start, r.errorAt(start, fmt.Errorf("%w: %d of the %d bytes of an RDW", ErrShortRecord, n, descriptorSize))
+	}
+	if err != nil {
+		return nil, 0, start, err
+	}
+
+	length := int(binary.BigEndian.Uint16(rdw))
+	code := rdw[2]
+	switch {
+	case length < descriptorSize:
+		return nil, 0, start, r.errorAt(start, fmt.Errorf("%w: length %d is shorter than the RDW", ErrInvalidRDW, length))
+	case rdw[3] != 0 || code > segmentMiddle:
+		return nil, 0, start, r.errorAt(start, fmt.Errorf("%w: reserved bytes %02X%02X", ErrInvalidRDW, rdw[2], rdw[3]))
+	case code != segmentComplete && !r.layout.spanned:
+		return nil, 0, start, r.errorAt(start, fmt.Errorf("%w: segment code %d in a record of format %s", ErrInvalidRDW, code, r.layout.Recfm))
+	case !r.layout.spanned && r.layout.Lrecl > 0 && length > r.layout.Lrecl:
+		return nil, 0, start, r.errorAt(start, fmt.Errorf("%w: %d bytes for an LRECL of %d", ErrRecordTooLong, length, r.layout.Lrecl))
+	case r.layout.Blocks && length > r.blockLeft:
+		return nil, 0, start, r.errorAt(start, fmt.Errorf("%w: record of %d bytes in the %d bytes left in the block", ErrInvalidBDW, length, r.blockLeft))
+	}
+
+	data, n, err := r.readFull(length - descriptorSize)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return nil, 0, start, r.errorAt(start, fmt.Errorf("%w: %d of %d bytes", ErrShortRecord, n+descriptorSize, length))
+	}
+	if err != nil {
+		return nil, 0, start, err
+	}
+	if r.layout.Blocks {
+		r.blockLeft -= length
+	}
+	return data, code, start, nil
+}
+
+// readBDW reads the descriptor word of the next block.
+func (r *Reader) readBDW() error {
+	start := r.offset
+	bdw, n, err := r.readFull(descriptorSize)
+	if err == io.ErrUnexpectedEOF {
+		return &FormatError{Offset: start, Record: r.record + 1, Block: r.block + 1,
+			Err: fmt.Errorf("%w: %d of the %d bytes of a BDW", ErrShortRecord, n, descriptorSize)}
+	}
+	if err != nil {
+		return err
+	}
+	r.block++
+
+	var length int
+	if bdw[0]&0x80 != 0 {
+		// The extended BDW of large blocks is a 31 bits length.
+		length = int(binary.BigEndian.Uint32(bdw) &^ (1 << 31))
+	} else {
+		length = int(binary.BigEndian.Uint16(bdw))
+		if bdw[2] != 0 || bdw[3] != 0 {
+			return r.errorAt(start, fmt.Errorf("%w: reserved bytes %02X%02X", ErrInvalidBDW, bdw[2], bdw[3]))
+		}
+	}
+	switch {
+	case length < 2*descriptorSize:
+		return r.errorAt(start, fmt.Errorf("%w: length %d is too short for a BDW and an RDW", ErrInvalidBDW, length))
+	case r.layout.BlockSize > 0 && length > r.layout.BlockSize:
+		return r.errorAt(start, fmt.Errorf("%w: length %d is over the block size %d", ErrInvalidBDW, length, r.layout.BlockSize))
+	}
+	r.blockLeft = length - descriptorSize
+	return nil
+}
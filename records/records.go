@@ -0,0 +1,169 @@
+// Package records reads and writes the records of the binary image of a dataset, as copied with
+// Client.Copy and CopyArgs.Binary: fixed (F, FB), variable (V, VB) with their record descriptor words,
+// spanned (VS, VBS) and undefined (U) records. The block descriptor words of variable records are
+// parsed too when the image holds whole blocks.
+//
+//	r, err := records.NewReader(image, records.FormatOf(dataset))
+//	for {
+//		record, err := r.Read()
+//		...
+//	}
+package records
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Stolkerve/zoau-go"
+)
+
+// Descriptor words are 4 bytes long, as are the RDW of variable records and the BDW of their blocks.
+const descriptorSize = 4
+
+// Longest block or record segment a 2 bytes descriptor word can describe.
+const maxDescriptorLength = 32760
+
+var (
+	// ErrShortRecord means that the image ends within a record, a descriptor word or a spanned record.
+	ErrShortRecord = errors.New("short record")
+
+	// ErrInvalidRDW means that a record descriptor word is malformed.
+	ErrInvalidRDW = errors.New("invalid record descriptor word")
+
+	// ErrInvalidBDW means that a block descriptor word is malformed or disagrees with its records.
+	ErrInvalidBDW = errors.New("invalid block descriptor word")
+
+	// ErrInvalidSegment means that the segments of a spanned record are out of order.
+	ErrInvalidSegment = errors.New("invalid spanned record segment")
+
+	// ErrRecordTooLong means that a record is longer than the LRECL or the block size allows.
+	ErrRecordTooLong = errors.New("record too long")
+)
+
+// FormatError reports a malformed record or block of an image, or a record that cannot be written.
+type FormatError struct {
+	// Offset in the image of the descriptor word or the record at fault.
+	Offset int64
+
+	// Number of the record, from 1.
+	Record int
+
+	// Number of the block, from 1, 0 when the image has no block descriptor words.
+	Block int
+
+	// One of the errors of the package, wrapped with details.
+	Err error
+}
+
+func (e *FormatError) Error() string {
+	if e.Block > 0 {
+		return fmt.Sprintf("records: record %d of block %d at offset %d: %v", e.Record, e.Block, e.Offset, e.Err)
+	}
+	return fmt.Sprintf("records: record %d at offset %d: %v", e.Record, e.Offset, e.Err)
+}
+
+func (e *FormatError) Unwrap() error {
+	return e.Err
+}
+
+// Struct that represents the record format of a dataset image.
+type Format struct {
+	// Record format, as in zoau.Dataset: F, FB, FBS, V, VB, VS, VBS or U. A trailing A or M, for ASA or
+	// machine control characters, is accepted: the control character is kept as the first byte of
+	// every record.
+	Recfm string
+
+	// Logical record length. For variable records, the length of the longest record with its RDW; 0 to
+	// read variable records of any length.
+	Lrecl int
+
+	// Block size. Undefined records are read in blocks of this size, and blocks of variable records are
+	// written up to this size.
+	BlockSize int
+
+	// The image of variable records holds their blocks, each starting with its BDW, as a block level
+	// transfer copies them. Otherwise the image is the sequence of the records with their RDW.
+	Blocks bool
+}
+
+// FormatOf returns the format of the image of ds, without block descriptor words.
+func FormatOf(ds *zoau.Dataset) Format {
+	return Format{Recfm: ds.Recfm, Lrecl: ds.Lrecl, BlockSize: ds.BlockSize}
+}
+
+// layout is a Format once parsed.
+type layout struct {
+	Format
+
+	// 'F', 'V' or 'U'.
+	kind    byte
+	blocked bool
+	spanned bool
+}
+
+func (f Format) parse() (layout, error) {
+	l := layout{Format: f}
+	recfm := strings.ToUpper(strings.TrimSpace(f.Recfm))
+	if strings.HasSuffix(recfm, "A") || strings.HasSuffix(recfm, "M") {
+		recfm = recfm[:len(recfm)-1]
+	}
+	if recfm == "" || strings.IndexByte("FVU", recfm[0]) < 0 {
+		return l, fmt.Errorf("records: unknown record format %q", f.Recfm)
+	}
+	l.kind = recfm[0]
+	for _, c := range recfm[1:] {
+		switch {
+		case c == 'B' && l.kind != 'U' && !l.blocked:
+			l.blocked = true
+		case c == 'S' && l.kind != 'U' && !l.spanned:
+			// Standard blocks of fixed records only tell how they are stored.
+			l.spanned = l.kind == 'V'
+		case c == 'T':
+			// Track overflow is obsolete and does not change the records.
+		default:
+			return l, fmt.Errorf("records: unknown record format %q", f.Recfm)
+		}
+	}
+
+	switch {
+	case f.Lrecl < 0 || f.BlockSize < 0:
+		return l, fmt.Errorf("records: negative LRECL %d or block size %d", f.Lrecl, f.BlockSize)
+	case l.kind == 'F' && f.Lrecl == 0:
+		return l, errors.New("records: fixed records need an LRECL")
+	case l.kind == 'V' && f.Lrecl > 0 && f.Lrecl < descriptorSize+1:
+		return l, fmt.Errorf("records: LRECL %d of variable records is shorter than an RDW and a byte", f.Lrecl)
+	case l.kind == 'U' && f.Lrecl == 0 && f.BlockSize == 0:
+		return l, errors.New("records: undefined records need a block size")
+	case f.Blocks && l.kind != 'V':
+		return l, fmt.Errorf("records: only variable records have block descriptor words, not %s", f.Recfm)
+	case f.Blocks && f.BlockSize > 0 && f.BlockSize < 2*descriptorSize+1:
+		return l, fmt.Errorf("records: block size %d is shorter than a BDW, an RDW and a byte", f.BlockSize)
+	}
+	return l, nil
+}
+
+// maxData returns the longest record data the format allows, 0 when it has no limit.
+func (l layout) maxData() int {
+	switch {
+	case l.kind == 'F':
+		return l.Lrecl
+	case l.kind == 'U' && l.BlockSize > 0:
+		return l.BlockSize
+	case l.kind == 'U':
+		return l.Lrecl
+	case l.Lrecl > 0:
+		return l.Lrecl - descriptorSize
+	case l.spanned:
+		return 0
+	}
+	return maxDescriptorLength - descriptorSize
+}
+
+// Segment control codes of the RDW of a spanned record.
+const (
+	segmentComplete = 0
+	segmentFirst    = 1
+	segmentLast     = 2
+	segmentMiddle   = 3
+)
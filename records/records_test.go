@@ -0,0 +1,249 @@
+package records_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"github.com/Stolkerve/zoau-go"
+	"github.com/Stolkerve/zoau-go/records"
+)
+
+func writeAll(t *testing.T, f records.Format, recs ...string) []byte {
+	t.Helper()
+	var image bytes.Buffer
+	w, err := records.NewWriter(&image, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range recs {
+		if err := w.Write([]byte(r)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	return image.Bytes()
+}
+
+func readAll(t *testing.T, f records.Format, image []byte) ([]string, error) {
+	t.Helper()
+	r, err := records.NewReader(iotest.HalfReader(bytes.NewReader(image)), f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recs, err := r.ReadAll()
+	var out []string
+	for _, r := range recs {
+		out = append(out, string(r))
+	}
+	return out, err
+}
+
+func expectFormatError(t *testing.T, err error, target error, offset int64, record int) {
+	t.Helper()
+	var formatErr *records.FormatError
+	if !errors.As(err, &formatErr) || !errors.Is(err, target) || formatErr.Offset != offset || formatErr.Record != record {
+		t.Fatalf("expected: %v at offset %d of record %d, got %v", target, offset, record, err)
+	}
+}
+
+func TestFixed(t *testing.T) {
+	f := records.FormatOf(&zoau.Dataset{Recfm: "FB", Lrecl: 5, BlockSize: 50})
+	image := writeAll(t, f, "ABC", "DEFGH", "")
+	if string(image) != "ABC\x40\x40DEFGH\x40\x40\x40\x40\x40" {
+		t.Fatalf("Unexpected image %q", image)
+	}
+	recs, err := readAll(t, f, image)
+	if err != nil || len(recs) != 3 || recs[0] != "ABC\x40\x40" || recs[1] != "DEFGH" {
+		t.Fatalf("Unexpected records %q, %v", recs, err)
+	}
+
+	_, err = readAll(t, f, image[:12])
+	expectFormatError(t, err, records.ErrShortRecord, 10, 3)
+
+	var out bytes.Buffer
+	w, _ := records.NewWriter(&out, f)
+	expectFormatError(t, w.Write([]byte("TOOLONG")), records.ErrRecordTooLong, 0, 1)
+	w.Truncate, w.Pad = true, 0
+	if err := w.Write([]byte("TOOLONG")); err != nil || out.String() != "TOOLO" {
+		t.Fatalf("Unexpected truncated record %q, %v", out.String(), err)
+	}
+	if err := w.Write([]byte("A")); err != nil || out.String() != "TOOLOA\x00\x00\x00\x00" {
+		t.Fatalf("Unexpected padded record %q, %v", out.String(), err)
+	}
+}
+
+func TestVariable(t *testing.T) {
+	f := records.Format{Recfm: "VB", Lrecl: 10}
+	image := writeAll(t, f, "AB", "", "CDEFGH")
+	if string(image) != "\x00\x06\x00\x00AB\x00\x04\x00\x00\x00\x0A\x00\x00CDEFGH" {
+		t.Fatalf("Unexpected image %q", image)
+	}
+	recs, err := readAll(t, f, image)
+	if err != nil || len(recs) != 3 || recs[0] != "AB" || recs[1] != "" || recs[2] != "CDEFGH" {
+		t.Fatalf("Unexpected records %q, %v", recs, err)
+	}
+
+	cases := []struct {
+		image  string
+		err    error
+		offset int64
+		record int
+	}{
+		{"\x00\x06\x00\x00AB\x00\x03\x00\x00", records.ErrInvalidRDW, 6, 2},
+		{"\x00\x06\x00\x01AB", records.ErrInvalidRDW, 0, 1},
+		{"\x00\x06\x01\x00AB", records.ErrInvalidRDW, 0, 1},
+		{"\x00\x0B\x00\x00ABCDEFG", records.ErrRecordTooLong, 0, 1},
+		{"\x00\x06\x00\x00AB\x00\x08\x00\x00CD", records.ErrShortRecord, 6, 2},
+		{"\x00\x06\x00\x00AB\x00", records.ErrShortRecord, 6, 2},
+	}
+	for _, tc := range cases {
+		_, err := readAll(t, f, []byte(tc.image))
+		expectFormatError(t, err, tc.err, tc.offset, tc.record)
+	}
+
+	var out bytes.Buffer
+	w, _ := records.NewWriter(&out, f)
+	expectFormatError(t, w.Write([]byte("ABCDEFGHI")), records.ErrRecordTooLong, 0, 1)
+}
+
+func TestBlocks(t *testing.T) {
+	f := records.Format{Recfm: "VB", Lrecl: 10, BlockSize: 20, Blocks: true}
+	image := writeAll(t, f, "AB", "CDEFGH", "IJ")
+	expected := "\x00\x14\x00\x00" + "\x00\x06\x00\x00AB" + "\x00\x0A\x00\x00CDEFGH" +
+		"\x00\x0A\x00\x00" + "\x00\x06\x00\x00IJ"
+	if string(image) != expected {
+		t.Fatalf("Unexpected image %q", image)
+	}
+	recs, err := readAll(t, f, image)
+	if err != nil || len(recs) != 3 || recs[2] != "IJ" {
+		t.Fatalf("Unexpected records %q, %v", recs, err)
+	}
+
+	// Unblocked records have a block each.
+	image = writeAll(t, records.Format{Recfm: "V", Lrecl: 10, Blocks: true}, "AB", "C")
+	if string(image) != "\x00\x0A\x00\x00\x00\x06\x00\x00AB\x00\x09\x00\x00\x00\x05\x00\x00C" {
+		t.Fatalf("Unexpected image %q", image)
+	}
+
+	cases := []struct {
+		image  string
+		err    error
+		offset int64
+		record int
+	}{
+		// The RDW of the second record is past the end of the block.
+		{"\x00\x0C\x00\x00\x00\x06\x00\x00AB\x00\x04", records.ErrInvalidBDW, 10, 2},
+		// The record is longer than what is left of the block.
+		{"\x00\x0C\x00\x00\x00\x09\x00\x00ABCDE", records.ErrInvalidBDW, 4, 1},
+		{"\x00\x0C\x00\x01\x00\x08\x00\x00ABCD", records.ErrInvalidBDW, 0, 1},
+		{"\x00\x04\x00\x00", records.ErrInvalidBDW, 0, 1},
+		{"\x00\x20\x00\x00", records.ErrInvalidBDW, 0, 1},
+		{"\x00\x0A\x00\x00\x00\x06\x00\x00AB\x00\x0A", records.ErrShortRecord, 10, 2},
+	}
+	for _, tc := range cases {
+		_, err := readAll(t, f, []byte(tc.image))
+		expectFormatError(t, err, tc.err, tc.offset, tc.record)
+	}
+
+	var formatErr *records.FormatError
+	if _, err := readAll(t, f, []byte(cases[0].image)); !errors.As(err, &formatErr) || formatErr.Block != 1 {
+		t.Fatalf("expected: an error in block 1, got %v", err)
+	}
+
+	if _, err := records.NewReader(nil, records.Format{Recfm: "FB", Lrecl: 80, Blocks: true}); err == nil {
+		t.Fatal("Fixed records must not have block descriptor words")
+	}
+}
+
+func TestSpanned(t *testing.T) {
+	f := records.Format{Recfm: "VBS", Lrecl: 100, BlockSize: 16, Blocks: true}
+	image := writeAll(t, f, "AB", "CDEFGHIJKLMNOPQRST", "UV")
+	// The long record fills the first block and spans the next ones.
+	expected := "\x00\x10\x00\x00" + "\x00\x06\x00\x00AB" + "\x00\x06\x01\x00CD" +
+		"\x00\x10\x00\x00" + "\x00\x0C\x03\x00EFGHIJKL" +
+		"\x00\x10\x00\x00" + "\x00\x0C\x02\x00MNOPQRST" +
+		"\x00\x0A\x00\x00" + "\x00\x06\x00\x00UV"
+	if string(image) != expected {
+		t.Fatalf("Unexpected image %q", image)
+	}
+	recs, err := readAll(t, f, image)
+	if err != nil || len(recs) != 3 || recs[0] != "AB" || recs[1] != "CDEFGHIJKLMNOPQRST" || recs[2] != "UV" {
+		t.Fatalf("Unexpected records %q, %v", recs, err)
+	}
+
+	// Without blocks, records are spanned only when longer than an RDW describes.
+	long := string(bytes.Repeat([]byte{'X'}, 40000))
+	f = records.Format{Recfm: "VS"}
+	image = writeAll(t, f, long, "Y")
+	if len(image) != 40000+3*4+1 || image[2] != 1 || image[32760+2] != 2 {
+		t.Fatalf("Unexpected image of %d bytes", len(image))
+	}
+	recs, err = readAll(t, f, image)
+	if err != nil || len(recs) != 2 || recs[0] != long || recs[1] != "Y" {
+		t.Fatalf("Unexpected records, %v", err)
+	}
+
+	cases := []struct {
+		image  string
+		err    error
+		offset int64
+		record int
+	}{
+		{"\x00\x06\x02\x00AB", records.ErrInvalidSegment, 0, 1},
+		{"\x00\x06\x01\x00AB\x00\x06\x00\x00CD", records.ErrInvalidSegment, 6, 1},
+		{"\x00\x06\x01\x00AB\x00\x06\x03\x00CD", records.ErrShortRecord, 0, 1},
+	}
+	for _, tc := range cases {
+		_, err := readAll(t, f, []byte(tc.image))
+		expectFormatError(t, err, tc.err, tc.offset, tc.record)
+	}
+
+	_, err = readAll(t, records.Format{Recfm: "VBS", Lrecl: 8}, []byte("\x00\x06\x01\x00AB\x00\x07\x02\x00CDE"))
+	expectFormatError(t, err, records.ErrRecordTooLong, 0, 1)
+}
+
+func TestUndefined(t *testing.T) {
+	f := records.Format{Recfm: "U", BlockSize: 4}
+	image := writeAll(t, f, "ABCD", "EF")
+	recs, err := readAll(t, f, append(image, "GHIJK"...))
+	if err != nil || len(recs) != 3 || recs[0] != "ABCD" || recs[1] != "EFGH" || recs[2] != "IJK" {
+		t.Fatalf("Unexpected records %q, %v", recs, err)
+	}
+
+	r, _ := records.NewReader(bytes.NewReader(nil), f)
+	if _, err := r.Read(); err != io.EOF {
+		t.Fatalf("expected: %v, got %v", io.EOF, err)
+	}
+}
+
+func TestFormats(t *testing.T) {
+	for _, f := range []records.Format{
+		{Recfm: "FBA", Lrecl: 133},
+		{Recfm: "fbs", Lrecl: 80},
+		{Recfm: "VBM", Lrecl: 255},
+		{Recfm: "VBS"},
+		{Recfm: "U", Lrecl: 100},
+	} {
+		if _, err := records.NewReader(nil, f); err != nil {
+			t.Fatalf("%+v: %v", f, err)
+		}
+	}
+	for _, f := range []records.Format{
+		{Recfm: "FB"},
+		{Recfm: "X", Lrecl: 80},
+		{Recfm: "FBB", Lrecl: 80},
+		{Recfm: "UB", BlockSize: 80},
+		{Recfm: "U"},
+		{Recfm: "V", Lrecl: 4},
+		{Recfm: "VB", Lrecl: 80, BlockSize: 8, Blocks: true},
+	} {
+		if _, err := records.NewWriter(nil, f); err == nil {
+			t.Fatalf("%+v must be rejected", f)
+		}
+	}
+}
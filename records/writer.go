@@ -0,0 +1,186 @@
+package records
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Writer writes records as the image of a dataset. Blocks of variable records are kept until full, so
+// Flush must be called once the records are written.
+type Writer struct {
+	// Byte padding fixed records shorter than the LRECL. NewWriter sets it to 0x40, the EBCDIC space;
+	// set it to 0 for binary data.
+	Pad byte
+
+	// Truncate records longer than the LRECL instead of failing with ErrRecordTooLong.
+	Truncate bool
+
+	layout layout
+	w      io.Writer
+
+	// Offset of the next byte of the image, records and blocks written so far.
+	offset int64
+	record int
+	block  int
+
+	// Records, with their RDW, of the block being written, when the image has block descriptor words.
+	pending []byte
+}
+
+// NewWriter returns a Writer of records in the format f to w. Blocks of variable records are written
+// up to the block size of f, or to the longest block a BDW describes if it has none.
+func NewWriter(w io.Writer, f Format) (*Writer, error) {
+	l, err := f.parse()
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{Pad: 0x40, layout: l, w: w}, nil
+}
+
+// errorAt returns a *FormatError about the next record.
+func (w *Writer) errorAt(err error) error {
+	block := 0
+	if w.layout.Blocks {
+		block = w.block + 1
+	}
+	return &FormatError{Offset: w.offset + int64(len(w.pending)), Record: w.record + 1, Block: block, Err: err}
+}
+
+// Write writes a record, padded to the LRECL if the records are fixed.
+func (w *Writer) Write(record []byte) error {
+	if max := w.layout.maxData(); max > 0 && len(record) > max {
+		if !w.Truncate {
+			return w.errorAt(fmt.Errorf("%w: %d bytes for at most %d", ErrRecordTooLong, len(record), max))
+		}
+		record = record[:max]
+	}
+
+	switch w.layout.kind {
+	case 'F':
+		if len(record) < w.layout.Lrecl {
+			padded := make([]byte, w.layout.Lrecl)
+			n := copy(padded, record)
+			for i := n; i < len(padded); i++ {
+				padded[i] = w.Pad
+			}
+			record = padded
+		}
+		if err := w.write(record); err != nil {
+			return err
+		}
+	case 'U':
+		if err := w.write(record); err != nil {
+			return err
+		}
+	default:
+		if err := w.writeVariable(record); err != nil {
+			return err
+		}
+	}
+	w.record++
+	return nil
+}
+
+// Flush writes the block being written, if any.
+func (w *Writer) Flush() error {
+	if len(w.pending) == 0 {
+		return nil
+	}
+	length := len(w.pending) + descriptorSize
+	bdw := make([]byte, descriptorSize)
+	if length > maxDescriptorLength {
+		binary.BigEndian.PutUint32(bdw, uint32(length)|1<<31)
+	} else {
+		binary.BigEndian.PutUint16(bdw, uint16(length))
+	}
+	block := append(bdw, w.pending...)
+	w.pending = w.pending[:0]
+	if err := w.write(block); err != nil {
+		return err
+	}
+	w.block++
+	return nil
+}
+
+func (w *Writer) write(b []byte) error {
+	n, err := w.w.Write(b)
+	w.offset += int64(n)
+	return err
+}
+
+// blockCapacity returns the number of bytes of records a block holds.
+func (w *Writer) blockCapacity() int {
+	if w.layout.BlockSize > 0 {
+		return w.layout.BlockSize - descriptorSize
+	}
+	return maxDescriptorLength - descriptorSize
+}
+
+// writeVariable writes a variable record, split in segments if it is spanned and does not fit the
+// current block, or is longer than an RDW describes.
+func (w *Writer) writeVariable(record []byte) error {
+	room := maxDescriptorLength
+	if w.layout.Blocks {
+		room = w.blockCapacity() - len(w.pending)
+		if len(record)+descriptorSize > room && (!w.layout.spanned || room <= descriptorSize) && len(w.pending) > 0 {
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			room = w.blockCapacity()
+		}
+	}
+	if len(record)+descriptorSize <= room {
+		return w.writeSegment(record, segmentComplete)
+	}
+	if !w.layout.spanned {
+		return w.errorAt(fmt.Errorf("%w: %d bytes with their RDW for blocks of %d", ErrRecordTooLong, len(record)+descriptorSize, room))
+	}
+
+	for first := true; ; first = false {
+		n := room - descriptorSize
+		last := len(record) <= n
+		code := byte(segmentMiddle)
+		switch {
+		case first:
+			code = segmentFirst
+		case last:
+			code = segmentLast
+		}
+		if last {
+			n = len(record)
+		}
+		if err := w.writeSegment(record[:n], code); err != nil {
+			return err
+		}
+		record = record[n:]
+		if last {
+			return nil
+		}
+
+		room = maxDescriptorLength
+		if w.layout.Blocks {
+			if err := w.Flush(); err != nil {
+				return err
+			}
+			room = w.blockCapacity()
+		}
+	}
+}
+
+// writeSegment writes a record or a segment with its RDW, in the current block if the image has block
+// descriptor words. A block of unblocked records holds a single one.
+func (w *Writer) writeSegment(data []byte, code byte) error {
+	rdw := make([]byte, descriptorSize, descriptorSize+len(data))
+	binary.BigEndian.PutUint16(rdw, uint16(len(data)+descriptorSize))
+	rdw[2] = code
+	segment := append(rdw, data...)
+	if !w.layout.Blocks {
+		return w.write(segment)
+	}
+	w.pending = append(w.pending, segment...)
+	if !w.layout.blocked {
+		return w.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package zoau
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"slices"
+	"time"
+)
+
+// RetryPolicy describes how a Client retries the ZOAU commands that fail for a transient reason,
+// such as a dataset held by another job or an allocation that temporarily fails.
+type RetryPolicy struct {
+	// Maximum number of times a command is run, including the first one. Values lower than 2 disable the retries.
+	MaxAttempts int
+
+	// Delay before the first retry. Defaults to 1 second.
+	InitialDelay time.Duration
+
+	// Upper bound of the delay between two attempts. Defaults to 30 seconds.
+	MaxDelay time.Duration
+
+	// Factor applied to the delay after every retry. Defaults to 2.
+	Multiplier float64
+
+	// Fraction of the delay, between 0 and 1, randomly removed from every delay so concurrent
+	// callers do not retry in lockstep. 0 disables the jitter.
+	Jitter float64
+
+	// Return codes considered transient.
+	RetryCodes []int
+
+	// IBM message identifiers (e.g. IEF861I) considered transient.
+	RetryMessages []string
+
+	// Reports whether a failed command can be retried. It is consulted in addition to RetryCodes
+	// and RetryMessages. When all three are empty, IsTransient is used.
+	Retryable func(err *CommandError) bool
+}
+
+// Messages identifiers of allocation failures that usually go away when retried.
+var transientMessages = map[string]bool{
+	"IEF244I":   true, // Unable to allocate, units not available
+	"IKJ56221I": true, // Data set not allocated, volume not available
+	"IGD17103I": true, // Catalog error while defining the data set
+}
+
+// IsTransient reports whether err is a failed command that may succeed if run again: the dataset is
+// in use (ErrInUse) or the allocation failed because a unit or volume was not available.
+func IsTransient(err error) bool {
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) || cmdErr.Err != nil {
+		return false
+	}
+	if errors.Is(cmdErr, ErrInUse) {
+		return true
+	}
+	for _, m := range cmdErr.Messages {
+		if transientMessages[m.Id] {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *RetryPolicy) retryable(err *CommandError) bool {
+	if err.Err != nil {
+		// The command could not be run or ctx is done.
+		return false
+	}
+	if p.Retryable == nil && len(p.RetryCodes) == 0 && len(p.RetryMessages) == 0 {
+		return IsTransient(err)
+	}
+	if p.Retryable != nil && p.Retryable(err) {
+		return true
+	}
+	if slices.Contains(p.RetryCodes, err.Rc) {
+		return true
+	}
+	for _, m := range err.Messages {
+		if slices.Contains(p.RetryMessages, m.Id) {
+			return true
+		}
+	}
+	return false
+}
+
+// delay returns the pause before the given retry, counted from 1.
+func (p *RetryPolicy) delay(retry int) time.Duration {
+	initial := p.InitialDelay
+	if initial <= 0 {
+		initial = time.Second
+	}
+	maxDelay := p.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = 30 * time.Second
+	}
+	multiplier := p.Multiplier
+	if multiplier <= 0 {
+		multiplier = 2
+	}
+
+	d := math.Min(float64(initial)*math.Pow(multiplier, float64(retry-1)), float64(maxDelay))
+	if jitter := math.Min(math.Max(p.Jitter, 0), 1); jitter > 0 {
+		d -= d * jitter * rand.Float64()
+	}
+	return time.Duration(d)
+}
+
+type retryPolicyKey struct{}
+
+// WithRetryPolicy returns a copy of ctx making the Client calls that use it follow policy instead
+// of the RetryPolicy of the Client. A nil policy disables the retries.
+func WithRetryPolicy(ctx context.Context, policy *RetryPolicy) context.Context {
+	return context.WithValue(ctx, retryPolicyKey{}, policy)
+}
+
+func (c *Client) retryPolicy(ctx context.Context) *RetryPolicy {
+	if policy, ok := ctx.Value(retryPolicyKey{}).(*RetryPolicy); ok {
+		return policy
+	}
+	return c.retry
+}
+
+// runWithRetry runs cmd until it succeeds, fails for a reason the retry policy does not consider
+// transient, or the attempts are exhausted. Commands reading a stdin that cannot be rewound are run once.
+func (c *Client) runWithRetry(ctx context.Context, cmd Command) (Result, error) {
+	policy := c.retryPolicy(ctx)
+	seeker, seekable := cmd.Stdin.(io.Seeker)
+	if policy == nil || policy.MaxAttempts < 2 || (cmd.Stdin != nil && !seekable) {
+		return c.run(ctx, cmd, 1)
+	}
+
+	for attempt := 1; ; attempt++ {
+		res, err := c.run(ctx, cmd, attempt)
+		var cmdErr *CommandError
+		if err == nil || !errors.As(err, &cmdErr) || attempt >= policy.MaxAttempts || !policy.retryable(cmdErr) {
+			return res, err
+		}
+		if ctxErr := sleepContext(ctx, policy.delay(attempt)); ctxErr != nil {
+			cmdErr.Err = ctxErr
+			return res, cmdErr
+		}
+		if seekable {
+			if _, seekErr := seeker.Seek(0, io.SeekStart); seekErr != nil {
+				return res, err
+			}
+		}
+	}
+}
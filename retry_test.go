@@ -0,0 +1,71 @@
+package zoau_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Stolkerve/zoau-go"
+)
+
+// sequenceExecutor returns its results in order, then repeats the last one.
+type sequenceExecutor struct {
+	calls   int
+	results []zoau.Result
+}
+
+func (s *sequenceExecutor) Run(ctx context.Context, cmd zoau.Command) (zoau.Result, error) {
+	res := s.results[min(s.calls, len(s.results)-1)]
+	s.calls++
+	return res, nil
+}
+
+func TestRetry(t *testing.T) {
+	inUse := zoau.Result{Rc: 8, Stderr: "IKJ56225I DATA SET USER.PARMS ALREADY IN USE, TRY LATER"}
+	notFound := zoau.Result{Rc: 8, Stderr: "IKJ56228I DATA SET USER.PARMS NOT IN CATALOG OR CATALOG CAN NOT BE ACCESSED"}
+	policy := &zoau.RetryPolicy{MaxAttempts: 3, InitialDelay: time.Millisecond, Jitter: 0.5}
+	ctx := context.Background()
+
+	seq := &sequenceExecutor{results: []zoau.Result{inUse, inUse, {Rc: 0}}}
+	c := zoau.NewClient(&zoau.ClientArgs{Executor: seq, Retry: policy})
+	if err := c.Write(ctx, "USER.PARMS", "A=1", false); err != nil || seq.calls != 3 {
+		t.Fatalf("expected: <nil> after 3 calls, got %v after %d calls", err, seq.calls)
+	}
+
+	seq = &sequenceExecutor{results: []zoau.Result{inUse}}
+	c = zoau.NewClient(&zoau.ClientArgs{Executor: seq, Retry: policy})
+	err := c.Write(ctx, "USER.PARMS", "A=1", false)
+	var cmdErr *zoau.CommandError
+	if !errors.As(err, &cmdErr) || cmdErr.Attempts != 3 || seq.calls != 3 || !errors.Is(err, zoau.ErrInUse) {
+		t.Fatalf("expected: in use error after 3 attempts, got %v after %d calls", err, seq.calls)
+	}
+
+	seq = &sequenceExecutor{results: []zoau.Result{notFound}}
+	c = zoau.NewClient(&zoau.ClientArgs{Executor: seq, Retry: policy})
+	if err := c.Write(ctx, "USER.PARMS", "A=1", false); !errors.Is(err, zoau.ErrNotFound) || seq.calls != 1 {
+		t.Fatalf("Permanent errors must not be retried, got %v after %d calls", err, seq.calls)
+	}
+
+	seq = &sequenceExecutor{results: []zoau.Result{inUse}}
+	c = zoau.NewClient(&zoau.ClientArgs{Executor: seq, Retry: policy})
+	if err := c.Write(zoau.WithRetryPolicy(ctx, nil), "USER.PARMS", "A=1", false); err == nil || seq.calls != 1 {
+		t.Fatalf("A nil policy must disable the retries, got %v after %d calls", err, seq.calls)
+	}
+
+	seq = &sequenceExecutor{results: []zoau.Result{{Rc: 4}, {Rc: 0}}}
+	c = zoau.NewClient(&zoau.ClientArgs{Executor: seq})
+	override := zoau.WithRetryPolicy(ctx, &zoau.RetryPolicy{MaxAttempts: 2, InitialDelay: time.Millisecond, RetryCodes: []int{4}})
+	if err := c.Write(override, "USER.PARMS", "A=1", false); err != nil || seq.calls != 2 {
+		t.Fatalf("expected: <nil> after 2 calls, got %v after %d calls", err, seq.calls)
+	}
+
+	seq = &sequenceExecutor{results: []zoau.Result{inUse}}
+	c = zoau.NewClient(&zoau.ClientArgs{Executor: seq, Retry: &zoau.RetryPolicy{MaxAttempts: 5, InitialDelay: time.Hour}})
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	err = c.Write(timeout, "USER.PARMS", "A=1", false)
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, zoau.ErrInUse) || seq.calls != 1 {
+		t.Fatalf("expected: deadline exceeded after 1 call, got %v after %d calls", err, seq.calls)
+	}
+}
@@ -0,0 +1,134 @@
+package zoau
+
+import (
+	"strconv"
+	"strings"
+)
+
+// quoteBRE escapes the characters special in a POSIX basic regular expression, so that dgrep matches s
+// literally.
+func quoteBRE(s string) string {
+	var out strings.Builder
+	for i := 0; i < len(s); i++ {
+		if strings.IndexByte(`\.[*^$`, s[i]) >= 0 {
+			out.WriteByte('\\')
+		}
+		out.WriteByte(s[i])
+	}
+	return out.String()
+}
+
+// searchLine is a line printed by "dgrep -n -v": the dataset name, the line number and the text,
+// separated by ':' for a matched line and by '-' for a context line.
+type searchLine struct {
+	Name  string
+	Line  uint
+	Text  string
+	Match bool
+}
+
+// parseSearchLines returns the ways raw can be split into a searchLine. Dataset names may contain
+// hyphens, so a context line can be ambiguous.
+func parseSearchLines(raw string) []searchLine {
+	candidates := make([]searchLine, 0, 1)
+	for i := 0; i < len(raw); i++ {
+		sep := raw[i]
+		if sep != ':' && sep != '-' {
+			continue
+		}
+		name := raw[:i]
+		if parsed, err := ParseDatasetName(name); err != nil || parsed.IsPath() || parsed.String() != name {
+			continue
+		}
+		end := i + 1
+		for end < len(raw) && raw[end] >= '0' && raw[end] <= '9' {
+			end++
+		}
+		if end == i+1 || end == len(raw) || raw[end] != sep {
+			continue
+		}
+		line, err := strconv.ParseUint(raw[i+1:end], 10, 0)
+		if err != nil {
+			continue
+		}
+		candidates = append(candidates, searchLine{Name: name, Line: uint(line), Text: raw[end+1:], Match: sep == ':'})
+	}
+	return candidates
+}
+
+// parseSearchOutput parses the output of "dgrep -n -v -C context" into the matched lines, with the
+// context lines around them.
+func parseSearchOutput(stdout string, context uint) *SearchResult {
+	result := &SearchResult{Matches: make([]SearchMatch, 0)}
+	texts := make(map[string]map[uint]string)
+
+	groups := make([][][]searchLine, 0)
+	group := make([][]searchLine, 0)
+	for _, raw := range strings.Split(strings.TrimSuffix(stdout, "\n"), "\n") {
+		if raw == "--" {
+			groups, group = append(groups, group), make([][]searchLine, 0)
+			continue
+		}
+		if candidates := parseSearchLines(raw); len(candidates) > 0 {
+			group = append(group, candidates)
+		}
+	}
+	groups = append(groups, group)
+
+	for _, group := range groups {
+		// A group of lines holds the matches of some sources and their context lines: the names of the
+		// sources are those of the matched lines, and the lines of a source follow each other.
+		names := make(map[string]bool)
+		for _, candidates := range group {
+			for _, c := range candidates {
+				if c.Match {
+					names[c.Name] = true
+				}
+			}
+		}
+		var previous *searchLine
+		for _, candidates := range group {
+			chosen := -1
+			for i, c := range candidates {
+				switch {
+				case !names[c.Name]:
+				case previous != nil && c.Name == previous.Name && c.Line == previous.Line+1:
+					chosen = i
+				case chosen < 0:
+					chosen = i
+				}
+			}
+			line := candidates[max(chosen, 0)]
+			previous = &line
+
+			if texts[line.Name] == nil {
+				texts[line.Name] = make(map[uint]string)
+			}
+			texts[line.Name][line.Line] = line.Text
+			if line.Match {
+				dataset, member := splitMemberReference(line.Name)
+				result.Matches = append(result.Matches, SearchMatch{Dataset: dataset, Member: member, Line: line.Line, Text: line.Text})
+			}
+		}
+	}
+
+	for i := range result.Matches {
+		m := &result.Matches[i]
+		lines := texts[m.Dataset]
+		if m.Member != "" {
+			lines = texts[m.Dataset+"("+m.Member+")"]
+		}
+		for n := m.Line - min(context, m.Line-1); n < m.Line; n++ {
+			if text, ok := lines[n]; ok {
+				m.Before = append(m.Before, text)
+			}
+		}
+		for n := m.Line + 1; n <= m.Line+context; n++ {
+			if text, ok := lines[n]; ok {
+				m.After = append(m.After, text)
+			}
+		}
+	}
+	result.Count = uint(len(result.Matches))
+	return result
+}
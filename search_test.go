@@ -0,0 +1,91 @@
+package zoau_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/Stolkerve/zoau-go"
+	"github.com/Stolkerve/zoau-go/zoautest"
+)
+
+func TestSearch(t *testing.T) {
+	ctx := context.Background()
+	c := zoau.NewClient(&zoau.ClientArgs{Executor: zoautest.NewSimulator("USER")})
+
+	if _, err := c.Create(ctx, "USER.SRC", &zoau.CreateArgs{Type: zoau.String(zoau.DS_ORG_PDSE)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Create(ctx, "USER.LOG-1", nil); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"USER.SRC(PROG1)": "MOVE A TO B.\nCALL 'SUB1'.\nGOBACK.",
+		"USER.SRC(PROG2)": "DISPLAY 'X'.\nGOBACK.",
+		"USER.LOG-1":      "1-START\n2-A.B\n3-AXB\n4-END",
+	} {
+		if err := c.Write(ctx, name, content, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	res, err := c.Search(ctx, "USER.SRC", "GOBACK", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []zoau.SearchMatch{
+		{Dataset: "USER.SRC", Member: "PROG1", Line: 3, Text: "GOBACK."},
+		{Dataset: "USER.SRC", Member: "PROG2", Line: 2, Text: "GOBACK."},
+	}
+	if res.Count != 2 || !reflect.DeepEqual(res.Matches, expected) {
+		t.Fatalf("expected: %+v, got %+v", expected, res)
+	}
+
+	// Context lines of the hyphenated dataset name are told apart from matched lines.
+	res, err = c.Search(ctx, "USER.SRC(PROG1)", "call", &zoau.SearchArgs{IgnoreCase: true, Lines: zoau.Uint(1), Concatenation: []string{"USER.LOG-1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = []zoau.SearchMatch{
+		{Dataset: "USER.SRC", Member: "PROG1", Line: 2, Text: "CALL 'SUB1'.", Before: []string{"MOVE A TO B."}, After: []string{"GOBACK."}},
+	}
+	if res.Count != 1 || !reflect.DeepEqual(res.Matches, expected) {
+		t.Fatalf("expected: %+v, got %+v", expected, res)
+	}
+
+	res, err = c.Search(ctx, "USER.LOG-1", "A.B", &zoau.SearchArgs{Lines: zoau.Uint(2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = []zoau.SearchMatch{
+		{Dataset: "USER.LOG-1", Line: 2, Text: "2-A.B", Before: []string{"1-START"}, After: []string{"3-AXB", "4-END"}},
+		{Dataset: "USER.LOG-1", Line: 3, Text: "3-AXB", Before: []string{"1-START", "2-A.B"}, After: []string{"4-END"}},
+	}
+	if res.Count != 2 || !reflect.DeepEqual(res.Matches, expected) {
+		t.Fatalf("expected: %+v, got %+v", expected, res)
+	}
+
+	res, err = c.Search(ctx, "USER.LOG-1", "A.B", &zoau.SearchArgs{Literal: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Count != 1 || res.Matches[0].Line != 2 {
+		t.Fatalf("expected: a literal match on line 2, got %+v", res)
+	}
+
+	res, err = c.Search(ctx, "USER.**", "[.]", &zoau.SearchArgs{CountLines: true, Lines: zoau.Uint(3)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Count != 6 || res.Matches != nil {
+		t.Fatalf("expected: 6 lines counted, got %+v", res)
+	}
+
+	res, err = c.Search(ctx, "USER.SRC", "NOTFOUND", nil)
+	if err != nil || res.Count != 0 || len(res.Matches) != 0 {
+		t.Fatalf("expected: no match, got %+v, %v", res, err)
+	}
+	if _, err := c.Search(ctx, "USER.MISSING", "X", nil); err == nil {
+		t.Fatal("Searching a missing dataset must fail")
+	}
+}
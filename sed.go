@@ -0,0 +1,252 @@
+package zoau
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Delimiters tried, in order, for the regular expressions of a sed expression. None is special in a
+// basic regular expression, so that an escaped delimiter always stands for itself.
+const sedDelimiters = "/|#%@,;:!~_"
+
+// sedBuilder builds the expressions of the dsed and dmod scripts. User values are never interpolated
+// as they are: regular expressions have their delimiter and newlines escaped, literals have every
+// character special to sed escaped as well, and the text of the a, i and c commands has its
+// backslashes and newlines escaped.
+type sedBuilder struct {
+	delim byte
+}
+
+// newSedBuilder returns a builder whose delimiter appears in none of the values, or '/' escaped.
+func newSedBuilder(values ...string) sedBuilder {
+	for i := 0; i < len(sedDelimiters); i++ {
+		used := false
+		for _, v := range values {
+			if strings.IndexByte(v, sedDelimiters[i]) >= 0 {
+				used = true
+				break
+			}
+		}
+		if !used {
+			return sedBuilder{delim: sedDelimiters[i]}
+		}
+	}
+	return sedBuilder{delim: '/'}
+}
+
+// optionalValues returns the values that are set.
+func optionalValues(values ...*string) []string {
+	set := make([]string, 0, len(values))
+	for _, v := range values {
+		if v != nil {
+			set = append(set, *v)
+		}
+	}
+	return set
+}
+
+// regex escapes the delimiter and the newlines of a basic regular expression.
+func (b sedBuilder) regex(re string) string {
+	var out strings.Builder
+	for i := 0; i < len(re); i++ {
+		switch c := re[i]; {
+		case c == '\\' && i+1 < len(re):
+			// An escape is kept as is, but a newline can only be written \n.
+			i++
+			out.WriteByte('\\')
+			if re[i] == '\n' {
+				out.WriteByte('n')
+			} else {
+				out.WriteByte(re[i])
+			}
+		case c == '\\':
+			// A trailing backslash would escape the delimiter closing the expression.
+			out.WriteString(`\\`)
+		case c == '\n':
+			out.WriteString(`\n`)
+		case c == b.delim:
+			out.WriteByte('\\')
+			out.WriteByte(c)
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return out.String()
+}
+
+// literal returns the basic regular expression matching s.
+func (b sedBuilder) literal(s string) string {
+	return b.regex(quoteBRE(s))
+}
+
+// address returns the address selecting the lines matching re: /re/, or \%re% with another delimiter.
+func (b sedBuilder) address(re string) string {
+	if b.delim == '/' {
+		return "/" + b.regex(re) + "/"
+	}
+	return `\` + string(b.delim) + b.regex(re) + string(b.delim)
+}
+
+// lineAddress returns the address selecting the lines equal to line.
+func (b sedBuilder) lineAddress(line string) string {
+	return b.address("^" + quoteBRE(line) + "$")
+}
+
+// substitute returns the s command replacing find by replace on every line. With literal, find is
+// matched as is and replace inserted as is; otherwise find is a basic regular expression and replace
+// may refer to the match with & and to its groups with \1 to \9.
+func (b sedBuilder) substitute(find string, replace string, literal bool, flags string) (string, error) {
+	if find == "" {
+		return "", errors.New("The string to find must not be empty")
+	}
+	var out strings.Builder
+	out.WriteByte('s')
+	out.WriteByte(b.delim)
+	if literal {
+		out.WriteString(b.literal(find))
+	} else {
+		out.WriteString(b.regex(find))
+	}
+	out.WriteByte(b.delim)
+	for i := 0; i < len(replace); i++ {
+		switch c := replace[i]; {
+		case c == '\\' && !literal && i+1 < len(replace) && replace[i+1] != '\n':
+			i++
+			out.WriteByte('\\')
+			out.WriteByte(replace[i])
+		case c == '\\' || (c == '&' && literal) || c == b.delim:
+			out.WriteByte('\\')
+			out.WriteByte(c)
+		case c == '\n':
+			out.WriteString(`\n`)
+		default:
+			out.WriteByte(c)
+		}
+	}
+	out.WriteByte(b.delim)
+	out.WriteString(flags)
+	return out.String(), nil
+}
+
+// text returns the a, i or c command adding text after, before or instead of the lines selected by
+// address. text may have several lines. occurrence is the ZOAU extension selecting the first ("1") or
+// last ("$") line matching a regular expression address, empty for every line.
+func (b sedBuilder) text(address string, cmd byte, text string, occurrence string) string {
+	var out strings.Builder
+	out.WriteString(address)
+	if !strings.HasSuffix(address, string(b.delim)) {
+		out.WriteByte(' ')
+	}
+	out.WriteByte(cmd)
+	out.WriteByte('\\')
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; c {
+		case '\\':
+			out.WriteString(`\\`)
+		case '\n':
+			out.WriteString(`\n`)
+		default:
+			out.WriteByte(c)
+		}
+	}
+	if occurrence != "" {
+		out.WriteString("/" + occurrence)
+	}
+	return out.String()
+}
+
+// delete returns the d command deleting the lines selected by address.
+func (b sedBuilder) delete(address string) string {
+	return address + "d"
+}
+
+// CompileBRE compiles a POSIX basic regular expression, as dsed and dgrep read it, with the GNU \+, \?
+// and \| extensions. Back-references are not supported.
+func CompileBRE(bre string, ignoreCase bool) (*regexp.Regexp, error) {
+	var out strings.Builder
+	if ignoreCase {
+		out.WriteString("(?i)")
+	}
+	atStart := true
+	for i := 0; i < len(bre); i++ {
+		c := bre[i]
+		start := atStart
+		atStart = false
+		switch {
+		case c == '\\':
+			if i+1 >= len(bre) {
+				return nil, fmt.Errorf("trailing backslash in %q", bre)
+			}
+			i++
+			switch n := bre[i]; n {
+			case '(':
+				out.WriteByte('(')
+				atStart = true
+			case ')', '{', '}', '|', '+', '?':
+				out.WriteByte(n)
+				atStart = n == '|'
+			case 'n':
+				out.WriteString(`\n`)
+			case 't':
+				out.WriteString(`\t`)
+			case 'w', 'W', 's', 'S', 'b', 'B':
+				out.WriteByte('\\')
+				out.WriteByte(n)
+			case '<', '>':
+				out.WriteString(`\b`)
+			default:
+				if n >= '1' && n <= '9' {
+					return nil, fmt.Errorf("back-references are not supported: %q", bre)
+				}
+				out.WriteString(regexp.QuoteMeta(string(n)))
+			}
+		case c == '[':
+			j := i + 1
+			if j < len(bre) && bre[j] == '^' {
+				j++
+			}
+			if j < len(bre) && bre[j] == ']' {
+				j++
+			}
+			for j < len(bre) && bre[j] != ']' {
+				if bre[j] == '[' && j+1 < len(bre) && strings.IndexByte(":.=", bre[j+1]) >= 0 {
+					end := strings.Index(bre[j+2:], string(bre[j+1])+"]")
+					if end < 0 {
+						return nil, fmt.Errorf("unterminated character class in %q", bre)
+					}
+					j += end + 4
+					continue
+				}
+				j++
+			}
+			if j >= len(bre) {
+				return nil, fmt.Errorf("unterminated bracket expression in %q", bre)
+			}
+			out.WriteString(strings.ReplaceAll(bre[i:j+1], `\`, `\\`))
+			i = j
+		case c == '*' && start:
+			out.WriteString(`\*`)
+		case c == '^':
+			if start {
+				out.WriteByte('^')
+				atStart = true
+			} else {
+				out.WriteString(`\^`)
+			}
+		case c == '$':
+			if i == len(bre)-1 || strings.HasPrefix(bre[i+1:], `\)`) || strings.HasPrefix(bre[i+1:], `\|`) {
+				out.WriteByte('$')
+			} else {
+				out.WriteString(`\$`)
+			}
+		case strings.IndexByte("+?(){}|", c) >= 0:
+			out.WriteByte('\\')
+			out.WriteByte(c)
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return regexp.Compile(out.String())
+}
@@ -0,0 +1,335 @@
+package zoau_test
+
+import (
+	"context"
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"github.com/Stolkerve/zoau-go"
+	"github.com/Stolkerve/zoau-go/zoautest"
+)
+
+// sedText is a string made of the characters that sed, regular expressions and shells treat specially.
+type sedText string
+
+const sedAlphabet = `ab /\&.*[]^$|#%@,;:!~_-"'(){}+?1n`
+
+func (sedText) Generate(r *rand.Rand, size int) reflect.Value {
+	b := make([]byte, 1+r.Intn(min(size, 12)))
+	for i := range b {
+		b[i] = sedAlphabet[r.Intn(len(sedAlphabet))]
+	}
+	return reflect.ValueOf(sedText(b))
+}
+
+func newSedDataset(t *testing.T, content string) (*zoau.Client, string) {
+	t.Helper()
+	ctx := context.Background()
+	c := zoau.NewClient(&zoau.ClientArgs{Executor: zoautest.NewSimulator("USER")})
+	if _, err := c.Create(ctx, "USER.SED", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Write(ctx, "USER.SED", content, false); err != nil {
+		t.Fatal(err)
+	}
+	return c, "USER.SED"
+}
+
+// hostSed runs the expressions of the dsed command args over content with the sed of the host in POSIX
+// mode, the reference the expressions are checked against, and returns the result. As dsed with -s,
+// it keeps the result of the first expression that changes the content. The test is skipped without
+// a POSIX sed.
+func hostSed(t *testing.T, content string, args []string) string {
+	t.Helper()
+	if err := exec.Command("sed", "--posix", "-e", "p", "/dev/null").Run(); err != nil {
+		t.Skipf("No reference sed on the host: %v", err)
+	}
+	stop := false
+	expressions := make([]string, 0)
+	for i := 0; i < len(args)-1; i++ {
+		switch args[i] {
+		case "-s":
+			stop = true
+		case "-e":
+			i++
+			expressions = append(expressions, args[i])
+		default:
+			expressions = append(expressions, args[i])
+		}
+	}
+
+	file := filepath.Join(t.TempDir(), "content")
+	if err := os.WriteFile(file, []byte(content+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	script := filepath.Join(t.TempDir(), "script")
+	run := func(expressions ...string) string {
+		lines := ""
+		for _, e := range expressions {
+			lines += posixSedLine(e)
+		}
+		if err := os.WriteFile(script, []byte(lines), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		out, err := exec.Command("sed", "--posix", "-f", script, file).Output()
+		if err != nil {
+			t.Fatalf("sed %q: %v", lines, err)
+		}
+		return strings.TrimSuffix(string(out), "\n")
+	}
+	if !stop {
+		return run(expressions...)
+	}
+	for _, e := range expressions {
+		if out := run(e); out != content {
+			return out
+		}
+	}
+	return content
+}
+
+// posixSedLine returns the script line of the dsed expression e. The one-line a, i and c commands
+// of dsed are written in the POSIX form: the text follows "a\" on its own line, and its escaped
+// newlines are continuation lines. As the occurrence suffix is dropped, a regular expression address
+// must select a single line.
+func posixSedLine(e string) string {
+	end := 0
+	regex := e[0] == '/' || e[0] == '\\'
+	if regex {
+		delim, i := e[0], 1
+		if delim == '\\' {
+			delim, i = e[1], 2
+		}
+		for ; i < len(e) && e[i] != delim; i++ {
+			if e[i] == '\\' {
+				i++
+			}
+		}
+		end = i + 1
+	} else {
+		end = strings.IndexFunc(e, func(r rune) bool { return r != '$' && (r < '0' || r > '9') })
+	}
+	if end < 0 || end >= len(e) {
+		return e + "\n"
+	}
+	address, cmd := e[:end], strings.TrimPrefix(e[end:], " ")
+	if len(cmd) < 2 || strings.IndexByte("aic", cmd[0]) < 0 || cmd[1] != '\\' {
+		return e + "\n"
+	}
+	text := cmd[2:]
+	if regex {
+		// The ZOAU occurrence suffix, "/1" or "/$", is dropped.
+		text = text[:len(text)-2]
+	}
+	var out strings.Builder
+	out.WriteString(address + cmd[:2] + "\n")
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\\' && i+1 < len(text) {
+			i++
+			if text[i] == 'n' {
+				out.WriteString("\\\n")
+				continue
+			}
+			out.WriteByte('\\')
+		}
+		out.WriteByte(text[i])
+	}
+	return out.String() + "\n"
+}
+
+func TestFindReplaceLiteralProperty(t *testing.T) {
+	ctx := context.Background()
+	property := func(line1, line2, find, replace sedText) bool {
+		content := string(line1+find+line2) + "\n" + string(find+find+line1)
+		c, commands := dsedClient(t, content)
+		if _, err := c.FindReplace(ctx, "USER.SED", string(find), string(replace), &zoau.FindReplaceArgs{Literal: true}); err != nil {
+			t.Log(err)
+			return false
+		}
+		if len(*commands) != 1 {
+			t.Logf("find %q replace %q: expected one dsed command, got %q", find, replace, *commands)
+			return false
+		}
+		expected := strings.ReplaceAll(content, string(find), string(replace))
+		if out := hostSed(t, content, (*commands)[0]); out != expected {
+			t.Logf("find %q replace %q: %q gives %q, expected %q", find, replace, (*commands)[0], out, expected)
+			return false
+		}
+		return true
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLineInFileTextProperty(t *testing.T) {
+	ctx := context.Background()
+	property := func(line sedText) bool {
+		c, commands := dsedClient(t, "FIRST\nLAST")
+		// The regular expression is quoted, so it only matches the line added at the end.
+		address := "^" + strings.NewReplacer(`\`, `\\`, `.`, `\.`, `[`, `\[`, `*`, `\*`, `^`, `\^`, `$`, `\$`).Replace(string(line)) + "$"
+		if _, err := c.LineInFile(ctx, "USER.SED", string(line), &zoau.LineInFileArgs{Regex: zoau.String(address), InsAft: zoau.String("EOF")}); err != nil {
+			t.Log(err)
+			return false
+		}
+		added := "FIRST\nLAST\n" + string(line)
+		if len(*commands) != 1 {
+			t.Logf("line %q: expected one dsed command, got %q", line, *commands)
+			return false
+		}
+		if out := hostSed(t, "FIRST\nLAST", (*commands)[0]); out != added {
+			t.Logf("line %q: %q gives %q, expected %q", line, (*commands)[0], out, added)
+			return false
+		}
+
+		if _, err := c.LineInFile(ctx, "USER.SED", string(line), &zoau.LineInFileArgs{State: zoau.Bool(false)}); err != nil {
+			t.Log(err)
+			return false
+		}
+		if len(*commands) != 2 {
+			t.Logf("line %q: expected a second dsed command, got %q", line, *commands)
+			return false
+		}
+		if out := hostSed(t, added, (*commands)[1]); out != "FIRST\nLAST" {
+			t.Logf("line %q: %q gives %q, expected it removed", line, (*commands)[1], out)
+			return false
+		}
+		return true
+	}
+	if err := quick.Check(property, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFindReplaceRegex(t *testing.T) {
+	ctx := context.Background()
+	c, ds := newSedDataset(t, "/usr/lpp/zoau\nA & B\nkey=value")
+
+	cases := []struct {
+		find, replace string
+		expected      string
+	}{
+		{"/usr/lpp", "/opt", "/opt/zoau\nA & B\nkey=value"},
+		{`\(key\)=\(.*\)`, `\2=\1`, "/opt/zoau\nA & B\nvalue=key"},
+		{"&", "and", "/opt/zoau\nA and B\nvalue=key"},
+		{"A", "[&]", "/opt/zoau\n[A] and B\nvalue=key"},
+		{"B$", `\\`, "/opt/zoau\n[A] and \\\nvalue=key"},
+		{"^/opt/", "\"'|#%@,;:!~_", "\"'|#%@,;:!~_zoau\n[A] and \\\nvalue=key"},
+	}
+	for _, tc := range cases {
+		if _, err := c.FindReplace(ctx, ds, tc.find, tc.replace, nil); err != nil {
+			t.Fatal(err)
+		}
+		if out, err := c.Read(ctx, ds, nil); err != nil || out != tc.expected {
+			t.Fatalf("find %q replace %q: expected %q, got %q, %v", tc.find, tc.replace, tc.expected, out, err)
+		}
+	}
+
+	if _, err := c.FindReplace(ctx, ds, "", "X", nil); err == nil {
+		t.Fatal("An empty string to find must be rejected")
+	}
+}
+
+func TestFindReplaceBackReference(t *testing.T) {
+	ctx := context.Background()
+	c := zoau.NewClient(&zoau.ClientArgs{Executor: hostSedExecutor(t, "USER.X", "AAB\nAB")})
+	if _, err := c.FindReplace(ctx, "USER.X", `\(A\)\1`, "X", nil); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := c.Read(ctx, "USER.X", nil); err != nil || out != "XB\nAB" {
+		t.Fatalf("expected: \"XB\\nAB\", got %q, %v", out, err)
+	}
+}
+
+func TestLineInFileMultiLine(t *testing.T) {
+	ctx := context.Background()
+	c, ds := newSedDataset(t, "HEADER\n/* END */\nTRAILER")
+
+	if _, err := c.LineInFile(ctx, ds, "LINE 1\nLINE \\2", &zoau.LineInFileArgs{InsBef: zoau.String(`^/\* END`)}); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := c.Read(ctx, ds, nil); err != nil || out != "HEADER\nLINE 1\nLINE \\2\n/* END */\nTRAILER" {
+		t.Fatalf("Unexpected content %q, %v", out, err)
+	}
+
+	if _, err := c.LineInFile(ctx, ds, "/* END */", &zoau.LineInFileArgs{State: zoau.Bool(false)}); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := c.Read(ctx, ds, nil); err != nil || out != "HEADER\nLINE 1\nLINE \\2\nTRAILER" {
+		t.Fatalf("Unexpected content %q, %v", out, err)
+	}
+}
+
+// dsedClient returns a Client over a simulated dataset USER.SED holding content, and the arguments of
+// the dsed commands it runs.
+func dsedClient(t *testing.T, content string) (*zoau.Client, *[][]string) {
+	t.Helper()
+	ctx := context.Background()
+	commands := &[][]string{}
+	c := zoau.NewClient(&zoau.ClientArgs{
+		Executor: zoautest.NewSimulator("USER"),
+		Middlewares: []zoau.Middleware{zoau.MiddlewareFuncs{BeforeFunc: func(ctx context.Context, cmd zoau.Command) context.Context {
+			if cmd.Name == "dsed" {
+				*commands = append(*commands, cmd.Args)
+			}
+			return ctx
+		}}},
+	})
+	if err := c.Write(ctx, "USER.SED", content, false); err != nil {
+		t.Fatal(err)
+	}
+	return c, commands
+}
+
+func TestSedExpressions(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name     string
+		edit     func(c *zoau.Client) error
+		expected []string
+	}{
+		{"literal", func(c *zoau.Client) error {
+			_, err := c.FindReplace(ctx, "USER.SED", "a/b", `c&d`, &zoau.FindReplaceArgs{Literal: true})
+			return err
+		}, []string{`s|a/b|c\&d|g`, "USER.SED"}},
+		{"literal special", func(c *zoau.Client) error {
+			_, err := c.FindReplace(ctx, "USER.SED", "a.*[b]", `\1`, &zoau.FindReplaceArgs{Literal: true})
+			return err
+		}, []string{`s/a\.\*\[b]/\\1/g`, "USER.SED"}},
+		{"regex", func(c *zoau.Client) error {
+			_, err := c.FindReplace(ctx, "USER.SED", `\(a\)/`, `\1|`, nil)
+			return err
+		}, []string{`s#\(a\)/#\1|#g`, "USER.SED"}},
+		{"address", func(c *zoau.Client) error {
+			_, err := c.LineInFile(ctx, "USER.SED", "x=1", &zoau.LineInFileArgs{Regex: zoau.String("^/a")})
+			return err
+		}, []string{"-s", "-e", `\|^/a|c\x=1/$`, "-e", `$ a\x=1`, "USER.SED"}},
+		{"present", func(c *zoau.Client) error {
+			_, err := c.LineInFile(ctx, "USER.SED", "a/b", nil)
+			return err
+		}, []string{`/^a\/b$/c\a/b/1`, "USER.SED"}},
+		{"text", func(c *zoau.Client) error {
+			_, err := c.LineInFile(ctx, "USER.SED", "a\\b\nc ", &zoau.LineInFileArgs{InsBef: zoau.String("BOF")})
+			return err
+		}, []string{`1 i\a\\b\nc `, "USER.SED"}},
+		{"delete", func(c *zoau.Client) error {
+			_, err := c.LineInFile(ctx, "USER.SED", "a/b", &zoau.LineInFileArgs{State: zoau.Bool(false)})
+			return err
+		}, []string{`/^a\/b$/d`, "USER.SED"}},
+	}
+	for _, test := range tests {
+		c, commands := dsedClient(t, "a/b\na.*[b]")
+		if err := test.edit(c); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if len(*commands) != 1 || !reflect.DeepEqual((*commands)[0], test.expected) {
+			t.Fatalf("%s: expected: %q, got %q", test.name, test.expected, *commands)
+		}
+	}
+}
@@ -0,0 +1,328 @@
+package zoau
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Stolkerve/zoau-go/codepage"
+)
+
+// OpenReader runs Client.OpenReader on the default client.
+func OpenReader(dataset string, args *ReaderArgs) (io.ReadCloser, error) {
+	return DefaultClient().OpenReader(context.Background(), dataset, args)
+}
+
+// Open a dataset, member or HFS file for reading. The contents are streamed from dtail as text, one
+// record per line, so large datasets are read with bounded memory when the executor implements
+// StreamExecutor. An error of the utility is returned by Read in place of io.EOF.
+// The reader must be closed, which stops the utility if the contents were not read until the end.
+// With args.Encoding, the dataset is copied with dcp to a temporary HFS file instead, and its bytes
+// are converted from the code page as they are read.
+func (c *Client) OpenReader(ctx context.Context, dataset string, args *ReaderArgs) (io.ReadCloser, error) {
+	if err := validateNames(dataset); err != nil {
+		return nil, err
+	}
+	if args != nil && args.Encoding != nil {
+		return c.openEncodedReader(ctx, dataset, args.Encoding)
+	}
+	return c.stream(ctx, "dtail", []string{"-n", "+1", dataset})
+}
+
+func (c *Client) openEncodedReader(ctx context.Context, dataset string, encoding codepage.Encoding) (io.ReadCloser, error) {
+	file, err := os.CreateTemp("", "zoau-*")
+	if err != nil {
+		return nil, err
+	}
+	r := &fileReader{file: file}
+	if _, _, err := c.execZaouCmd(ctx, "dcp", []string{"-B", dataset, file.Name()}); err != nil {
+		r.Close()
+		return nil, err
+	}
+	r.Reader = encoding.NewDecoder().Reader(file)
+	return r, nil
+}
+
+// fileReader reads a temporary HFS file, removed by Close.
+type fileReader struct {
+	io.Reader
+	file *os.File
+}
+
+func (r *fileReader) Close() error {
+	defer os.Remove(r.file.Name())
+	return r.file.Close()
+}
+
+// stream starts a command through the middlewares and returns a reader of its standard output.
+// Unlike execZaouCmd, the command is not retried.
+func (c *Client) stream(ctx context.Context, proc string, params []string) (io.ReadCloser, error) {
+	cmd := Command{Name: proc, Args: params, Env: c.env}
+	ctx = c.before(ctx, cmd)
+	runCtx, cancel := c.withTimeout(ctx)
+	start := time.Now()
+
+	executor, ok := c.executor.(StreamExecutor)
+	if !ok {
+		defer cancel()
+		res, err := c.executor.Run(runCtx, cmd)
+		if res, err = c.after(ctx, cmd, 1, start, res, err); err != nil {
+			return nil, err
+		}
+		return io.NopCloser(strings.NewReader(res.Stdout)), nil
+	}
+
+	stream, err := executor.Start(runCtx, cmd)
+	if err != nil {
+		cancel()
+		_, err = c.after(ctx, cmd, 1, start, Result{}, err)
+		return nil, err
+	}
+	return &commandReader{client: c, ctx: ctx, cmd: cmd, start: start, cancel: cancel, stream: stream}, nil
+}
+
+// commandReader reads the output of a command started by a StreamExecutor.
+type commandReader struct {
+	client *Client
+	ctx    context.Context
+	cmd    Command
+	start  time.Time
+	cancel context.CancelFunc
+	stream Stream
+	done   bool
+	err    error
+}
+
+func (r *commandReader) Read(p []byte) (int, error) {
+	if r.done {
+		if r.err != nil {
+			return 0, r.err
+		}
+		return 0, io.EOF
+	}
+
+	n, err := r.stream.Read(p)
+	if err == nil {
+		return n, nil
+	}
+	r.wait(false)
+	if r.err != nil {
+		return n, r.err
+	}
+	return n, err
+}
+
+// Close stops the command if its output was not read until the end. The middlewares see the stopped
+// command as a success, since the caller chose to stop it, but a command that failed on its own before
+// is reported, and its error returned.
+func (r *commandReader) Close() error {
+	if r.done {
+		return nil
+	}
+	r.cancel()
+	r.wait(true)
+	return r.err
+}
+
+// wait waits for the end of the command and reports it to the middlewares. A command stopped by Close
+// is reported with rc 0 and no error.
+func (r *commandReader) wait(closed bool) {
+	res, err := r.stream.Wait()
+	r.cancel()
+	if closed && errors.Is(err, context.Canceled) && r.ctx.Err() == nil {
+		res.Rc, err = 0, nil
+	}
+	_, r.err = r.client.after(r.ctx, r.cmd, 1, r.start, res, err)
+	r.done = true
+}
+
+// Longest line accepted by a RecordScanner. Records are at most 32760 bytes long, HFS files lines may be longer.
+const maxScanLine = 1 << 20
+
+// RecordScanner reads the records of a dataset, or the lines of an HFS file, one at a time from the
+// output of OpenReader.
+type RecordScanner struct {
+	scanner *bufio.Scanner
+	number  int
+}
+
+// NewRecordScanner returns a RecordScanner reading from r.
+func NewRecordScanner(r io.Reader) *RecordScanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), maxScanLine)
+	return &RecordScanner{scanner: scanner}
+}
+
+// Scan advances to the next record, it returns false at the end of the input or on error.
+func (s *RecordScanner) Scan() bool {
+	if !s.scanner.Scan() {
+		return false
+	}
+	s.number++
+	return true
+}
+
+// Record returns the current record.
+func (s *RecordScanner) Record() string {
+	return s.scanner.Text()
+}
+
+// Bytes returns the current record. The slice is overwritten by the next call to Scan.
+func (s *RecordScanner) Bytes() []byte {
+	return s.scanner.Bytes()
+}
+
+// Number returns the number of the current record, starting at 1.
+func (s *RecordScanner) Number() int {
+	return s.number
+}
+
+// Err returns the first error met by Scan, including the error of the utility reading the dataset.
+func (s *RecordScanner) Err() error {
+	return s.scanner.Err()
+}
+
+// OpenWriter runs Client.OpenWriter on the default client.
+func OpenWriter(dataset string, mode WriteMode, args *WriterArgs) (io.WriteCloser, error) {
+	return DefaultClient().OpenWriter(context.Background(), dataset, mode, args)
+}
+
+// Open a dataset, member or HFS file for writing. The content is staged in a temporary HFS file and
+// copied to the target by Close, with dcp, or with IEBGENER when appending to a sequential dataset,
+// so it is never passed on a command line. Nothing is written to the target before Close, whose
+// error must be checked.
+func (c *Client) OpenWriter(ctx context.Context, dataset string, mode WriteMode, args *WriterArgs) (io.WriteCloser, error) {
+	name, err := ParseDatasetName(dataset)
+	if err != nil {
+		return nil, err
+	}
+	binary := args != nil && (args.Binary || args.Encoding != nil)
+	member := name.Member() != ""
+	path := name.IsPath()
+
+	exist := false
+	switch {
+	case mode != WRITE_APPEND && mode != WRITE_CREATE:
+	case path:
+		_, statErr := os.Stat(dataset)
+		exist = statErr == nil
+	case member:
+		var members []string
+		members, err = c.ListMembers(ctx, dataset)
+		exist = len(members) > 0 && members[0] != ""
+	default:
+		exist, err = c.Exist(ctx, dataset)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if mode == WRITE_CREATE && exist {
+		return nil, fmt.Errorf("%s: %w", dataset, os.ErrExist)
+	}
+
+	w := &datasetWriter{client: c, ctx: ctx, dataset: dataset, binary: binary}
+	if mode == WRITE_APPEND && exist {
+		if !member && !path {
+			w.append = true
+		} else if binary {
+			return nil, fmt.Errorf("%s: binary append is only supported on sequential datasets", dataset)
+		}
+	}
+
+	if w.file, err = os.CreateTemp("", "zoau-*"); err != nil {
+		return nil, err
+	}
+	if args != nil && args.Encoding != nil {
+		w.encoder = args.Encoding.NewEncoder().Writer(w.file)
+	}
+	if mode == WRITE_APPEND && exist && !w.append {
+		// dcp replaces members and files, stage their current contents first.
+		if err := w.stage(); err != nil {
+			w.file.Close()
+			os.Remove(w.file.Name())
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+// datasetWriter stages the content written to a dataset in a temporary HFS file.
+type datasetWriter struct {
+	client  *Client
+	ctx     context.Context
+	dataset string
+	binary  bool
+	append  bool
+	file    *os.File
+	encoder io.WriteCloser
+	closed  bool
+}
+
+func (w *datasetWriter) stage() error {
+	reader, err := w.client.OpenReader(w.ctx, w.dataset, nil)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	_, err = io.Copy(w.file, reader)
+	return err
+}
+
+func (w *datasetWriter) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+	if w.encoder != nil {
+		return w.encoder.Write(p)
+	}
+	return w.file.Write(p)
+}
+
+// Close copies the staged content to the target and removes the temporary file.
+func (w *datasetWriter) Close() error {
+	if w.closed {
+		return os.ErrClosed
+	}
+	w.closed = true
+	defer os.Remove(w.file.Name())
+	if w.encoder != nil {
+		if err := w.encoder.Close(); err != nil {
+			w.file.Close()
+			return err
+		}
+	}
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+
+	if w.append {
+		fileData := "text"
+		if w.binary {
+			fileData = "binary"
+		}
+		input := FileDefinition{PathName: w.file.Name(), FileData: &fileData}
+		output := DatasetDefinition{DatasetName: w.dataset, Disposition: String("mod")}
+		options := []string{
+			"--pgm=IEBGENER",
+			"--sysprint=dummy",
+			"--sysin=dummy",
+			"--sysut1=" + input.buildArgsString(),
+			"--sysut2=" + output.buildArgsString(),
+		}
+		_, _, err := w.client.execZaouCmd(w.ctx, "mvscmd", options)
+		return err
+	}
+
+	options := make([]string, 0)
+	if w.binary {
+		options = append(options, "-B")
+	}
+	options = append(options, w.file.Name(), w.dataset)
+	_, _, err := w.client.execZaouCmd(w.ctx, "dcp", options)
+	return err
+}
@@ -0,0 +1,234 @@
+package zoau_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Stolkerve/zoau-go"
+	"github.com/Stolkerve/zoau-go/codepage"
+	"github.com/Stolkerve/zoau-go/zoautest"
+)
+
+// scriptClient returns a Client running script in place of the ZOAU utility named name.
+func scriptClient(t *testing.T, name string, script string, middlewares ...zoau.Middleware) *zoau.Client {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script+"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return zoau.NewClient(&zoau.ClientArgs{
+		Executor:    zoau.ExecExecutor{Config: &zoau.Config{BinDir: dir, LibDir: dir}},
+		Middlewares: middlewares,
+	})
+}
+
+func TestOpenReader(t *testing.T) {
+	ctx := context.Background()
+	c := zoau.NewClient(&zoau.ClientArgs{Executor: zoautest.NewSimulator("USER")})
+	if err := c.Write(ctx, "USER.PARMS", "A=1\nB=2\nC=3", false); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := c.OpenReader(ctx, "USER.PARMS", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scanner := zoau.NewRecordScanner(reader)
+	records := []string{}
+	for scanner.Scan() {
+		records = append(records, scanner.Record())
+	}
+	if err := scanner.Err(); err != nil || len(records) != 3 || records[2] != "C=3" || scanner.Number() != 3 {
+		t.Fatalf("Unexpected records %q, %v", records, err)
+	}
+	if err := reader.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if head, err := c.ReadHead(ctx, "USER.PARMS", zoau.Uint(2)); err != nil || head != "A=1\nB=2" {
+		t.Fatalf("expected: \"A=1\\nB=2\", got %q, %v", head, err)
+	}
+	if _, err := c.OpenReader(ctx, "USER.MISSING", nil); !errors.Is(err, zoau.ErrNotFound) {
+		t.Fatalf("expected: %v, got %v", zoau.ErrNotFound, err)
+	}
+}
+
+func TestOpenReaderStream(t *testing.T) {
+	ctx := context.Background()
+
+	var events []zoau.Event
+	observer := zoau.MiddlewareFuncs{AfterFunc: func(_ context.Context, event zoau.Event) { events = append(events, event) }}
+	c := scriptClient(t, "dtail", "exec yes RECORD", observer)
+	reader, err := c.OpenReader(ctx, "USER.HUGE", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scanner := zoau.NewRecordScanner(reader)
+	for scanner.Scan() && scanner.Number() < 1000 {
+	}
+	if scanner.Record() != "RECORD" {
+		t.Fatalf("expected: RECORD, got %q", scanner.Record())
+	}
+	start := time.Now()
+	if err := reader.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("The process was not stopped, Close took %v", elapsed)
+	}
+	if len(events) != 1 || events[0].Rc != 0 || events[0].Err != nil {
+		t.Fatalf("Close must be reported as a success, got %+v", events)
+	}
+
+	// A utility that failed before Close is still reported.
+	events = nil
+	c = scriptClient(t, "dtail", "echo A=1; echo B=2; echo 'BGYSC1102E Unable to open USER.PARMS' >&2; exit 8", observer)
+	reader, err = c.OpenReader(ctx, "USER.PARMS", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reader.Read(make([]byte, 2)); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(200 * time.Millisecond)
+	var closeErr *zoau.CommandError
+	if err := reader.Close(); !errors.As(err, &closeErr) || closeErr.Rc != 8 {
+		t.Fatalf("expected: the error of the utility, got %v", err)
+	}
+	if len(events) != 1 || events[0].Rc != 8 || events[0].Err == nil {
+		t.Fatalf("The failure must be reported, got %+v", events)
+	}
+
+	c = scriptClient(t, "dtail", "echo A=1; echo 'BGYSC1102E Unable to open USER.PARMS' >&2; exit 8")
+	reader, err = c.OpenReader(ctx, "USER.PARMS", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	content, err := io.ReadAll(reader)
+	var cmdErr *zoau.CommandError
+	if string(content) != "A=1\n" || !errors.As(err, &cmdErr) || cmdErr.Rc != 8 || cmdErr.Messages[0].Id != "BGYSC1102E" {
+		t.Fatalf("Unexpected content %q and error %v", content, err)
+	}
+}
+
+func TestOpenWriter(t *testing.T) {
+	ctx := context.Background()
+	c := zoau.NewClient(&zoau.ClientArgs{Executor: zoautest.NewSimulator("USER")})
+
+	write := func(dataset string, mode zoau.WriteMode, content string) error {
+		writer, err := c.OpenWriter(ctx, dataset, mode, nil)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(writer, content); err != nil {
+			t.Fatal(err)
+		}
+		return writer.Close()
+	}
+	read := func(dataset string) string {
+		out, err := c.Read(ctx, dataset, &zoau.ReadArgs{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+
+	content := "-n 'quoted' \"double\" back\\slash $HOME\n"
+	if err := write("USER.SEQ", zoau.WRITE_TRUNCATE, content); err != nil {
+		t.Fatal(err)
+	}
+	if err := write("USER.SEQ", zoau.WRITE_APPEND, "LAST\n"); err != nil {
+		t.Fatal(err)
+	}
+	if out := read("USER.SEQ"); out != strings.TrimSuffix(content, "\n")+"\nLAST" {
+		t.Fatalf("Unexpected content %q", out)
+	}
+
+	if _, err := c.Create(ctx, "USER.PDS", &zoau.CreateArgs{Type: zoau.String(zoau.DS_ORG_PDS)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := write("USER.PDS(NEW)", zoau.WRITE_CREATE, "A\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := write("USER.PDS(NEW)", zoau.WRITE_CREATE, "B\n"); !errors.Is(err, os.ErrExist) {
+		t.Fatalf("expected: %v, got %v", os.ErrExist, err)
+	}
+	if err := write("USER.PDS(NEW)", zoau.WRITE_APPEND, "B\n"); err != nil {
+		t.Fatal(err)
+	}
+	if out := read("USER.PDS(NEW)"); out != "A\nB" {
+		t.Fatalf("expected: \"A\\nB\", got %q", out)
+	}
+	if _, err := c.OpenWriter(ctx, "USER.PDS(NEW)", zoau.WRITE_APPEND, &zoau.WriterArgs{Binary: true}); err == nil {
+		t.Fatal("Binary appends to members must fail")
+	}
+
+	writer, err := c.OpenWriter(ctx, "USER.SEQ", zoau.WRITE_TRUNCATE, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.Close()
+	if err := writer.Close(); !errors.Is(err, os.ErrClosed) {
+		t.Fatalf("expected: %v, got %v", os.ErrClosed, err)
+	}
+}
+
+func TestOpenEncoded(t *testing.T) {
+	ctx := context.Background()
+	sim := zoautest.NewSimulator("USER")
+	c := zoau.NewClient(&zoau.ClientArgs{Executor: sim})
+
+	text := "PRICE=10€ [ok]"
+	encoded, err := codepage.IBM1140.NewEncoder().String(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer, err := c.OpenWriter(ctx, "USER.EURO", zoau.WRITE_TRUNCATE, &zoau.WriterArgs{Encoding: codepage.IBM1140})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The euro sign is split across writes.
+	for _, part := range []string{text[:9], text[9:10], text[10:]} {
+		if _, err := io.WriteString(writer, part); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := c.Read(ctx, "USER.EURO", nil); err != nil || out != encoded {
+		t.Fatalf("expected: the bytes of IBM-1140 %x, got %x, %v", encoded, out, err)
+	}
+
+	// Reading with the encoding copies the dataset to a file, even in a dry-run.
+	dry := zoau.NewClient(&zoau.ClientArgs{Executor: sim, DryRun: &zoau.Plan{}})
+	for _, client := range []*zoau.Client{c, dry} {
+		reader, err := client.OpenReader(ctx, "USER.EURO", &zoau.ReaderArgs{Encoding: codepage.IBM1140})
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := io.ReadAll(reader)
+		if err != nil || string(out) != text {
+			t.Fatalf("expected: %q, got %q, %v", text, out, err)
+		}
+		if err := reader.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writer, err = c.OpenWriter(ctx, "USER.EURO", zoau.WRITE_TRUNCATE, &zoau.WriterArgs{Encoding: codepage.IBM1047})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer writer.Close()
+	var unsupported *codepage.UnsupportedRuneError
+	if _, err := io.WriteString(writer, "€"); !errors.As(err, &unsupported) {
+		t.Fatalf("expected: an UnsupportedRuneError, got %v", err)
+	}
+}
@@ -3,7 +3,10 @@ package zoau
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
+
+	"github.com/Stolkerve/zoau-go/codepage"
 )
 
 type Args struct {
@@ -15,8 +18,28 @@ type Args struct {
  *	Datasets Types
  */
 
+type BackupArgs struct {
+	// Pattern of the backup name, a dataset or a member of an existing partitioned dataset. The placeholders
+	// {hlq}, {name} and {member} are replaced by the high level qualifier and the dataset and member backed up,
+	// {date} and {time} by the yymmdd date and the hhmmss time, and {seq} by a 4 digits number following that
+	// of the latest backup. (e.g. "{hlq}.BACKUP.D{date}.T{time}", "SYS1.BACKUP({member})")
+	// Defaults to a name generated by TmpName.
+	Name *string
+
+	// High level qualifier of the backup name. Defaults to that of the client.
+	Hlq *string
+
+	// Number of backups named after Name to keep, the oldest ones are deleted. 0 keeps every backup.
+	Retention uint
+}
+
+type RestoreArgs struct {
+	// Delete the backup once restored.
+	Delete bool
+}
+
 type BlockInFileArgs struct {
-	// The line(s) to insert inside the marker lines separated by '\\n'. (e.g. "line 1\\nline 2\\nline 3")
+	// The line(s) to insert inside the marker lines, separated by newlines. (e.g. "line 1\nline 2\nline 3")
 	Block *string
 
 	// The marker line template in this format <marker_begin>\\n<marker_end>\\n< {mark} marker>
@@ -29,7 +52,8 @@ type BlockInFileArgs struct {
 	Market *string
 
 	// Insert block after matching regex pattern
-	// The special value "EOF" will insert the block at the end of the target dataset or HFS file
+	// The special value "EOF" will insert the block at the end of the target dataset or HFS file, the default
+	// when InsBef is not provided.
 	InsAft *string
 
 	// Insert block before matching regex pattern
@@ -49,6 +73,12 @@ type BlockInFileArgs struct {
 
 	// Force open. Open dataset member in DISP=SHR mode. Default is DISP=OLD mode when False.
 	Force bool
+
+	// Compute the result against the current content without changing the dataset.
+	CheckMode bool
+
+	// Back the dataset up before changing it.
+	Backup *BackupArgs
 }
 
 type Point struct {
@@ -62,6 +92,109 @@ type CompareArgs struct {
 	IgnoreCase bool
 }
 
+// Struct that represents the outcome of LineInFile or BlockInFile.
+type EditResult struct {
+	// Dataset or member edited.
+	Dataset string
+
+	// Whether the edit changed the content, or would change it in check mode.
+	Changed bool
+
+	// Content before the edit.
+	Before string
+
+	// Content after the edit, or the content the edit would produce in check mode.
+	After string
+
+	// Lines that differ between Before and After.
+	Hunks []DiffHunk
+
+	// Name of the backup taken before the edit, empty without one.
+	Backup string
+}
+
+// Struct that represents the result of a compare, as parsed from the SuperC (ISRSUPC) listing.
+type CompareResult struct {
+	// Names of the compared datasets, OLD is the source and NEW the target.
+	Old string
+	New string
+
+	// Whether no difference was found.
+	Equal bool
+
+	Summary CompareSummary
+
+	// Differences of a sequential dataset, member or file compare.
+	Hunks []DiffHunk
+
+	// Results per member of a partitioned dataset compare.
+	Members []MemberCompare
+
+	// SuperC listing the result was parsed from.
+	Listing string
+}
+
+// Struct that represents the LINE COMPARE SUMMARY AND STATISTICS section of a SuperC listing.
+type CompareSummary struct {
+	LineMatches       uint
+	ReformattedLines  uint
+	Insertions        uint
+	Deletions         uint
+	NewLinesProcessed uint
+	OldLinesProcessed uint
+	TotalChanges      uint
+	PairedChanges     uint
+	NonPairedInserts  uint
+	NonPairedDeletes  uint
+}
+
+type DiffKind = string
+
+const (
+	// Lines inserted in the NEW file.
+	DIFF_INSERT DiffKind = "INS"
+	// Lines deleted from the OLD file.
+	DIFF_DELETE DiffKind = "DEL"
+	// Lines of the OLD file replaced by lines of the NEW file.
+	DIFF_REPLACE DiffKind = "RPL"
+)
+
+// Struct that represents a block of consecutive changed lines.
+type DiffHunk struct {
+	Kind DiffKind
+
+	// Number of the first line of the block in the NEW file and in the OLD file, starting at 1. For an
+	// insertion OldLine is the OLD line the lines are inserted before, for a deletion NewLine is the NEW
+	// line the lines were deleted before.
+	NewLine uint
+	OldLine uint
+
+	// Lines inserted from the NEW file and deleted from the OLD file.
+	Inserted []string
+	Deleted  []string
+}
+
+type MemberCompareStatus = string
+
+const (
+	MEMBER_COMPARE_SAME     MemberCompareStatus = "SAME"
+	MEMBER_COMPARE_CHANGED  MemberCompareStatus = "CHANGED"
+	MEMBER_COMPARE_NEW_ONLY MemberCompareStatus = "NEW_ONLY"
+	MEMBER_COMPARE_OLD_ONLY MemberCompareStatus = "OLD_ONLY"
+)
+
+// Struct that represents the result of the compare of a member of a partitioned dataset.
+type MemberCompare struct {
+	Name   string
+	Status MemberCompareStatus
+
+	// Number of lines of the member in the NEW and the OLD dataset.
+	NewLines uint
+	OldLines uint
+
+	Hunks []DiffHunk
+}
+
 type CopyArgs struct {
 	// If the source data set has aliases, they will be recreated in the target data set.
 	Alias bool
@@ -157,6 +290,15 @@ type CreateArgs struct {
 	Volumes *string
 }
 
+type FindReplaceArgs struct {
+	// Match the string to find as is and insert the replacement as is, instead of treating them as a
+	// regular expression and a sed replacement.
+	Literal bool
+
+	// Back the dataset up before changing it.
+	Backup *BackupArgs
+}
+
 type LineInFileArgs struct {
 	// The regular expression to look for in every line of the dataset or HFS file.
 	//	- For state = True, the pattern to replace if found. Only the last line found will be replaced.
@@ -165,7 +307,7 @@ type LineInFileArgs struct {
 	Regex *string
 
 	// Insert line after matching regex pattern.
-	//	- The special value “EOF” will insert the line at the end of the target dataset or HFS file.
+	//	- The special value “EOF” will insert the line at the end of the target dataset or HFS file, the default when ins_bef is not provided.
 	//	- If regex is provided, ins_aft is only honored if no match for regex is found.
 	//	- ins_bef will be ignored if provided.
 	InsAft *string
@@ -192,6 +334,12 @@ type LineInFileArgs struct {
 
 	// Force open. Open dataset member in DISP=SHR mode. Default is DISP=OLD mode when False.
 	Force bool
+
+	// Compute the result against the current content without changing the dataset.
+	CheckMode bool
+
+	// Back the dataset up before changing it.
+	Backup *BackupArgs
 }
 
 type ListingArgs struct {
@@ -235,6 +383,367 @@ type Dataset struct {
 	TotalSpace int
 }
 
+type WriteMode = uint
+
+const (
+	// Replace the contents of the dataset, member or HFS file, creating it if it does not exist.
+	WRITE_TRUNCATE WriteMode = iota
+
+	// Add to the end of the contents, creating the dataset, member or HFS file if it does not exist.
+	WRITE_APPEND
+
+	// Create a new member, dataset or HFS file. Fails if it already exists.
+	WRITE_CREATE
+)
+
+type ReaderArgs struct {
+	// Code page of the dataset. Its bytes are copied without conversion and converted to UTF-8 as
+	// they are read, records are not separated by new lines.
+	Encoding codepage.Encoding
+}
+
+type WriterArgs struct {
+	// Copy the content without conversion, for binary data.
+	// Binary appends are only supported on sequential datasets.
+	Binary bool
+
+	// Code page of the dataset. The UTF-8 written is converted to it and copied as with Binary.
+	Encoding codepage.Encoding
+}
+
+type GdgOrder = string
+
+const (
+	// The generations are ordered oldest first.
+	GDG_ORDER_FIFO GdgOrder = "FIFO"
+
+	// The generations are ordered newest first.
+	GDG_ORDER_LIFO GdgOrder = "LIFO"
+)
+
+type GdgArgs struct {
+	// Maximum number of generations, 1 to 255 (999 for extended GDGs).
+	Limit uint
+
+	// Delete the generations rolled off the GDG, instead of only uncataloging them.
+	Scratch bool
+
+	// Roll off every generation when the limit is reached, instead of only the oldest.
+	Empty bool
+
+	// Delete the rolled off generations even if their expiration date has not passed.
+	Purge bool
+
+	// Order of the generations, GDG_ORDER_FIFO (default) or GDG_ORDER_LIFO.
+	Order *GdgOrder
+
+	// Define an extended GDG, whose limit goes up to 999.
+	Extended bool
+}
+
+type AlterGdgArgs struct {
+	// Maximum number of generations. The generations past the new limit are rolled off.
+	Limit *uint
+
+	// Delete the generations rolled off the GDG, instead of only uncataloging them.
+	Scratch *bool
+
+	// Roll off every generation when the limit is reached, instead of only the oldest.
+	Empty *bool
+
+	// Delete the rolled off generations even if their expiration date has not passed.
+	Purge *bool
+
+	// Order of the generations, GDG_ORDER_FIFO or GDG_ORDER_LIFO.
+	Order *GdgOrder
+
+	// Convert the GDG to an extended GDG.
+	Extended bool
+}
+
+type DeleteGdgArgs struct {
+	// Delete the generations along with the base.
+	Force bool
+
+	// Delete even if the expiration date has not passed.
+	Purge bool
+}
+
+type SpaceUnit = string
+
+const (
+	SPACE_UNIT_CYLINDERS SpaceUnit = "CYLINDERS"
+	SPACE_UNIT_TRACKS    SpaceUnit = "TRACKS"
+	SPACE_UNIT_RECORDS   SpaceUnit = "RECORDS"
+	SPACE_UNIT_KILOBYTES SpaceUnit = "KILOBYTES"
+	SPACE_UNIT_MEGABYTES SpaceUnit = "MEGABYTES"
+)
+
+type ClusterArgs struct {
+	// Type of cluster: DS_ORG_KSDS (default), DS_ORG_ESDS, DS_ORG_RRDS or DS_ORG_LDS.
+	Type *DsType
+
+	// Required for KSDS clusters. Not valid for the other types.
+	Keys *KeyPoint
+
+	// Average and maximum record length, expressed in bytes. Not valid for LDS clusters.
+	// Both default to the IDCAMS defaults when MaximumRecordSize is 0.
+	AverageRecordSize uint
+	MaximumRecordSize uint
+
+	// Unit of PrimarySpace and SecondarySpace. Defaults to SPACE_UNIT_TRACKS.
+	SpaceUnit *SpaceUnit
+
+	// Space to allocate for the cluster. 0 leaves the space to the SMS data class.
+	PrimarySpace uint
+
+	// Secondary (extent) space to allocate for the cluster.
+	SecondarySpace uint
+
+	// Volume serials of the cluster.
+	Volumes []string
+
+	// Size of the control intervals, expressed in bytes.
+	ControlIntervalSize *uint
+
+	// Free space left in every control interval and control area on load, in percent.
+	FreeSpace *Point
+
+	// Cross-region and cross-system share options, 1 to 4.
+	ShareOptions *Point
+
+	// Allow the cluster to be opened for output as a new cluster, see ReproArgs.Reuse.
+	Reuse bool
+
+	// The storage class for an SMS-managed cluster.
+	StorageClassName *string
+
+	// Data class name for cluster.
+	DataClassName *string
+
+	// The management class for an SMS-managed cluster.
+	ManagementClassName *string
+
+	// Names of the data and index components. IDCAMS generates them when nil.
+	DataName  *string
+	IndexName *string
+
+	// Run IDCAMS authorized, with ExecuteAuthorized.
+	Authorized bool
+}
+
+type AlterClusterArgs struct {
+	// New name of the entry. The names of the components are kept.
+	NewName *string
+
+	// Free space left in every control interval and control area on load, in percent. Data component only.
+	FreeSpace *Point
+
+	// Cross-region and cross-system share options, 1 to 4. Data or index component only.
+	ShareOptions *Point
+
+	// Every alternate key points to a single record of the base cluster. Empty alternate index only.
+	UniqueKey *bool
+
+	// Keep the alternate index up to date when the base cluster is changed through a path.
+	Upgrade *bool
+
+	// The storage class for an SMS-managed cluster.
+	StorageClassName *string
+
+	// The management class for an SMS-managed cluster.
+	ManagementClassName *string
+
+	// Run IDCAMS authorized, with ExecuteAuthorized.
+	Authorized bool
+}
+
+type AlternateIndexArgs struct {
+	// Length and offset of the alternate key in the records of the base cluster. Required.
+	Keys *KeyPoint
+
+	// Every alternate key points to a single record of the base cluster.
+	UniqueKey bool
+
+	// Keep the alternate index up to date when the base cluster is changed through a path.
+	Upgrade bool
+
+	// Average and maximum record length of the alternate index, expressed in bytes.
+	AverageRecordSize uint
+	MaximumRecordSize uint
+
+	// Unit of PrimarySpace and SecondarySpace. Defaults to SPACE_UNIT_TRACKS.
+	SpaceUnit *SpaceUnit
+
+	// Space to allocate for the alternate index. 0 leaves the space to the SMS data class.
+	PrimarySpace uint
+
+	// Secondary (extent) space to allocate for the alternate index.
+	SecondarySpace uint
+
+	// Volume serials of the alternate index.
+	Volumes []string
+
+	// Names of the data and index components. IDCAMS generates them when nil.
+	DataName  *string
+	IndexName *string
+
+	// Run IDCAMS authorized, with ExecuteAuthorized.
+	Authorized bool
+}
+
+type PathArgs struct {
+	// Open the upgrade set of the base cluster along with the path, keeping its alternate indexes up to date.
+	Update bool
+
+	// Run IDCAMS authorized, with ExecuteAuthorized.
+	Authorized bool
+}
+
+type BuildIndexArgs struct {
+	// Run IDCAMS authorized, with ExecuteAuthorized.
+	Authorized bool
+}
+
+type ReproArgs struct {
+	// Replace the records of the target having the same key or relative record number.
+	Replace bool
+
+	// Reset a reusable target cluster before loading it.
+	Reuse bool
+
+	// First and last keys to copy, for a KSDS source.
+	FromKey *string
+	ToKey   *string
+
+	// Number of records to skip at the beginning of the source.
+	Skip *uint
+
+	// Number of records to copy.
+	Count *uint
+
+	// Run IDCAMS authorized, with ExecuteAuthorized.
+	Authorized bool
+}
+
+type PrintFormat = string
+
+const (
+	PRINT_FORMAT_CHARACTER PrintFormat = "CHARACTER"
+	PRINT_FORMAT_HEX       PrintFormat = "HEX"
+	PRINT_FORMAT_DUMP      PrintFormat = "DUMP"
+)
+
+type PrintArgs struct {
+	// Format of the records, PRINT_FORMAT_DUMP (default), PRINT_FORMAT_CHARACTER or PRINT_FORMAT_HEX.
+	Format *PrintFormat
+
+	// First and last keys to print, for a KSDS.
+	FromKey *string
+	ToKey   *string
+
+	// Number of records to skip at the beginning of the dataset.
+	Skip *uint
+
+	// Number of records to print.
+	Count *uint
+
+	// Run IDCAMS authorized, with ExecuteAuthorized.
+	Authorized bool
+}
+
+type ListCatalogArgs struct {
+	// List every entry whose name begins with the given qualifiers, instead of the named entries.
+	Level bool
+
+	// Run IDCAMS authorized, with ExecuteAuthorized.
+	Authorized bool
+}
+
+// Struct that represents a VSAM cluster, alternate index or path, as listed by ListCatalog.
+type VsamCluster struct {
+	// Name of the entry.
+	Name string
+
+	// Type of the entry: CLUSTER, AIX or PATH.
+	Type string
+
+	// Catalog the entry is cataloged in.
+	Catalog string
+
+	// Base cluster of an alternate index, or alternate index (or cluster) a path goes through.
+	Related string
+
+	// SMS classes of the cluster. Empty if not SMS-managed.
+	StorageClass    string
+	ManagementClass string
+	DataClass       string
+
+	// Data component. nil for a path.
+	Data *VsamComponent
+
+	// Index component. nil for ESDS, RRDS, LDS clusters and paths.
+	Index *VsamComponent
+
+	// Every "KEYWORD---value" field listed for the entry, as printed by LISTCAT.
+	Fields map[string]string
+}
+
+// Struct that represents the data or index component of a VSAM cluster.
+type VsamComponent struct {
+	// Name of the component.
+	Name string
+
+	// Organization of the cluster: INDEXED (KSDS), NONINDEXED (ESDS), NUMBERED (RRDS) or LINEAR (LDS).
+	Organization string
+
+	// Key length and offset.
+	KeyLength uint
+	KeyOffset uint
+
+	// Average and maximum record length.
+	AverageRecordLength uint
+	MaximumRecordLength uint
+
+	// Size of the control intervals.
+	ControlIntervalSize uint
+
+	// Cross-region and cross-system share options.
+	ShareOptions Point
+
+	// Flags of the ATTRIBUTES section other than the organization (e.g. UNIQUE, REUSE, SPANNED).
+	Attributes []string
+
+	// Record statistics.
+	Records          uint64
+	DeletedRecords   uint64
+	InsertedRecords  uint64
+	UpdatedRecords   uint64
+	RetrievedRecords uint64
+
+	// Control interval and control area splits.
+	CISplits uint64
+	CASplits uint64
+
+	// Number of extents.
+	Extents uint
+
+	// Allocation unit (e.g. CYLINDER, TRACK) and primary and secondary quantities.
+	SpaceType      string
+	PrimarySpace   uint
+	SecondarySpace uint
+
+	// High allocated and high used relative byte addresses.
+	HighAllocatedRba uint64
+	HighUsedRba      uint64
+
+	// Volume serials of the component.
+	Volumes []string
+
+	// Every "KEYWORD---value" field listed for the component, as printed by LISTCAT.
+	Fields map[string]string
+}
+
 type ReadArgs struct {
 	// Read the last tail lines from the dataset.
 	Tail *uint
@@ -244,20 +753,51 @@ type ReadArgs struct {
 }
 
 type SearchArgs struct {
-	// Print only a count of matched lines in the dataset.
+	// Only count the matched lines, the result has no Matches.
 	CountLines bool
 
-	// Display the line number for each match.
+	// Deprecated: the line number of every match is in SearchMatch.Line.
 	DisplayLines bool
 
 	// Ignore case for search.
 	IgnoreCase bool
 
-	// Print names of datasets being searched.
+	// Deprecated: the dataset and member of every match are in SearchMatch.
 	PrintDatasets bool
 
 	// Number of lines to be shown before and after each match.
 	Lines *uint
+
+	// Search the value as a literal string instead of a regular expression.
+	Literal bool
+
+	// More datasets, members or patterns searched after the first one, in order, as a concatenation.
+	Concatenation []string
+}
+
+// Struct that represents the lines found by Search.
+type SearchResult struct {
+	// Number of matched lines, in all the datasets and members searched.
+	Count uint
+
+	Matches []SearchMatch
+}
+
+// Struct that represents a line matched by Search.
+type SearchMatch struct {
+	Dataset string
+
+	// Member of a partitioned dataset, empty for a sequential dataset.
+	Member string
+
+	// Number of the matched line, starting at 1.
+	Line uint
+
+	Text string
+
+	// Lines around the match, at most SearchArgs.Lines of each.
+	Before []string
+	After  []string
 }
 
 type UnZipArgs struct {
@@ -349,6 +889,24 @@ func (s *ValueDefinition) buildArgsString() string {
 	return s.V
 }
 
+// Definition of an in-stream dataset (e.g. control statements), fed to the program through the
+// standard input of mvscmd. A program has at most one InputDefinition.
+type InputDefinition struct {
+	// Records of the dataset, separated by '\n'.
+	Content string
+}
+
+func (s *InputDefinition) buildArgsString() string {
+	return "stdin"
+}
+
+func (s *InputDefinition) records() string {
+	if s.Content == "" || strings.HasSuffix(s.Content, "\n") {
+		return s.Content
+	}
+	return s.Content + "\n"
+}
+
 // Definition of an HFS file
 type FileDefinition struct {
 	// Full path to the HFS file
@@ -371,22 +929,22 @@ func (f *FileDefinition) buildArgsString() string {
 	if f.AbnormalDisposition != nil {
 		appendMvscmdString(&args, "abnormdisp", *f.AbnormalDisposition)
 	}
-	if f.NormalDisposition != nil {
+	if f.PathMode != nil {
 		appendMvscmdString(&args, "pathmode", *f.PathMode)
 	}
-	if f.NormalDisposition != nil {
+	if f.StatusGroup != nil {
 		appendMvscmdString(&args, "statusgroup", *f.StatusGroup)
 	}
-	if f.NormalDisposition != nil {
+	if f.FileData != nil {
 		appendMvscmdString(&args, "filedata", *f.FileData)
 	}
-	if f.NormalDisposition != nil {
+	if f.RecordLength != nil {
 		appendMvscmdString(&args, "lrecl", *f.RecordLength)
 	}
-	if f.NormalDisposition != nil {
+	if f.BlockSize != nil {
 		appendMvscmdString(&args, "blksize", *f.BlockSize)
 	}
-	if f.NormalDisposition != nil {
+	if f.RecordFormat != nil {
 		appendMvscmdString(&args, "recfm", *f.RecordFormat)
 	}
 
@@ -550,6 +1108,14 @@ type ApfArgs struct {
 	Batch        []ApfOptData
 }
 
+type OperCmdArgs struct {
+	// Seconds to wait for the command response (opercmd -t <seconds>).
+	Timeout *uint
+
+	// Verbose output, with the messages of opercmd itself (opercmd -v).
+	Verbose bool
+}
+
 /*
  * Jobs types
  */
@@ -1,9 +1,8 @@
 package zoau
 
 import (
-	"errors"
+	"context"
 	"fmt"
-	"os/exec"
 	"strconv"
 	"strings"
 	"time"
@@ -18,6 +17,10 @@ func Uint(v uint) *uint {
 	return &v
 }
 
+func Bool(v bool) *bool {
+	return &v
+}
+
 func parseUniversalArgs(args Args) []string {
 	options := make([]string, 0)
 	if args.Debug {
@@ -30,27 +33,16 @@ func parseUniversalArgs(args Args) []string {
 	return options
 }
 
-func execZaouCmd(proc string, params []string) (string, int, error) {
-	// fmt.Println(proc, params)
-	cmd := exec.Command(proc, params...)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return string(output), cmd.ProcessState.ExitCode(), errors.New(string(output))
-	}
-
-	return string(output), cmd.ProcessState.ExitCode(), nil
-}
-
-func execSimpleStringCmd(proc string, params []string) (string, error) {
-	if stdout, _, err := execZaouCmd(proc, params); err != nil {
+func (c *Client) execSimpleStringCmd(ctx context.Context, proc string, params []string) (string, error) {
+	if stdout, _, err := c.execZaouCmd(ctx, proc, params); err != nil {
 		return "", err
 	} else {
 		return strings.TrimRight(stdout, "\n"), nil
 	}
 }
 
-func execSimpleStringListCmd(proc string, params []string) ([]string, error) {
-	if stdout, _, err := execZaouCmd(proc, params); err != nil {
+func (c *Client) execSimpleStringListCmd(ctx context.Context, proc string, params []string) ([]string, error) {
+	if stdout, _, err := c.execZaouCmd(ctx, proc, params); err != nil {
 		return nil, err
 	} else {
 		return strings.Split(stdout, "\n"), nil
@@ -106,7 +98,14 @@ func appendMvscmdString(args *string, variableName string, variable string) {
 	*args += fmt.Sprintf(",%s=%s", variableName, variable)
 }
 
-func timeOut(duration time.Duration, timeOutChan chan struct{}) {
-	time.Sleep(duration)
-	timeOutChan <- struct{}{}
+// sleepContext pauses the current goroutine for the given duration or until ctx is done.
+func sleepContext(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
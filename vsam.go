@@ -0,0 +1,544 @@
+package zoau
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefineCluster runs Client.DefineCluster on the default client.
+func DefineCluster(name string, args *ClusterArgs) error {
+	return DefaultClient().DefineCluster(context.Background(), name, args)
+}
+
+// Define a VSAM cluster with IDCAMS. A nil args defines a KSDS, which requires keys, so args
+// is required in practice.
+func (c *Client) DefineCluster(ctx context.Context, name string, args *ClusterArgs) error {
+	cluster, err := catalogEntryName(name, "cluster")
+	if err != nil {
+		return err
+	}
+	if args == nil {
+		args = &ClusterArgs{}
+	}
+
+	dsType := DS_ORG_KSDS
+	if args.Type != nil {
+		dsType = *args.Type
+	}
+	organization, ok := vsamOrganizations[dsType]
+	if !ok {
+		return fmt.Errorf("Invalid cluster type %s", dsType)
+	}
+	if dsType == DS_ORG_KSDS && args.Keys == nil {
+		return errors.New("Keys is required to define a KSDS")
+	}
+	if dsType != DS_ORG_KSDS && args.Keys != nil {
+		return fmt.Errorf("Keys is not valid for a %s", dsType)
+	}
+	if dsType == DS_ORG_LDS && args.MaximumRecordSize != 0 {
+		return errors.New("Record sizes are not valid for a LDS")
+	}
+
+	params := []string{fmt.Sprintf("NAME(%s)", cluster), organization}
+	if args.Keys != nil {
+		params = append(params, vsamKeys(args.Keys))
+	}
+	params = append(params, vsamAllocation(args.AverageRecordSize, args.MaximumRecordSize, args.SpaceUnit, args.PrimarySpace, args.SecondarySpace, args.Volumes)...)
+	if args.ControlIntervalSize != nil {
+		params = append(params, fmt.Sprintf("CONTROLINTERVALSIZE(%d)", *args.ControlIntervalSize))
+	}
+	if args.FreeSpace != nil {
+		params = append(params, fmt.Sprintf("FREESPACE(%d %d)", args.FreeSpace.Start, args.FreeSpace.End))
+	}
+	if args.ShareOptions != nil {
+		params = append(params, fmt.Sprintf("SHAREOPTIONS(%d %d)", args.ShareOptions.Start, args.ShareOptions.End))
+	}
+	if args.Reuse {
+		params = append(params, "REUSE")
+	}
+	if args.StorageClassName != nil {
+		params = append(params, fmt.Sprintf("STORAGECLASS(%s)", *args.StorageClassName))
+	}
+	if args.DataClassName != nil {
+		params = append(params, fmt.Sprintf("DATACLASS(%s)", *args.DataClassName))
+	}
+	if args.ManagementClassName != nil {
+		params = append(params, fmt.Sprintf("MANAGEMENTCLASS(%s)", *args.ManagementClassName))
+	}
+	params = append(params, ")")
+
+	indexName := args.IndexName
+	if dsType != DS_ORG_KSDS {
+		indexName = nil
+	}
+	components, err := vsamComponents(args.DataName, indexName)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.idcams(ctx, args.Authorized, idcamsStatement("DEFINE CLUSTER (", append(params, components...)...))
+	return err
+}
+
+// AlterCluster runs Client.AlterCluster on the default client.
+func AlterCluster(name string, args *AlterClusterArgs) error {
+	return DefaultClient().AlterCluster(context.Background(), name, args)
+}
+
+// Alter the attributes of a VSAM cluster, alternate index or path with IDCAMS. name can also be a data or
+// index component, to which FreeSpace and ShareOptions apply.
+func (c *Client) AlterCluster(ctx context.Context, name string, args *AlterClusterArgs) error {
+	entry, err := catalogEntryName(name, "cluster")
+	if err != nil {
+		return err
+	}
+
+	params := make([]string, 0)
+	if args != nil {
+		if args.NewName != nil {
+			newName, err := catalogEntryName(*args.NewName, "cluster")
+			if err != nil {
+				return err
+			}
+			params = append(params, fmt.Sprintf("NEWNAME(%s)", newName))
+		}
+		if args.FreeSpace != nil {
+			params = append(params, fmt.Sprintf("FREESPACE(%d %d)", args.FreeSpace.Start, args.FreeSpace.End))
+		}
+		if args.ShareOptions != nil {
+			params = append(params, fmt.Sprintf("SHAREOPTIONS(%d %d)", args.ShareOptions.Start, args.ShareOptions.End))
+		}
+		if args.UniqueKey != nil {
+			params = append(params, idcamsFlag(*args.UniqueKey, "UNIQUEKEY", "NONUNIQUEKEY"))
+		}
+		if args.Upgrade != nil {
+			params = append(params, idcamsFlag(*args.Upgrade, "UPGRADE", "NOUPGRADE"))
+		}
+		if args.StorageClassName != nil {
+			params = append(params, fmt.Sprintf("STORAGECLASS(%s)", *args.StorageClassName))
+		}
+		if args.ManagementClassName != nil {
+			params = append(params, fmt.Sprintf("MANAGEMENTCLASS(%s)", *args.ManagementClassName))
+		}
+	}
+	if len(params) == 0 {
+		return errors.New("At least one attribute is required to alter a cluster")
+	}
+
+	_, err = c.idcams(ctx, args.Authorized, idcamsStatement("ALTER "+entry, params...))
+	return err
+}
+
+// DefineAlternateIndex runs Client.DefineAlternateIndex on the default client.
+func DefineAlternateIndex(name string, cluster string, args *AlternateIndexArgs) error {
+	return DefaultClient().DefineAlternateIndex(context.Background(), name, cluster, args)
+}
+
+// Define an alternate index over the KSDS or ESDS cluster with IDCAMS. The alternate index is empty
+// until BuildIndex is run, and is reached through a path, see DefinePath.
+func (c *Client) DefineAlternateIndex(ctx context.Context, name string, cluster string, args *AlternateIndexArgs) error {
+	aix, err := catalogEntryName(name, "alternate index")
+	if err != nil {
+		return err
+	}
+	base, err := catalogEntryName(cluster, "cluster")
+	if err != nil {
+		return err
+	}
+	if args == nil || args.Keys == nil {
+		return errors.New("Keys is required to define an alternate index")
+	}
+
+	params := []string{
+		fmt.Sprintf("NAME(%s)", aix),
+		fmt.Sprintf("RELATE(%s)", base),
+		vsamKeys(args.Keys),
+		idcamsFlag(args.UniqueKey, "UNIQUEKEY", "NONUNIQUEKEY"),
+		idcamsFlag(args.Upgrade, "UPGRADE", "NOUPGRADE"),
+	}
+	params = append(params, vsamAllocation(args.AverageRecordSize, args.MaximumRecordSize, args.SpaceUnit, args.PrimarySpace, args.SecondarySpace, args.Volumes)...)
+	params = append(params, ")")
+
+	components, err := vsamComponents(args.DataName, args.IndexName)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.idcams(ctx, args.Authorized, idcamsStatement("DEFINE ALTERNATEINDEX (", append(params, components...)...))
+	return err
+}
+
+// DefinePath runs Client.DefinePath on the default client.
+func DefinePath(name string, entry string, args *PathArgs) error {
+	return DefaultClient().DefinePath(context.Background(), name, entry, args)
+}
+
+// Define a path named name with IDCAMS, giving access to the records of a cluster through the
+// alternate index entry.
+func (c *Client) DefinePath(ctx context.Context, name string, entry string, args *PathArgs) error {
+	path, err := catalogEntryName(name, "path")
+	if err != nil {
+		return err
+	}
+	aix, err := catalogEntryName(entry, "alternate index")
+	if err != nil {
+		return err
+	}
+	if args == nil {
+		args = &PathArgs{}
+	}
+
+	params := []string{
+		fmt.Sprintf("NAME(%s)", path),
+		fmt.Sprintf("PATHENTRY(%s)", aix),
+		idcamsFlag(args.Update, "UPDATE", "NOUPDATE"),
+		")",
+	}
+	_, err = c.idcams(ctx, args.Authorized, idcamsStatement("DEFINE PATH (", params...))
+	return err
+}
+
+// BuildIndex runs Client.BuildIndex on the default client.
+func BuildIndex(cluster string, aix string, args *BuildIndexArgs) error {
+	return DefaultClient().BuildIndex(context.Background(), cluster, aix, args)
+}
+
+// Build the alternate index aix from the records of its base cluster with IDCAMS BLDINDEX. The keys are
+// sorted in virtual storage (INTERNALSORT), as an external sort needs the IDCUT1 and IDCUT2 work files,
+// which BuildIndex does not allocate.
+func (c *Client) BuildIndex(ctx context.Context, cluster string, aix string, args *BuildIndexArgs) error {
+	base, err := catalogEntryName(cluster, "cluster")
+	if err != nil {
+		return err
+	}
+	index, err := catalogEntryName(aix, "alternate index")
+	if err != nil {
+		return err
+	}
+	if args == nil {
+		args = &BuildIndexArgs{}
+	}
+
+	params := []string{
+		fmt.Sprintf("INDATASET(%s)", base),
+		fmt.Sprintf("OUTDATASET(%s)", index),
+		"INTERNALSORT",
+	}
+	_, err = c.idcams(ctx, args.Authorized, idcamsStatement("BLDINDEX", params...))
+	return err
+}
+
+// Repro runs Client.Repro on the default client.
+func Repro(source string, target string, args *ReproArgs) error {
+	return DefaultClient().Repro(context.Background(), source, target, args)
+}
+
+// Copy the records of source into target with IDCAMS REPRO. Both can be VSAM clusters, paths or
+// sequential datasets, which makes Repro the way to load and unload a cluster.
+func (c *Client) Repro(ctx context.Context, source string, target string, args *ReproArgs) error {
+	in, err := catalogEntryName(source, "data set")
+	if err != nil {
+		return err
+	}
+	out, err := catalogEntryName(target, "data set")
+	if err != nil {
+		return err
+	}
+	if args == nil {
+		args = &ReproArgs{}
+	}
+
+	params := []string{fmt.Sprintf("INDATASET(%s)", in), fmt.Sprintf("OUTDATASET(%s)", out)}
+	params = append(params, vsamRange(args.FromKey, args.ToKey, args.Skip, args.Count)...)
+	if args.Replace {
+		params = append(params, "REPLACE")
+	}
+	if args.Reuse {
+		params = append(params, "REUSE")
+	}
+	_, err = c.idcams(ctx, args.Authorized, idcamsStatement("REPRO", params...))
+	return err
+}
+
+// PrintDataset runs Client.PrintDataset on the default client.
+func PrintDataset(name string, args *PrintArgs) (string, error) {
+	return DefaultClient().PrintDataset(context.Background(), name, args)
+}
+
+// Print the records of a VSAM cluster, path or sequential dataset with IDCAMS PRINT.
+// Returns the SYSPRINT listing.
+func (c *Client) PrintDataset(ctx context.Context, name string, args *PrintArgs) (string, error) {
+	in, err := catalogEntryName(name, "data set")
+	if err != nil {
+		return "", err
+	}
+	if args == nil {
+		args = &PrintArgs{}
+	}
+
+	params := []string{fmt.Sprintf("INDATASET(%s)", in)}
+	if args.Format != nil {
+		params = append(params, *args.Format)
+	}
+	params = append(params, vsamRange(args.FromKey, args.ToKey, args.Skip, args.Count)...)
+	return c.idcams(ctx, args.Authorized, idcamsStatement("PRINT", params...))
+}
+
+// ListCatalog runs Client.ListCatalog on the default client.
+func ListCatalog(name string, args *ListCatalogArgs) ([]VsamCluster, error) {
+	return DefaultClient().ListCatalog(context.Background(), name, args)
+}
+
+// Returns the VSAM clusters, alternate indexes and paths named name, as listed by IDCAMS LISTCAT ALL.
+// With args.Level, every entry whose name begins with the qualifiers of name is listed, and an empty
+// level is not an error. The other entries (e.g. non-VSAM datasets, GDG bases) are left out.
+func (c *Client) ListCatalog(ctx context.Context, name string, args *ListCatalogArgs) ([]VsamCluster, error) {
+	entry, err := catalogEntryName(name, "catalog entry")
+	if err != nil {
+		return nil, err
+	}
+	if args == nil {
+		args = &ListCatalogArgs{}
+	}
+
+	selection := fmt.Sprintf("ENTRIES(%s)", entry)
+	if args.Level {
+		selection = fmt.Sprintf("LEVEL(%s)", entry)
+	}
+	stdout, err := c.idcams(ctx, args.Authorized, idcamsStatement("LISTCAT", selection, "ALL"))
+	if args.Level && errors.Is(err, ErrNotFound) {
+		return []VsamCluster{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return ParseListCatalog(stdout), nil
+}
+
+// IDCAMS keyword of every VSAM cluster type.
+var vsamOrganizations = map[DsType]string{
+	DS_ORG_KSDS: "INDEXED",
+	DS_ORG_ESDS: "NONINDEXED",
+	DS_ORG_RRDS: "NUMBERED",
+	DS_ORG_LDS:  "LINEAR",
+}
+
+func vsamKeys(keys *KeyPoint) string {
+	return fmt.Sprintf("KEYS(%d %d)", keys.KeyLength, keys.KeyOffset)
+}
+
+// vsamAllocation returns the RECORDSIZE, space and VOLUMES parameters of a cluster or alternate index.
+func vsamAllocation(average uint, maximum uint, unit *SpaceUnit, primary uint, secondary uint, volumes []string) []string {
+	params := make([]string, 0)
+	if maximum != 0 {
+		params = append(params, fmt.Sprintf("RECORDSIZE(%d %d)", min(average, maximum), maximum))
+	}
+	if primary != 0 {
+		spaceUnit := SPACE_UNIT_TRACKS
+		if unit != nil {
+			spaceUnit = *unit
+		}
+		params = append(params, fmt.Sprintf("%s(%d %d)", spaceUnit, primary, secondary))
+	}
+	if len(volumes) != 0 {
+		params = append(params, fmt.Sprintf("VOLUMES(%s)", strings.Join(volumes, " ")))
+	}
+	return params
+}
+
+// vsamComponents returns the DATA and INDEX parameters naming the components of a cluster or alternate index.
+func vsamComponents(dataName *string, indexName *string) ([]string, error) {
+	params := make([]string, 0)
+	for _, component := range []struct {
+		Keyword string
+		Name    *string
+	}{{"DATA", dataName}, {"INDEX", indexName}} {
+		if component.Name == nil {
+			continue
+		}
+		name, err := catalogEntryName(*component.Name, "component")
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, component.Keyword+" (", fmt.Sprintf("NAME(%s)", name), ")")
+	}
+	return params, nil
+}
+
+// vsamRange returns the parameters selecting the records processed by REPRO and PRINT.
+func vsamRange(fromKey *string, toKey *string, skip *uint, count *uint) []string {
+	params := make([]string, 0)
+	if fromKey != nil {
+		params = append(params, fmt.Sprintf("FROMKEY(%s)", idcamsQuote(*fromKey)))
+	}
+	if toKey != nil {
+		params = append(params, fmt.Sprintf("TOKEY(%s)", idcamsQuote(*toKey)))
+	}
+	if skip != nil {
+		params = append(params, fmt.Sprintf("SKIP(%d)", *skip))
+	}
+	if count != nil {
+		params = append(params, fmt.Sprintf("COUNT(%d)", *count))
+	}
+	return params
+}
+
+var idcamsPlainRegex = regexp.MustCompile(`^[A-Z0-9@#$]+$`)
+
+// idcamsQuote quotes an IDCAMS value unless it only holds upper case letters, digits and national characters.
+func idcamsQuote(value string) string {
+	if idcamsPlainRegex.MatchString(value) {
+		return value
+	}
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+var (
+	listcatEntryRegex   = regexp.MustCompile(`^\s{0,4}([A-Z]+(?: BASE)?)\s+-{3,}\s+(\S+)\s*$`)
+	listcatFieldRegex   = regexp.MustCompile(`([A-Z][A-Z0-9%/]*(?:-[A-Z0-9%/]+)*)\s*-{2,}\s*(\S+)`)
+	listcatShareRegex   = regexp.MustCompile(`^SHROPTNS\((\d+),(\d+)\)$`)
+	listcatSectionRegex = regexp.MustCompile(`^[A-Z]+$`)
+)
+
+// ParseListCatalog parses the output of IDCAMS LISTCAT ALL into the VSAM clusters, alternate indexes
+// and paths it lists. The fields missing from the listing are left to their zero value.
+func ParseListCatalog(listing string) []VsamCluster {
+	clusters := make([]VsamCluster, 0)
+	var cluster *VsamCluster
+	var component *VsamComponent
+	section := ""
+
+	for _, line := range strings.Split(listing, "\n") {
+		if strings.Contains(line, "THE NUMBER OF ENTRIES PROCESSED WAS") {
+			cluster = nil
+			continue
+		}
+		if m := listcatEntryRegex.FindStringSubmatch(line); m != nil {
+			section = ""
+			component = nil
+			switch m[1] {
+			case "CLUSTER", "AIX", "PATH":
+				clusters = append(clusters, VsamCluster{Name: m[2], Type: m[1], Fields: make(map[string]string)})
+				cluster = &clusters[len(clusters)-1]
+			case "DATA", "INDEX":
+				if cluster == nil {
+					continue
+				}
+				component = &VsamComponent{Name: m[2], Fields: make(map[string]string)}
+				if m[1] == "DATA" {
+					cluster.Data = component
+				} else {
+					cluster.Index = component
+				}
+			default:
+				cluster = nil
+			}
+			continue
+		}
+		if cluster == nil {
+			continue
+		}
+
+		text := strings.TrimSpace(line)
+		if listcatSectionRegex.MatchString(text) {
+			section = text
+			continue
+		}
+
+		fields := listcatFieldRegex.FindAllStringSubmatch(text, -1)
+		for _, f := range fields {
+			key, value := f[1], f[2]
+			if value == "(NULL)" {
+				value = ""
+			}
+			switch {
+			case section == "ASSOCIATIONS":
+				if component == nil && cluster.Related == "" && ((cluster.Type == "AIX" && key == "CLUSTER") || (cluster.Type == "PATH" && (key == "AIX" || key == "CLUSTER"))) {
+					cluster.Related = value
+				}
+			case component != nil:
+				component.Fields[key] = value
+				component.setField(key, value)
+			default:
+				cluster.Fields[key] = value
+				cluster.setField(key, value)
+			}
+		}
+
+		if section == "ATTRIBUTES" && component != nil {
+			for _, flag := range strings.Fields(listcatFieldRegex.ReplaceAllString(text, "")) {
+				component.setFlag(flag)
+			}
+		}
+	}
+	return clusters
+}
+
+func (c *VsamCluster) setField(key string, value string) {
+	switch key {
+	case "IN-CAT":
+		c.Catalog = value
+	case "STORAGECLASS":
+		c.StorageClass = value
+	case "MANAGEMENTCLASS":
+		c.ManagementClass = value
+	case "DATACLASS":
+		c.DataClass = value
+	}
+}
+
+func (c *VsamComponent) setField(key string, value string) {
+	uints := map[string]*uint{
+		"KEYLEN":    &c.KeyLength,
+		"RKP":       &c.KeyOffset,
+		"AVGLRECL":  &c.AverageRecordLength,
+		"MAXLRECL":  &c.MaximumRecordLength,
+		"CISIZE":    &c.ControlIntervalSize,
+		"EXTENTS":   &c.Extents,
+		"SPACE-PRI": &c.PrimarySpace,
+		"SPACE-SEC": &c.SecondarySpace,
+	}
+	uint64s := map[string]*uint64{
+		"REC-TOTAL":     &c.Records,
+		"REC-DELETED":   &c.DeletedRecords,
+		"REC-INSERTED":  &c.InsertedRecords,
+		"REC-UPDATED":   &c.UpdatedRecords,
+		"REC-RETRIEVED": &c.RetrievedRecords,
+		"SPLITS-CI":     &c.CISplits,
+		"SPLITS-CA":     &c.CASplits,
+		"HI-A-RBA":      &c.HighAllocatedRba,
+		"HI-U-RBA":      &c.HighUsedRba,
+	}
+
+	if p, ok := uints[key]; ok {
+		if n, err := strconv.ParseUint(value, 10, 0); err == nil {
+			*p = uint(n)
+		}
+	} else if p, ok := uint64s[key]; ok {
+		if n, err := strconv.ParseUint(value, 10, 64); err == nil {
+			*p = n
+		}
+	} else if key == "SPACE-TYPE" {
+		c.SpaceType = value
+	} else if key == "VOLSER" && value != "" {
+		c.Volumes = append(c.Volumes, value)
+	}
+}
+
+func (c *VsamComponent) setFlag(flag string) {
+	if m := listcatShareRegex.FindStringSubmatch(flag); m != nil {
+		start, _ := strconv.ParseUint(m[1], 10, 0)
+		end, _ := strconv.ParseUint(m[2], 10, 0)
+		c.ShareOptions = Point{Start: uint(start), End: uint(end)}
+		return
+	}
+	switch flag {
+	case "INDEXED", "NONINDEXED", "NUMBERED", "LINEAR":
+		c.Organization = flag
+	default:
+		c.Attributes = append(c.Attributes, flag)
+	}
+}
@@ -0,0 +1,331 @@
+package zoau_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Stolkerve/zoau-go"
+	"github.com/Stolkerve/zoau-go/zoautest"
+)
+
+func TestVsam(t *testing.T) {
+	ctx := context.Background()
+	c := zoau.NewClient(&zoau.ClientArgs{Executor: zoautest.NewSimulator("USER")})
+
+	err := c.DefineCluster(ctx, "USER.CUSTOMER", &zoau.ClusterArgs{
+		Keys:              &zoau.KeyPoint{KeyLength: 4, KeyOffset: 0},
+		AverageRecordSize: 20,
+		MaximumRecordSize: 80,
+		PrimarySpace:      1,
+		SecondarySpace:    1,
+		SpaceUnit:         zoau.String(zoau.SPACE_UNIT_CYLINDERS),
+		ShareOptions:      &zoau.Point{Start: 2, End: 3},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DefineCluster(ctx, "USER.CUSTOMER", &zoau.ClusterArgs{Keys: &zoau.KeyPoint{KeyLength: 4}}); err == nil {
+		t.Fatal("Defining an existing cluster must fail")
+	}
+	if err := c.DefineCluster(ctx, "USER.OTHER", nil); err == nil {
+		t.Fatal("Defining a KSDS without keys must fail")
+	}
+
+	if _, err := c.Create(ctx, "USER.CUSTOMER.LOAD", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Write(ctx, "USER.CUSTOMER.LOAD", "0003 PARIS\n0001 LONDON\n0002 PARIS", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Repro(ctx, "USER.CUSTOMER.LOAD", "USER.CUSTOMER", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Repro(ctx, "USER.CUSTOMER.LOAD", "USER.CUSTOMER", nil); err == nil {
+		t.Fatal("Loading duplicate keys must fail without Replace")
+	}
+	if err := c.Repro(ctx, "USER.CUSTOMER.LOAD", "USER.CUSTOMER", &zoau.ReproArgs{Replace: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := c.PrintDataset(ctx, "USER.CUSTOMER", &zoau.PrintArgs{Format: zoau.String(zoau.PRINT_FORMAT_CHARACTER), FromKey: zoau.String("0002")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "KEY OF RECORD - 0002\n0002 PARIS\n") || strings.Contains(out, "LONDON") {
+		t.Fatalf("Unexpected listing %s", out)
+	}
+
+	if err := c.DefineAlternateIndex(ctx, "USER.CUSTOMER.CITY", "USER.CUSTOMER", &zoau.AlternateIndexArgs{Keys: &zoau.KeyPoint{KeyLength: 6, KeyOffset: 5}, Upgrade: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DefinePath(ctx, "USER.CUSTOMER.BYCITY", "USER.CUSTOMER.CITY", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.BuildIndex(ctx, "USER.CUSTOMER", "USER.CUSTOMER.CITY", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Create(ctx, "USER.CUSTOMER.UNLOAD", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Repro(ctx, "USER.CUSTOMER.BYCITY", "USER.CUSTOMER.UNLOAD", &zoau.ReproArgs{Count: zoau.Uint(2)}); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := c.Read(ctx, "USER.CUSTOMER.UNLOAD", nil); err != nil || out != "0001 LONDON\n0002 PARIS" {
+		t.Fatalf("expected: the records in city order, got %q, %v", out, err)
+	}
+
+	clusters, err := c.ListCatalog(ctx, "USER", &zoau.ListCatalogArgs{Level: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	types := []string{}
+	for _, cluster := range clusters {
+		types = append(types, cluster.Type+" "+cluster.Name+" "+cluster.Related)
+	}
+	expected := []string{"CLUSTER USER.CUSTOMER ", "PATH USER.CUSTOMER.BYCITY USER.CUSTOMER.CITY", "AIX USER.CUSTOMER.CITY USER.CUSTOMER"}
+	if !reflect.DeepEqual(types, expected) {
+		t.Fatalf("expected: %v, got %v", expected, types)
+	}
+
+	cluster := clusters[0]
+	if cluster.Data == nil || cluster.Index == nil {
+		t.Fatalf("Missing components in %+v", cluster)
+	}
+	data := cluster.Data
+	if data.Name != "USER.CUSTOMER.DATA" || data.Organization != "INDEXED" || data.KeyLength != 4 || data.KeyOffset != 0 ||
+		data.AverageRecordLength != 20 || data.MaximumRecordLength != 80 || data.Records != 3 || data.InsertedRecords != 3 ||
+		data.UpdatedRecords != 3 || data.ShareOptions != (zoau.Point{Start: 2, End: 3}) || data.SpaceType != "CYLINDER" ||
+		data.PrimarySpace != 1 || data.HighAllocatedRba != 737280 || !reflect.DeepEqual(data.Volumes, []string{"SIM001"}) {
+		t.Fatalf("Unexpected data component %+v", data)
+	}
+	if clusters[1].Data != nil {
+		t.Fatalf("Unexpected data component of a path %+v", clusters[1].Data)
+	}
+
+	if _, err := c.Delete(ctx, "USER.CUSTOMER"); err != nil {
+		t.Fatal(err)
+	}
+	if clusters, err := c.ListCatalog(ctx, "USER", &zoau.ListCatalogArgs{Level: true}); err != nil || len(clusters) != 0 {
+		t.Fatalf("expected: the alternate index and path deleted with the cluster, got %+v, %v", clusters, err)
+	}
+}
+
+func TestAlterCluster(t *testing.T) {
+	ctx := context.Background()
+	c := zoau.NewClient(&zoau.ClientArgs{Executor: zoautest.NewSimulator("USER")})
+	if err := c.DefineCluster(ctx, "USER.ORDERS", &zoau.ClusterArgs{Keys: &zoau.KeyPoint{KeyLength: 8}, MaximumRecordSize: 80, AverageRecordSize: 80}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DefineAlternateIndex(ctx, "USER.ORDERS.AIX", "USER.ORDERS", &zoau.AlternateIndexArgs{Keys: &zoau.KeyPoint{KeyLength: 6, KeyOffset: 8}, Upgrade: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.AlterCluster(ctx, "USER.ORDERS.DATA", &zoau.AlterClusterArgs{ShareOptions: &zoau.Point{Start: 3, End: 3}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.AlterCluster(ctx, "USER.ORDERS.AIX", &zoau.AlterClusterArgs{UniqueKey: zoau.Bool(true), Upgrade: zoau.Bool(false)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.AlterCluster(ctx, "USER.ORDERS", &zoau.AlterClusterArgs{NewName: zoau.String("USER.ORDERS2")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.AlterCluster(ctx, "USER.ORDERS2", &zoau.AlterClusterArgs{FreeSpace: &zoau.Point{Start: 10, End: 10}}); err == nil {
+		t.Fatal("FreeSpace must be rejected for a cluster entry")
+	}
+	if err := c.AlterCluster(ctx, "USER.ORDERS2", nil); err == nil {
+		t.Fatal("Altering nothing must be rejected")
+	}
+
+	clusters, err := c.ListCatalog(ctx, "USER", &zoau.ListCatalogArgs{Level: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(clusters) != 2 || clusters[0].Name != "USER.ORDERS.AIX" || clusters[0].Related != "USER.ORDERS2" || clusters[1].Name != "USER.ORDERS2" {
+		t.Fatalf("Unexpected entries %+v", clusters)
+	}
+	if data := clusters[1].Data; data == nil || data.ShareOptions != (zoau.Point{Start: 3, End: 3}) {
+		t.Fatalf("Unexpected data component %+v", data)
+	}
+}
+
+func TestIdcamsReproWarning(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeExecutor{results: map[string]zoau.Result{
+		"mvscmd": {Rc: 4, Stdout: "IDC3314I **RECORD X'F0F0F0F1' OUT OF SEQUENCE\nIDC0001I FUNCTION COMPLETED, HIGHEST CONDITION CODE WAS 4\n"},
+	}}
+	c := zoau.NewClient(&zoau.ClientArgs{Executor: fake})
+	var cmdErr *zoau.CommandError
+	if err := c.Repro(ctx, "USER.LOAD", "USER.KSDS", nil); !errors.As(err, &cmdErr) || cmdErr.Rc != 4 {
+		t.Fatalf("Condition code 4 of REPRO must fail, got %v", err)
+	}
+	if err := c.AlterCluster(ctx, "USER.KSDS", &zoau.AlterClusterArgs{Upgrade: zoau.Bool(true)}); err != nil {
+		t.Fatalf("Condition code 4 of ALTER must not fail, got %v", err)
+	}
+}
+
+func TestVsamDryRun(t *testing.T) {
+	plan := &zoau.Plan{}
+	c := zoau.NewClient(&zoau.ClientArgs{Executor: zoautest.NewSimulator("USER"), DryRun: plan})
+	ctx := context.Background()
+
+	err := c.DefineCluster(ctx, "USER.LOG", &zoau.ClusterArgs{
+		Type:              zoau.String(zoau.DS_ORG_ESDS),
+		MaximumRecordSize: 200,
+		AverageRecordSize: 100,
+		PrimarySpace:      10,
+		SecondarySpace:    5,
+		Volumes:           []string{"VOL001", "VOL002"},
+		DataName:          zoau.String("USER.LOG.D"),
+		IndexName:         zoau.String("USER.LOG.I"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Repro(ctx, "USER.LOG", "USER.LOG.BACKUP", &zoau.ReproArgs{FromKey: zoau.String("O'NEIL"), Skip: zoau.Uint(1)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.ListCatalog(ctx, "USER", &zoau.ListCatalogArgs{Level: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "mvscmd --pgm=IDCAMS '--sysprint=*' --sysin=stdin <<'EOF'\n" +
+		" DEFINE CLUSTER ( -\n" +
+		"   NAME(USER.LOG) -\n" +
+		"   NONINDEXED -\n" +
+		"   RECORDSIZE(100 200) -\n" +
+		"   TRACKS(10 5) -\n" +
+		"   VOLUMES(VOL001 VOL002) -\n" +
+		"   ) -\n" +
+		"   DATA ( -\n" +
+		"   NAME(USER.LOG.D) -\n" +
+		"   )\n" +
+		"EOF\n" +
+		"mvscmd --pgm=IDCAMS '--sysprint=*' --sysin=stdin <<'EOF'\n" +
+		" REPRO -\n" +
+		"   INDATASET(USER.LOG) -\n" +
+		"   OUTDATASET(USER.LOG.BACKUP) -\n" +
+		"   FROMKEY('O''NEIL') -\n" +
+		"   SKIP(1)\n" +
+		"EOF\n"
+	if plan.String() != expected {
+		t.Fatalf("expected: %s, got %s", expected, plan.String())
+	}
+}
+
+func TestParseListCatalog(t *testing.T) {
+	listing := `IDCAMS  SYSTEM SERVICES                                           TIME: 10:12:31
+
+  LISTCAT ENTRIES(PROD.ORDERS) ALL
+CLUSTER ------- PROD.ORDERS
+     IN-CAT --- CATALOG.PROD.UCAT
+     HISTORY
+       DATASET-OWNER-----(NULL)     CREATION--------2024.015
+       RELEASE----------------2     EXPIRATION------0000.000
+     SMSDATA
+       STORAGECLASS ----STANDARD     MANAGEMENTCLASS---(NULL)
+       DATACLASS --------(NULL)     LBACKUP ---0000.000.0000
+     RLSDATA
+       LOG ----------------(NULL)     RECOVERY REQUIRED --(NO)     FRLOG -------------(NULL)
+     ASSOCIATIONS
+       DATA-----PROD.ORDERS.DATA
+       INDEX----PROD.ORDERS.INDEX
+   DATA ------- PROD.ORDERS.DATA
+     IN-CAT --- CATALOG.PROD.UCAT
+     HISTORY
+       DATASET-OWNER-----(NULL)     CREATION--------2024.015
+     ASSOCIATIONS
+       CLUSTER--PROD.ORDERS
+     ATTRIBUTES
+       KEYLEN----------------12     AVGLRECL-------------250     BUFSPACE-----------37376     CISIZE-------------18432
+       RKP--------------------0     MAXLRECL------------1000     EXCPEXIT----------(NULL)     CI/CA-------------------30
+       SHROPTNS(2,3)   RECOVERY   UNIQUE   NOERASE   INDEXED   NOWRITECHK   NOIMBED   NOREPLICAT
+       UNORDERED   NOREUSE   NONSPANNED
+     STATISTICS
+       REC-TOTAL-----------15230     SPLITS-CI-------------12     EXCPS---------------4521
+       REC-DELETED-------------7     SPLITS-CA--------------1     EXTENTS----------------2
+       REC-INSERTED----------140     FREESPACE-%CI---------10     SYSTEM-TIMESTAMP:
+       REC-UPDATED-----------311     FREESPACE-%CA---------10          X'DC4F1B2A31E40000'
+       REC-RETRIEVED-------98231     FREESPC----------1474560
+     ALLOCATION
+       SPACE-TYPE------CYLINDER     HI-A-RBA--------11059200
+       SPACE-PRI-------------10     HI-U-RBA---------9584640
+       SPACE-SEC--------------5
+     VOLUME
+       VOLSER------------PRD001     PHYREC-SIZE--------18432     HI-A-RBA--------11059200     EXTENT-NUMBER----------2
+       DEVTYPE------X'3010200F'     PHYRECS/TRK------------3     HI-U-RBA---------9584640     EXTENT-TYPE--------X'40'
+   INDEX ------ PROD.ORDERS.INDEX
+     IN-CAT --- CATALOG.PROD.UCAT
+     ATTRIBUTES
+       KEYLEN----------------12     AVGLRECL---------------0     BUFSPACE---------------0     CISIZE--------------2048
+       RKP--------------------0     MAXLRECL------------2041     EXCPEXIT----------(NULL)     CI/CA-------------------23
+       SHROPTNS(2,3)   RECOVERY   UNIQUE   NOERASE   NOWRITECHK   NOIMBED   NOREPLICAT   UNORDERED
+     STATISTICS
+       REC-TOTAL--------------9     SPLITS-CI--------------0     EXCPS-----------------88
+     VOLUME
+       VOLSER------------PRD001     PHYREC-SIZE---------2048
+NONVSAM ------- PROD.ORDERS.EXPORT
+     IN-CAT --- CATALOG.PROD.UCAT
+     VOLUMES
+       VOLSER------------PRD002     DEVTYPE------X'3010200F'
+IDCAMS  SYSTEM SERVICES                                           TIME: 10:12:31
+
+         THE NUMBER OF ENTRIES PROCESSED WAS:
+                 AIX -------------------0
+                 CLUSTER ---------------1
+                 DATA ------------------1
+                 INDEX -----------------1
+                 NONVSAM ---------------1
+                 TOTAL -----------------4
+IDC0001I FUNCTION COMPLETED, HIGHEST CONDITION CODE WAS 0
+`
+
+	clusters := zoau.ParseListCatalog(listing)
+	if len(clusters) != 1 {
+		t.Fatalf("expected: 1 cluster, got %+v", clusters)
+	}
+	cluster := clusters[0]
+	if cluster.Name != "PROD.ORDERS" || cluster.Type != "CLUSTER" || cluster.Catalog != "CATALOG.PROD.UCAT" ||
+		cluster.StorageClass != "STANDARD" || cluster.ManagementClass != "" || cluster.Fields["CREATION"] != "2024.015" {
+		t.Fatalf("Unexpected cluster %+v", cluster)
+	}
+
+	expected := zoau.VsamComponent{
+		Name:                "PROD.ORDERS.DATA",
+		Organization:        "INDEXED",
+		KeyLength:           12,
+		AverageRecordLength: 250,
+		MaximumRecordLength: 1000,
+		ControlIntervalSize: 18432,
+		ShareOptions:        zoau.Point{Start: 2, End: 3},
+		Attributes:          []string{"RECOVERY", "UNIQUE", "NOERASE", "NOWRITECHK", "NOIMBED", "NOREPLICAT", "UNORDERED", "NOREUSE", "NONSPANNED"},
+		Records:             15230,
+		DeletedRecords:      7,
+		InsertedRecords:     140,
+		UpdatedRecords:      311,
+		RetrievedRecords:    98231,
+		CISplits:            12,
+		CASplits:            1,
+		Extents:             2,
+		SpaceType:           "CYLINDER",
+		PrimarySpace:        10,
+		SecondarySpace:      5,
+		HighAllocatedRba:    11059200,
+		HighUsedRba:         9584640,
+		Volumes:             []string{"PRD001"},
+	}
+	data := *cluster.Data
+	if data.Fields["FREESPACE-%CI"] != "10" || data.Fields["CI/CA"] != "30" || data.Fields["EXCPEXIT"] != "" {
+		t.Fatalf("Unexpected fields %v", data.Fields)
+	}
+	data.Fields = nil
+	if !reflect.DeepEqual(data, expected) {
+		t.Fatalf("expected: %+v, got %+v", expected, data)
+	}
+
+	if cluster.Index == nil || cluster.Index.Name != "PROD.ORDERS.INDEX" || cluster.Index.Records != 9 || cluster.Index.ControlIntervalSize != 2048 {
+		t.Fatalf("Unexpected index component %+v", cluster.Index)
+	}
+}
@@ -331,7 +331,7 @@ func TestCompare(t *testing.T) {
 	if res, err := zoau.Compare(ds1, ds2, nil); err != nil {
 		t.Fatalf("Fail to compere %s to %s", ds2, ds1)
 	} else {
-		if res == nil {
+		if res.Equal {
 			t.Fatalf("%s and %s must not be equals", ds2, ds1)
 		}
 	}
@@ -345,8 +345,8 @@ func TestCompare(t *testing.T) {
 	if res, err := zoau.Compare(ds1, ds2, &zoau.CompareArgs{IgnoreCase: true}); err != nil {
 		t.Fatalf("Fail to compere %s to %s", ds2, ds1)
 	} else {
-		if res != nil {
-			t.Log(*res)
+		if !res.Equal {
+			t.Log(res.Listing)
 			t.Fatalf("%s and %s must be equals", ds2, ds1)
 		}
 	}
@@ -355,7 +355,7 @@ func TestCompare(t *testing.T) {
 	if res, err := zoau.Compare(ds1, ds2, &zoau.CompareArgs{IgnoreCase: false}); err != nil {
 		t.Fatalf("Fail to compere %s to %s", ds2, ds1)
 	} else {
-		if res == nil {
+		if res.Equal {
 			t.Fatalf("%s and %s must not be equals", ds2, ds1)
 		}
 	}
@@ -366,8 +366,8 @@ func TestCompare(t *testing.T) {
 	}, IgnoreCase: true}); err != nil {
 		t.Fatalf("Fail to compere %s to %s", ds2, ds1)
 	} else {
-		if res != nil {
-			t.Log(*res)
+		if !res.Equal {
+			t.Log(res.Listing)
 			t.Fatalf("%s and %s must be equals", ds2, ds1)
 		}
 	}
@@ -401,12 +401,12 @@ func TestFindReplace(t *testing.T) {
 	}
 
 	t.Log("FindReplace")
-	if err := zoau.FindReplace(ds, "line.", "LINE"); err != nil {
+	if _, err := zoau.FindReplace(ds, "line.", "LINE", nil); err != nil {
 		t.Fatalf("Fail to find and replace. Err %s", err)
 	}
 
 	t.Log("FindReplace")
-	if err := zoau.FindReplace(ds, "This is", "This was"); err != nil {
+	if _, err := zoau.FindReplace(ds, "This is", "This was", nil); err != nil {
 		t.Fatalf("Fail to find and replace. Err %s", err)
 	}
 
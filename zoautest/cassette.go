@@ -0,0 +1,290 @@
+package zoautest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Stolkerve/zoau-go"
+)
+
+// Interaction is a recorded invocation of a ZOAU utility.
+type Interaction struct {
+	Command string   `json:"command"`
+	Args    []string `json:"args"`
+	Stdin   string   `json:"stdin,omitempty"`
+	Env     []string `json:"env,omitempty"`
+	Stdout  string   `json:"stdout"`
+	Stderr  string   `json:"stderr"`
+	Rc      int      `json:"rc"`
+
+	// Error returned by the executor when the utility could not be run.
+	Error string `json:"error,omitempty"`
+}
+
+func (i Interaction) String() string {
+	return strings.TrimSpace(i.Command + " " + strings.Join(i.Args, " "))
+}
+
+// Cassette is an ordered transcript of ZOAU invocations.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// LoadCassette reads a cassette saved with Cassette.Save.
+func LoadCassette(path string) (*Cassette, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &Cassette{}
+	if err := json.Unmarshal(content, c); err != nil {
+		return nil, fmt.Errorf("zoautest: invalid cassette %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Save writes the cassette to path as indented JSON.
+func (c *Cassette) Save(path string) error {
+	content, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(content, '\n'), 0o644)
+}
+
+// Redaction replaces every occurrence of Value delimited by word boundaries (e.g. a user ID or
+// an HLQ qualifier) with Placeholder in the recorded interactions.
+type Redaction struct {
+	Value       string
+	Placeholder string
+}
+
+type redactor []struct {
+	re          *regexp.Regexp
+	placeholder string
+}
+
+func newRedactor(redactions []Redaction) redactor {
+	r := make(redactor, 0, len(redactions))
+	for _, red := range redactions {
+		if red.Value == "" {
+			continue
+		}
+		r = append(r, struct {
+			re          *regexp.Regexp
+			placeholder string
+		}{regexp.MustCompile(`(?i)\b` + regexp.QuoteMeta(red.Value) + `\b`), red.Placeholder})
+	}
+	return r
+}
+
+func (r redactor) string(s string) string {
+	for _, red := range r {
+		s = red.re.ReplaceAllLiteralString(s, red.placeholder)
+	}
+	return s
+}
+
+func (r redactor) strings(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = r.string(v)
+	}
+	return out
+}
+
+// Recorder is a zoau.Executor that runs the commands with another executor and records every
+// invocation into a cassette.
+type Recorder struct {
+	mu       sync.Mutex
+	next     zoau.Executor
+	redactor redactor
+	cassette Cassette
+}
+
+// NewRecorder returns a Recorder running the commands with next. The redactions are applied to
+// the recorded interactions only; next receives the commands unchanged.
+func NewRecorder(next zoau.Executor, redactions []Redaction) *Recorder {
+	return &Recorder{next: next, redactor: newRedactor(redactions)}
+}
+
+func (r *Recorder) Run(ctx context.Context, cmd zoau.Command) (zoau.Result, error) {
+	stdin := ""
+	if cmd.Stdin != nil {
+		content, err := io.ReadAll(cmd.Stdin)
+		if err != nil {
+			return zoau.Result{Rc: -1}, err
+		}
+		stdin = string(content)
+		cmd.Stdin = bytes.NewReader(content)
+	}
+
+	res, err := r.next.Run(ctx, cmd)
+
+	interaction := Interaction{
+		Command: cmd.Name,
+		Args:    stagedFiles(r.redactor.strings(cmd.Args)),
+		Stdin:   r.redactor.string(stdin),
+		Env:     r.redactor.strings(cmd.Env),
+		Stdout:  r.redactor.string(res.Stdout),
+		Stderr:  r.redactor.string(res.Stderr),
+		Rc:      res.Rc,
+	}
+	if err != nil {
+		interaction.Error = r.redactor.string(err.Error())
+	}
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.mu.Unlock()
+	return res, err
+}
+
+// Cassette returns a copy of the interactions recorded so far.
+func (r *Recorder) Cassette() *Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &Cassette{Interactions: append([]Interaction{}, r.cassette.Interactions...)}
+}
+
+// Replayer is a zoau.Executor that answers the commands from a cassette. The commands must arrive
+// in the recorded order with the recorded arguments, standard input and environment; any other
+// command fails.
+type Replayer struct {
+	mu       sync.Mutex
+	t        testing.TB
+	cassette *Cassette
+	next     int
+	failed   bool
+}
+
+// NewReplayer returns a Replayer for the cassette. If t is not nil, unmatched calls are reported
+// with t.Errorf and a test cleanup reports the interactions that were not replayed.
+func NewReplayer(t testing.TB, cassette *Cassette) *Replayer {
+	r := &Replayer{t: t, cassette: cassette}
+	if t != nil {
+		t.Helper()
+		t.Cleanup(func() {
+			if err := r.Verify(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	return r
+}
+
+func (r *Replayer) Run(ctx context.Context, cmd zoau.Command) (zoau.Result, error) {
+	if err := ctx.Err(); err != nil {
+		return zoau.Result{Rc: -1}, fmt.Errorf("%s: %w", cmd.Name, err)
+	}
+	stdin := ""
+	if cmd.Stdin != nil {
+		content, err := io.ReadAll(cmd.Stdin)
+		if err != nil {
+			return zoau.Result{Rc: -1}, err
+		}
+		stdin = string(content)
+	}
+	got := Interaction{Command: cmd.Name, Args: stagedFiles(cmd.Args), Stdin: stdin, Env: cmd.Env}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.next >= len(r.cassette.Interactions) {
+		return zoau.Result{Rc: -1}, r.fail("unexpected call %q after the %d recorded interactions", got, len(r.cassette.Interactions))
+	}
+	want := r.cassette.Interactions[r.next]
+	if !sameCall(got, want) {
+		for i := r.next + 1; i < len(r.cassette.Interactions); i++ {
+			if sameCall(got, r.cassette.Interactions[i]) {
+				return zoau.Result{Rc: -1}, r.fail("out of order call %q: interaction #%d is %q, the call was recorded as interaction #%d", got, r.next+1, want, i+1)
+			}
+		}
+		return zoau.Result{Rc: -1}, r.fail("unmatched call %q: interaction #%d is %q", got, r.next+1, want)
+	}
+	r.next++
+
+	res := zoau.Result{Stdout: want.Stdout, Stderr: want.Stderr, Rc: want.Rc}
+	if want.Error != "" {
+		return res, errors.New(want.Error)
+	}
+	return res, nil
+}
+
+// Temporary HFS files staged by zoau.OpenWriter, whose names differ on every run.
+var stagedFileRegex = regexp.MustCompile(`(?:/[^\s,'"/]+)*/zoau-[0-9]+`)
+
+// stagedFiles replaces the names of the staged files in args by a fixed placeholder.
+func stagedFiles(args []string) []string {
+	if args == nil {
+		return nil
+	}
+	out := make([]string, len(args))
+	for i, arg := range args {
+		out[i] = stagedFileRegex.ReplaceAllString(arg, "$$STAGED")
+	}
+	return out
+}
+
+func sameCall(got Interaction, want Interaction) bool {
+	return got.Command == want.Command &&
+		reflect.DeepEqual(append([]string{}, got.Args...), append([]string{}, want.Args...)) &&
+		got.Stdin == want.Stdin &&
+		reflect.DeepEqual(append([]string{}, got.Env...), append([]string{}, want.Env...))
+}
+
+func (r *Replayer) fail(format string, a ...any) error {
+	err := fmt.Errorf("zoautest: "+format, a...)
+	r.failed = true
+	if r.t != nil {
+		r.t.Errorf("%v", err)
+	}
+	return err
+}
+
+// Verify returns an error if a call did not match the cassette or if some interactions were not replayed.
+func (r *Replayer) Verify() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failed {
+		return errors.New("zoautest: the calls did not match the cassette")
+	}
+	if remaining := len(r.cassette.Interactions) - r.next; remaining > 0 {
+		return fmt.Errorf("zoautest: %d interactions were not replayed, the first one is %q", remaining, r.cassette.Interactions[r.next])
+	}
+	return nil
+}
+
+// UseCassette returns an executor bound to the cassette at path. When the ZOAU_RECORD environment
+// variable is set, the commands run with zoau.ExecExecutor and the cassette is written at the end of
+// the test; otherwise the cassette is replayed.
+func UseCassette(t testing.TB, path string, redactions []Redaction) zoau.Executor {
+	t.Helper()
+	if os.Getenv("ZOAU_RECORD") != "" {
+		recorder := NewRecorder(zoau.ExecExecutor{}, redactions)
+		t.Cleanup(func() {
+			if err := recorder.Cassette().Save(path); err != nil {
+				t.Errorf("zoautest: unable to save the cassette: %v", err)
+			}
+		})
+		return recorder
+	}
+
+	cassette, err := LoadCassette(path)
+	if err != nil {
+		t.Fatalf("zoautest: %v", err)
+	}
+	return NewReplayer(t, cassette)
+}
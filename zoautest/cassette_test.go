@@ -0,0 +1,101 @@
+package zoautest_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Stolkerve/zoau-go"
+	"github.com/Stolkerve/zoau-go/zoautest"
+)
+
+// recordingTB captures the errors reported by a Replayer.
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingTB) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, format)
+}
+
+func (r *recordingTB) Cleanup(func()) {}
+
+func TestRecordReplay(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "crud.json")
+
+	recorder := zoautest.NewRecorder(zoautest.NewSimulator("Z38816"), []zoautest.Redaction{{Value: "Z38816", Placeholder: "USER"}})
+	client := zoau.NewClient(&zoau.ClientArgs{Executor: recorder})
+	hlq, err := client.Hlq(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Create(ctx, hlq+".ZOAU1", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.Write(ctx, hlq+".ZOAU1", "Michurao", false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Read(ctx, hlq+".ZOAU2", nil); err == nil {
+		t.Fatal("Reading a missing dataset must fail")
+	}
+	if err := recorder.Cassette().Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	cassette, err := zoautest.LoadCassette(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, interaction := range cassette.Interactions {
+		if strings.Contains(interaction.String()+interaction.Stdout+interaction.Stderr, "Z38816") {
+			t.Fatalf("Interaction %q was not redacted", interaction)
+		}
+	}
+
+	client = zoau.NewClient(&zoau.ClientArgs{Executor: zoautest.NewReplayer(t, cassette)})
+	if hlq, err = client.Hlq(ctx); err != nil || hlq != "USER" {
+		t.Fatalf("expected: USER, <nil>, got %s, %v", hlq, err)
+	}
+	if ds, err := client.Create(ctx, "USER.ZOAU1", nil); err != nil || ds.Name != "USER.ZOAU1" {
+		t.Fatalf("expected: USER.ZOAU1, <nil>, got %v, %v", ds, err)
+	}
+	if err := client.Write(ctx, "USER.ZOAU1", "Michurao", false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Read(ctx, "USER.ZOAU2", nil); err == nil || !strings.Contains(err.Error(), "USER.ZOAU2 does not exist") {
+		t.Fatalf("Unexpected error %v", err)
+	}
+}
+
+func TestReplayMismatch(t *testing.T) {
+	ctx := context.Background()
+	cassette := &zoautest.Cassette{Interactions: []zoautest.Interaction{
+		{Command: "hlq", Stdout: "USER\n"},
+		{Command: "mvstmp", Args: []string{"USER"}, Stdout: "USER.P0000001.T0000001.C0000001\n"},
+	}}
+
+	tb := &recordingTB{TB: t}
+	replayer := zoautest.NewReplayer(tb, cassette)
+	client := zoau.NewClient(&zoau.ClientArgs{Executor: replayer})
+	if _, err := client.TmpName(ctx, zoau.String("USER")); err == nil || !strings.Contains(err.Error(), "out of order") {
+		t.Fatalf("expected an out of order error, got %v", err)
+	}
+	if len(tb.errors) != 1 {
+		t.Fatalf("The mismatch must be reported to the test, got %v", tb.errors)
+	}
+
+	replayer = zoautest.NewReplayer(nil, cassette)
+	client = zoau.NewClient(&zoau.ClientArgs{Executor: replayer})
+	if _, err := client.Hlq(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := replayer.Verify(); err == nil || !strings.Contains(err.Error(), "1 interactions were not replayed") {
+		t.Fatalf("expected an error on the remaining interaction, got %v", err)
+	}
+	if _, err := client.Delete(ctx, "USER.ZOAU1"); err == nil || !strings.Contains(err.Error(), "unmatched call") {
+		t.Fatalf("expected an unmatched call error, got %v", err)
+	}
+}
@@ -0,0 +1,462 @@
+package zoautest
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Stolkerve/zoau-go"
+)
+
+// dls [-l] [-u] [-s] [-b] [-m] pattern...
+func (s *Simulator) dls(args []string) zoau.Result {
+	options, patterns, err := parseOptions(args, "")
+	if err != nil {
+		return failure(8, "BGYSC1001E", "%v.", err)
+	}
+	_, long := options['l']
+
+	var out strings.Builder
+	found := false
+	for _, pattern := range patterns {
+		for _, ds := range s.matchDatasets(pattern) {
+			found = true
+			if !long {
+				fmt.Fprintln(&out, ds.Name)
+				continue
+			}
+			used := ds.used()
+			fmt.Fprintf(&out, "%-44s %s %-3s %-4s %5d %5d %-6s %10d %12d\n",
+				ds.Name,
+				ds.Referenced.Format("2006/01/02"),
+				strings.ToLower(ds.Dsorg),
+				ds.Recfm,
+				ds.Lrecl,
+				ds.BlockSize,
+				ds.Volume,
+				used,
+				max(used, ds.Space),
+			)
+		}
+	}
+	if !found {
+		return failure(1, "BGYSC1103E", "No datasets match pattern: %s.", strings.Join(patterns, " "))
+	}
+	return success(out.String())
+}
+
+func (d *dataset) used() int {
+	records := d.Records
+	if d.partitioned() {
+		records = nil
+		for _, member := range d.Members {
+			records = append(records, member...)
+		}
+	}
+	size := 0
+	for _, r := range records {
+		if strings.HasPrefix(d.Recfm, "F") {
+			size += d.Lrecl
+		} else {
+			size += len(r) + 4
+		}
+	}
+	return size
+}
+
+// Default record lengths and block sizes by record format, as documented for dtouch.
+var recordDefaults = map[string][2]int{
+	"F":   {80, 80},
+	"FB":  {80, 32720},
+	"FBS": {80, 32720},
+	"FBA": {133, 32718},
+	"VB":  {137, 32760},
+	"VBA": {137, 32743},
+	"VBS": {137, 32760},
+	"U":   {0, 32760},
+}
+
+// dtouch [-t type] [-s primary] [-e secondary] [-b dirblks] [-B blksize] [-r recfm] [-l lrecl] ... name
+func (s *Simulator) dtouch(args []string) zoau.Result {
+	options, operands, err := parseOptions(args, "tseblBrcDkVm")
+	if err != nil {
+		return failure(8, "BGYSC2001E", "%v.", err)
+	}
+	if len(operands) != 1 {
+		return failure(8, "BGYSC2001E", "Usage: dtouch [options] dataset.")
+	}
+	name, _ := splitName(operands[0])
+	if ds, ok := s.datasets[name]; ok {
+		ds.Referenced = time.Now()
+		return success("")
+	}
+
+	dsType := "SEQ"
+	if v, ok := lastOption(options, 't'); ok {
+		dsType = strings.ToUpper(v)
+	}
+	recfm := "FB"
+	if v, ok := lastOption(options, 'r'); ok {
+		recfm = strings.ToUpper(v)
+	}
+	defaults, ok := recordDefaults[recfm]
+	if !ok {
+		return failure(8, "BGYSC2002E", "Invalid record format %s.", recfm)
+	}
+	lrecl, blockSize := defaults[0], defaults[1]
+	if v, ok := lastOption(options, 'l'); ok {
+		if lrecl, err = strconv.Atoi(v); err != nil {
+			return failure(8, "BGYSC2003E", "Invalid record length %s.", v)
+		}
+		if strings.HasPrefix(recfm, "F") && blockSize > lrecl && lrecl > 0 {
+			blockSize = blockSize / lrecl * lrecl
+		}
+	}
+	if v, ok := lastOption(options, 'B'); ok {
+		if blockSize, err = strconv.Atoi(v); err != nil {
+			return failure(8, "BGYSC2004E", "Invalid block size %s.", v)
+		}
+	}
+	space := 5 * 1024 * 1024
+	if v, ok := lastOption(options, 's'); ok {
+		if space, err = parseSpace(v); err != nil {
+			return failure(8, "BGYSC2005E", "Invalid space %s.", v)
+		}
+	}
+	volume := s.volume
+	if v, ok := lastOption(options, 'V'); ok {
+		volume = strings.ToUpper(strings.Split(v, ",")[0])
+	}
+
+	ds := &dataset{
+		Name:       name,
+		Recfm:      recfm,
+		Lrecl:      lrecl,
+		BlockSize:  blockSize,
+		Volume:     volume,
+		Space:      space,
+		Referenced: time.Now(),
+	}
+	switch dsType {
+	case zoau.DS_ORG_SEQ, zoau.DS_ORG_LARGE:
+		ds.Dsorg = "PS"
+		ds.Records = []string{}
+	case zoau.DS_ORG_PDS, zoau.DS_ORG_PDSE:
+		ds.Dsorg = "PO"
+		ds.Members = make(map[string][]string)
+	case zoau.DS_ORG_KSDS, zoau.DS_ORG_ESDS, zoau.DS_ORG_RRDS, zoau.DS_ORG_LDS:
+		ds.Dsorg = "VS"
+		ds.Recfm = "??"
+		ds.Records = []string{}
+		ds.Vsam = newVsamCluster(name, vsamOrganizations[dsType])
+		if v, ok := lastOption(options, 'k'); ok {
+			length, offset, _ := strings.Cut(v, ":")
+			ds.Vsam.KeyLength = atoiDefault(length, ds.Vsam.KeyLength)
+			ds.Vsam.KeyOffset = atoiDefault(offset, 0)
+		}
+		if _, ok := lastOption(options, 'l'); ok {
+			ds.Vsam.MaximumRecordSize = lrecl
+			ds.Vsam.AverageRecordSize = lrecl
+		}
+	default:
+		return failure(8, "BGYSC2006E", "Invalid dataset type %s.", dsType)
+	}
+	s.catalog(ds)
+	return success("")
+}
+
+// parseSpace converts a dtouch space specification (e.g. 10, 5M, 2CYL) to bytes.
+func parseSpace(v string) (int, error) {
+	units := []struct {
+		Suffix string
+		Bytes  int
+	}{{"CYL", 849960}, {"TRK", 56664}, {"K", 1024}, {"M", 1024 * 1024}, {"G", 1024 * 1024 * 1024}, {"", 1}}
+	v = strings.ToUpper(v)
+	for _, u := range units {
+		if strings.HasSuffix(v, u.Suffix) {
+			n, err := strconv.Atoi(strings.TrimSuffix(v, u.Suffix))
+			return n * u.Bytes, err
+		}
+	}
+	return 0, nil
+}
+
+// drm pattern...
+func (s *Simulator) drm(args []string) zoau.Result {
+	_, patterns, err := parseOptions(args, "")
+	if err != nil {
+		return failure(8, "BGYSC1101E", "%v.", err)
+	}
+	deleted := 0
+	for _, pattern := range patterns {
+		for _, ds := range s.matchDatasets(pattern) {
+			for _, dependent := range s.dependents(ds.Name) {
+				delete(s.datasets, dependent.Name)
+			}
+			delete(s.datasets, ds.Name)
+			deleted++
+		}
+	}
+	if deleted == 0 {
+		return failure(1, "BGYSC1103E", "No datasets match pattern: %s.", strings.Join(patterns, " "))
+	}
+	return success("")
+}
+
+// decho [-a] content dataset
+func (s *Simulator) decho(args []string) zoau.Result {
+	options, operands, err := parseOptions(args, "")
+	if err != nil {
+		return failure(8, "BGYSC1301E", "%v.", err)
+	}
+	if len(operands) != 2 {
+		return failure(8, "BGYSC1301E", "Usage: decho [-a] string dataset.")
+	}
+	content, target := operands[0], operands[1]
+
+	name, _ := splitName(target)
+	if _, ok := s.datasets[name]; !ok {
+		if res := s.dtouch([]string{name}); res.Rc != 0 {
+			return res
+		}
+	}
+
+	records := splitRecords(content)
+	if _, ok := options['a']; ok {
+		if current, _, _, ok := s.readSource(target); ok {
+			records = append(append([]string{}, current...), records...)
+		}
+	}
+	if res, ok := s.writeTarget(target, records); !ok {
+		return res
+	}
+	return success("")
+}
+
+// dtail [-n [+|-]lines] dataset
+func (s *Simulator) dtail(args []string) zoau.Result {
+	options, operands, err := parseOptions(args, "n")
+	if err != nil {
+		return failure(8, "BGYSC1401E", "%v.", err)
+	}
+	if len(operands) != 1 {
+		return failure(8, "BGYSC1401E", "Usage: dtail [-n lines] dataset.")
+	}
+	records, _, res, ok := s.readSource(operands[0])
+	if !ok {
+		return res
+	}
+
+	lines := "10"
+	if v, ok := lastOption(options, 'n'); ok {
+		lines = v
+	}
+	n, err := strconv.Atoi(strings.TrimLeft(lines, "+-"))
+	if err != nil {
+		return failure(8, "BGYSC1402E", "Invalid line count %s.", lines)
+	}
+	if strings.HasPrefix(lines, "+") {
+		records = records[min(max(n-1, 0), len(records)):]
+	} else {
+		records = records[max(len(records)-n, 0):]
+	}
+	return success(joinRecords(records))
+}
+
+// dcp [-f] [-I] [-X] [-B] [-T] source target
+func (s *Simulator) dcp(args []string) zoau.Result {
+	options, operands, err := parseOptions(args, "")
+	if err != nil {
+		return failure(8, "BGYSC1601E", "%v.", err)
+	}
+	if len(operands) != 2 {
+		return failure(8, "BGYSC1601E", "Usage: dcp [options] source target.")
+	}
+	source, target := operands[0], operands[1]
+
+	if !isPath(source) {
+		if name, member := splitName(source); member == "" {
+			if ds, ok := s.datasets[name]; ok && ds.partitioned() {
+				return s.copyPartitioned(ds, target)
+			}
+		}
+	}
+
+	records, from, res, ok := s.readSource(source)
+	if !ok {
+		return res
+	}
+	records = append([]string{}, records...)
+
+	if isPath(target) {
+		content := joinRecords(records)
+		if _, binary := options['B']; binary && !isPath(source) {
+			// The records of a dataset are copied as they are, without new lines.
+			content = strings.Join(records, "")
+		}
+		if err := os.WriteFile(target, []byte(content), 0o644); err != nil {
+			return failure(8, "BGYSC1702E", "Unable to write file %s: %v.", target, err)
+		}
+		return success("")
+	}
+
+	name, member := splitName(target)
+	if _, ok := s.datasets[name]; !ok {
+		created := s.allocateLike(name, from, member != "")
+		if isPath(source) {
+			for _, r := range records {
+				created.Lrecl = max(created.Lrecl, len(r))
+			}
+		}
+	}
+	if res, ok := s.writeTarget(target, records); !ok {
+		return res
+	}
+	return success("")
+}
+
+func (s *Simulator) copyPartitioned(from *dataset, target string) zoau.Result {
+	name, _ := splitName(target)
+	to, ok := s.datasets[name]
+	if !ok {
+		to = s.allocateLike(name, from, true)
+	}
+	if !to.partitioned() {
+		return failure(8, "BGYSC1503E", "Dataset %s is not partitioned.", name)
+	}
+	for member, records := range from.Members {
+		to.Members[member] = append([]string{}, records...)
+	}
+	return success("")
+}
+
+// allocateLike catalogs a new dataset with the attributes of like, or default ones if like is nil.
+func (s *Simulator) allocateLike(name string, like *dataset, partitioned bool) *dataset {
+	ds := &dataset{
+		Name:       name,
+		Dsorg:      "PS",
+		Recfm:      "FB",
+		Lrecl:      80,
+		BlockSize:  32720,
+		Volume:     s.volume,
+		Space:      5 * 1024 * 1024,
+		Referenced: time.Now(),
+		Records:    []string{},
+	}
+	if like != nil {
+		ds.Recfm, ds.Lrecl, ds.BlockSize, ds.Space = like.Recfm, like.Lrecl, like.BlockSize, like.Space
+	}
+	if partitioned {
+		ds.Dsorg = "PO"
+		ds.Records = nil
+		ds.Members = make(map[string][]string)
+	}
+	s.catalog(ds)
+	return ds
+}
+
+// dmv source target
+func (s *Simulator) dmv(args []string) zoau.Result {
+	_, operands, err := parseOptions(args, "")
+	if err != nil || len(operands) != 2 {
+		return failure(8, "BGYSC1801E", "Usage: dmv source target.")
+	}
+	source, _ := splitName(operands[0])
+	target, _ := splitName(operands[1])
+	ds, ok := s.datasets[source]
+	if !ok {
+		return failure(8, "BGYSC1501E", "Dataset %s does not exist.", source)
+	}
+	if _, ok := s.datasets[target]; ok {
+		return failure(8, "BGYSC1802E", "Dataset %s already exists.", target)
+	}
+	delete(s.datasets, source)
+	ds.Name = target
+	s.catalog(ds)
+	return success("")
+}
+
+// partitionedMembers returns the dataset of a "DSN(pattern)" reference and its members matching the pattern.
+func (s *Simulator) partitionedMembers(ref string) (*dataset, []string, zoau.Result, bool) {
+	name, pattern := splitName(ref)
+	if pattern == "" {
+		pattern = "*"
+	}
+	ds, ok := s.datasets[name]
+	if !ok {
+		return nil, nil, failure(8, "BGYSC1501E", "Dataset %s does not exist.", name), false
+	}
+	if !ds.partitioned() {
+		return nil, nil, failure(8, "BGYSC1503E", "Dataset %s is not partitioned.", name), false
+	}
+	compiled, err := zoau.CompileMemberPattern(pattern)
+	if err != nil {
+		return nil, nil, failure(8, "BGYSC1505E", "Invalid member pattern %s.", pattern), false
+	}
+	members := make([]string, 0)
+	for member := range ds.Members {
+		if compiled.Match(member) {
+			members = append(members, member)
+		}
+	}
+	sort.Strings(members)
+	return ds, members, zoau.Result{}, true
+}
+
+// mls pattern
+func (s *Simulator) mls(args []string) zoau.Result {
+	_, operands, err := parseOptions(args, "")
+	if err != nil || len(operands) != 1 {
+		return failure(8, "BGYSC1901E", "Usage: mls pattern.")
+	}
+	_, members, res, ok := s.partitionedMembers(operands[0])
+	if !ok {
+		return res
+	}
+	return success(joinRecords(members))
+}
+
+// mrm pattern
+func (s *Simulator) mrm(args []string) zoau.Result {
+	_, operands, err := parseOptions(args, "")
+	if err != nil || len(operands) != 1 {
+		return failure(8, "BGYSC1902E", "Usage: mrm pattern.")
+	}
+	ds, members, res, ok := s.partitionedMembers(operands[0])
+	if !ok {
+		return res
+	}
+	if len(members) == 0 {
+		return failure(1, "BGYSC1903E", "No members match pattern: %s.", operands[0])
+	}
+	for _, member := range members {
+		delete(ds.Members, member)
+	}
+	return success("")
+}
+
+// mmv dataset source target
+func (s *Simulator) mmv(args []string) zoau.Result {
+	_, operands, err := parseOptions(args, "")
+	if err != nil || len(operands) != 3 {
+		return failure(8, "BGYSC1904E", "Usage: mmv dataset source target.")
+	}
+	ds, _, res, ok := s.partitionedMembers(operands[0])
+	if !ok {
+		return res
+	}
+	source, target := strings.ToUpper(operands[1]), strings.ToUpper(operands[2])
+	records, ok := ds.Members[source]
+	if !ok {
+		return failure(8, "BGYSC1504E", "Member %s not found in dataset %s.", source, ds.Name)
+	}
+	if _, ok := ds.Members[target]; ok {
+		return failure(8, "BGYSC1905E", "Member %s already exists in dataset %s.", target, ds.Name)
+	}
+	delete(ds.Members, source)
+	ds.Members[target] = records
+	return success("")
+}
@@ -0,0 +1,302 @@
+package zoautest
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Stolkerve/zoau-go"
+)
+
+// diffOp is one line of an edit script: ' ' for a matched line, '-' for a deleted old line and '+' for
+// an inserted new line.
+type diffOp struct {
+	Kind    byte
+	OldLine int
+	NewLine int
+}
+
+// diffLines computes a longest common subsequence edit script turning old into new.
+func diffLines(old []string, new []string) []diffOp {
+	lcs := make([][]int, len(old)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(new)+1)
+	}
+	for i := len(old) - 1; i >= 0; i-- {
+		for j := len(new) - 1; j >= 0; j-- {
+			if old[i] == new[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, len(old)+len(new))
+	i, j := 0, 0
+	for i < len(old) || j < len(new) {
+		switch {
+		case i < len(old) && j < len(new) && old[i] == new[j]:
+			ops = append(ops, diffOp{' ', i, j})
+			i++
+			j++
+		case j < len(new) && (i == len(old) || lcs[i][j+1] >= lcs[i+1][j]):
+			ops = append(ops, diffOp{'+', i, j})
+			j++
+		default:
+			ops = append(ops, diffOp{'-', i, j})
+			i++
+		}
+	}
+	return ops
+}
+
+// ddiff [-i] [-c start:end] [-C start:end] source target
+//
+// The source is compared as the OLD file and the target as the NEW file. The listing follows the
+// layout of an ISRSUPC line compare with a delta listing: rc 0 when the datasets match and rc 1
+// when they differ. Two partitioned datasets are compared member by member, with a member summary.
+func (s *Simulator) ddiff(args []string) zoau.Result {
+	options, operands, err := parseOptions(args, "cC")
+	if err != nil || len(operands) != 2 {
+		return failure(8, "BGYSC3001E", "Usage: ddiff [options] source target.")
+	}
+	_, ignoreCase := options['i']
+	columns, err := parseRange(options, 'c')
+	if err != nil {
+		return failure(8, "BGYSC3002E", "Invalid columns: %v.", err)
+	}
+	lines, err := parseRange(options, 'C')
+	if err != nil {
+		return failure(8, "BGYSC3002E", "Invalid lines: %v.", err)
+	}
+	c := &comparer{columns: columns, lines: lines, ignoreCase: ignoreCase}
+
+	oldName, newName := strings.ToUpper(operands[0]), strings.ToUpper(operands[1])
+	oldPds, newPds := s.partitionedDataset(operands[0]), s.partitionedDataset(operands[1])
+	var out strings.Builder
+	differ := false
+	if oldPds != nil && newPds != nil {
+		differ = c.comparePartitioned(&out, oldPds, newPds)
+	} else {
+		oldRecords, _, res, ok := s.readSource(operands[0])
+		if !ok {
+			return res
+		}
+		newRecords, _, res, ok := s.readSource(operands[1])
+		if !ok {
+			return res
+		}
+		writeCompareHeader(&out, newName, oldName)
+		out.WriteString("\n                          LISTING OUTPUT SECTION (LINE COMPARE)\n\n")
+		differ = c.compare(&out, oldRecords, newRecords)
+	}
+	c.writeSummary(&out)
+
+	if !differ {
+		return success(out.String())
+	}
+	return zoau.Result{Stdout: out.String(), Rc: 1}
+}
+
+// partitionedDataset returns the partitioned dataset a reference without member names, or nil.
+func (s *Simulator) partitionedDataset(ref string) *dataset {
+	if isPath(ref) {
+		return nil
+	}
+	name, member := splitName(ref)
+	if ds, ok := s.datasets[name]; ok && member == "" && ds.partitioned() {
+		return ds
+	}
+	return nil
+}
+
+// comparer runs line compares and accumulates their statistics.
+type comparer struct {
+	columns    []int
+	lines      []int
+	ignoreCase bool
+
+	matches, insertions, deletions, paired, inserts, deletes, newLines, oldLines int
+}
+
+func (c *comparer) key(r string) string {
+	if c.columns != nil {
+		r = r[min(c.columns[0]-1, len(r)):min(c.columns[1], len(r))]
+	}
+	if c.ignoreCase {
+		r = strings.ToUpper(r)
+	}
+	return strings.TrimRight(r, " ")
+}
+
+// compare writes the delta listing of two files and reports whether they differ.
+func (c *comparer) compare(out *strings.Builder, oldRecords []string, newRecords []string) bool {
+	if c.lines != nil {
+		oldRecords = oldRecords[min(c.lines[0]-1, len(oldRecords)):min(c.lines[1], len(oldRecords))]
+		newRecords = newRecords[min(c.lines[0]-1, len(newRecords)):min(c.lines[1], len(newRecords))]
+	}
+	oldKeys := make([]string, len(oldRecords))
+	for i, r := range oldRecords {
+		oldKeys[i] = c.key(r)
+	}
+	newKeys := make([]string, len(newRecords))
+	for i, r := range newRecords {
+		newKeys[i] = c.key(r)
+	}
+	c.newLines += len(newRecords)
+	c.oldLines += len(oldRecords)
+
+	ops := diffLines(oldKeys, newKeys)
+	out.WriteString("  ID       SOURCE LINES                                                                      TYPE    LEN N-LN# O-LN#\n")
+	out.WriteString("  ----+----1----+----2----+----3----+----4----+----5----+----6----+----7----+----8\n")
+	differ := false
+	for i := 0; i < len(ops); {
+		if ops[i].Kind == ' ' {
+			c.matches++
+			i++
+			continue
+		}
+		differ = true
+		j := i
+		ins, del := make([]diffOp, 0), make([]diffOp, 0)
+		for ; j < len(ops) && ops[j].Kind != ' '; j++ {
+			if ops[j].Kind == '+' {
+				ins = append(ins, ops[j])
+			} else {
+				del = append(del, ops[j])
+			}
+		}
+		c.insertions += len(ins)
+		c.deletions += len(del)
+		kind := "RPL"
+		switch {
+		case len(del) == 0:
+			kind = "INS"
+			c.inserts++
+		case len(ins) == 0:
+			kind = "DEL"
+			c.deletes++
+		default:
+			c.paired++
+		}
+		// The first line of the block carries the line numbers where the block starts in both files.
+		first := true
+		for _, op := range ins {
+			writeListingLine(out, "I", newRecords[op.NewLine], kind, len(ins)+len(del), ops[i].NewLine+1, ops[i].OldLine+1, first)
+			first = false
+		}
+		for _, op := range del {
+			writeListingLine(out, "D", oldRecords[op.OldLine], kind, len(ins)+len(del), ops[i].NewLine+1, ops[i].OldLine+1, first)
+			first = false
+		}
+		i = j
+	}
+	return differ
+}
+
+// comparePartitioned writes the member summary and the delta listing of every changed member, and
+// reports whether the datasets differ.
+func (c *comparer) comparePartitioned(out *strings.Builder, oldPds *dataset, newPds *dataset) bool {
+	names := make([]string, 0)
+	for name := range oldPds.Members {
+		names = append(names, name)
+	}
+	for name := range newPds.Members {
+		if _, ok := oldPds.Members[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var summary, newOnly, oldOnly, listings strings.Builder
+	differ := false
+	for _, name := range names {
+		oldRecords, inOld := oldPds.Members[name]
+		newRecords, inNew := newPds.Members[name]
+		switch {
+		case !inOld:
+			fmt.Fprintf(&newOnly, "              %-8s    %7d\n", name, len(newRecords))
+			differ = true
+		case !inNew:
+			fmt.Fprintf(&oldOnly, "              %-8s    %7d\n", name, len(oldRecords))
+			differ = true
+		default:
+			var listing strings.Builder
+			if c.compare(&listing, oldRecords, newRecords) {
+				differ = true
+				fmt.Fprintf(&summary, "   **         %-8s    %7d   %7d\n", name, len(newRecords), len(oldRecords))
+				writeCompareHeader(&listings, newPds.Name+"("+name+")", oldPds.Name+"("+name+")")
+				listings.WriteString("\n                          LISTING OUTPUT SECTION (LINE COMPARE)\n\n")
+				listings.WriteString(listing.String())
+			} else {
+				fmt.Fprintf(&summary, "         **   %-8s    %7d   %7d\n", name, len(newRecords), len(oldRecords))
+			}
+		}
+	}
+
+	writeCompareHeader(out, newPds.Name, oldPds.Name)
+	out.WriteString("\n                          MEMBER SUMMARY LISTING (LINE COMPARE)\n\n")
+	out.WriteString("  DIFF  SAME  MEMBER-NAME   N-LINES   O-LINES\n")
+	out.WriteString(summary.String())
+	if newOnly.Len() > 0 {
+		out.WriteString("\n  NON-PAIRED NEW MEMBERS\n")
+		out.WriteString(newOnly.String())
+	}
+	if oldOnly.Len() > 0 {
+		out.WriteString("\n  NON-PAIRED OLD MEMBERS\n")
+		out.WriteString(oldOnly.String())
+	}
+	out.WriteString(listings.String())
+	return differ
+}
+
+func writeCompareHeader(out *strings.Builder, newName string, oldName string) {
+	now := time.Now()
+	fmt.Fprintf(out, "1  ISRSUPC   -   MVS/PDF FILE/LINE/WORD/BYTE/SFOR COMPARE UTILITY- ISPF FOR z/OS         %s  %s    PAGE     1\n", now.Format("2006/01/02"), now.Format("15.04"))
+	fmt.Fprintf(out, "      NEW: %-44s OLD: %s\n", newName, oldName)
+}
+
+func (c *comparer) writeSummary(out *strings.Builder) {
+	out.WriteString("\n                          LINE COMPARE SUMMARY AND STATISTICS\n\n")
+	fmt.Fprintf(out, "  %5d NUMBER OF LINE MATCHES         %5d  TOTAL CHANGES (PAIRED+NON PAIRED CHNG)\n", c.matches, c.paired+c.inserts+c.deletes)
+	fmt.Fprintf(out, "  %5d REFORMATTED LINES              %5d  PAIRED CHANGES (REFM+PAIRED INS/DEL)\n", 0, c.paired)
+	fmt.Fprintf(out, "  %5d NEW FILE LINE INSERTIONS       %5d  NON-PAIRED INSERTS\n", c.insertions, c.inserts)
+	fmt.Fprintf(out, "  %5d OLD FILE LINE DELETIONS        %5d  NON-PAIRED DELETES\n", c.deletions, c.deletes)
+	fmt.Fprintf(out, "  %5d NEW FILE LINES PROCESSED\n", c.newLines)
+	fmt.Fprintf(out, "  %5d OLD FILE LINES PROCESSED\n", c.oldLines)
+}
+
+func writeListingLine(out *strings.Builder, id string, text string, kind string, length int, newLine int, oldLine int, first bool) {
+	if !first {
+		fmt.Fprintf(out, "  %s - %s\n", id, text)
+		return
+	}
+	fmt.Fprintf(out, "  %s - %-80s %s  %05d %05d %05d\n", id, text, kind, length, newLine, oldLine)
+}
+
+// parseRange parses a start:end option value. It returns nil if the option is not set.
+func parseRange(options map[byte][]string, opt byte) ([]int, error) {
+	v, ok := lastOption(options, opt)
+	if !ok {
+		return nil, nil
+	}
+	bounds := strings.SplitN(v, ":", 2)
+	if len(bounds) != 2 {
+		return nil, fmt.Errorf("%s is not a start:end range", v)
+	}
+	start, err := strconv.Atoi(bounds[0])
+	if err != nil {
+		return nil, err
+	}
+	end, err := strconv.Atoi(bounds[1])
+	if err != nil {
+		return nil, err
+	}
+	if start < 1 || end < start {
+		return nil, fmt.Errorf("%s is not a valid range", v)
+	}
+	return []int{start, end}, nil
+}
@@ -0,0 +1,165 @@
+package zoautest
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// gdgBase holds the attributes of a GDG base.
+type gdgBase struct {
+	Limit    int
+	Scratch  bool
+	Empty    bool
+	Purge    bool
+	Lifo     bool
+	Extended bool
+}
+
+var generationRegex = regexp.MustCompile(`^(.+)\.G[0-9]{4}V[0-9]{2}$`)
+
+// generations returns the names of the generations of a GDG base, oldest first.
+func (s *Simulator) generations(base string) []string {
+	names := make([]string, 0)
+	for name := range s.datasets {
+		if m := generationRegex.FindStringSubmatch(name); m != nil && m[1] == base {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	// Generation numbers wrap from G9999 to G0001: the oldest follows the widest gap between numbers.
+	number := func(name string) int {
+		return atoiDefault(name[len(base)+2:len(base)+6], 0)
+	}
+	start, widest := 0, 0
+	for i, name := range names {
+		gap := number(name) - number(names[(i+len(names)-1)%len(names)])
+		if i == 0 {
+			gap += 9999
+		}
+		if gap > widest {
+			start, widest = i, gap
+		}
+	}
+	return append(append(make([]string, 0, len(names)), names[start:]...), names[:start]...)
+}
+
+// rollOff removes the oldest generations of the GDG name belongs to once the limit is exceeded.
+// With the EMPTY attribute, every generation but the new one is removed.
+func (s *Simulator) rollOff(name string) {
+	m := generationRegex.FindStringSubmatch(name)
+	if m == nil {
+		return
+	}
+	base, ok := s.datasets[m[1]]
+	if !ok || base.Gdg == nil {
+		return
+	}
+	generations := s.generations(base.Name)
+	if len(generations) <= base.Gdg.Limit {
+		return
+	}
+	keep := base.Gdg.Limit
+	if base.Gdg.Empty {
+		keep = 1
+	}
+	for _, g := range generations[:len(generations)-keep] {
+		if g != name {
+			delete(s.datasets, g)
+		}
+	}
+}
+
+func (s *Simulator) defineGdg(l *idcamsListing, params idcamsNode) int {
+	name, ok := params.value("NAME")
+	if !ok {
+		return l.fail(12, "IDC3201I", "VALUE REQUIRED FOR KEYWORD 'NAME'")
+	}
+	name = strings.ToUpper(name)
+	v, _ := params.value("LIMIT")
+	limit := atoiDefault(v, -1)
+	maxLimit := 255
+	if params.has("EXTENDED") {
+		maxLimit = 999
+	}
+	if limit < 1 || limit > maxLimit {
+		return l.fail(12, "IDC3226I", "VALUE FOR KEYWORD 'LIMIT' IS OUT OF RANGE")
+	}
+	if _, ok := s.datasets[name]; ok {
+		l.printf("IDC3013I DUPLICATE DATA SET NAME")
+		return l.fail(12, "IDC3009I", "** VSAM CATALOG RETURN CODE IS 8 - REASON CODE IS IGG0CLEH-38")
+	}
+
+	s.datasets[name] = &dataset{
+		Name:       name,
+		Dsorg:      "GDG",
+		Recfm:      "??",
+		Volume:     "??????",
+		Referenced: time.Now(),
+		Gdg: &gdgBase{
+			Limit:    limit,
+			Scratch:  params.has("SCRATCH"),
+			Empty:    params.has("EMPTY"),
+			Purge:    params.has("PURGE"),
+			Lifo:     params.has("LIFO"),
+			Extended: params.has("EXTENDED"),
+		},
+	}
+	return 0
+}
+
+func (s *Simulator) alterGdg(l *idcamsListing, ds *dataset, params idcamsNode) int {
+	gdg := *ds.Gdg
+	if v, ok := params.value("LIMIT"); ok {
+		gdg.Limit = atoiDefault(v, -1)
+	}
+	flags := []struct {
+		On, Off string
+		Flag    *bool
+	}{
+		{"SCRATCH", "NOSCRATCH", &gdg.Scratch},
+		{"EMPTY", "NOEMPTY", &gdg.Empty},
+		{"PURGE", "NOPURGE", &gdg.Purge},
+		{"LIFO", "FIFO", &gdg.Lifo},
+		{"EXTENDED", "", &gdg.Extended},
+	}
+	for _, f := range flags {
+		if params.has(f.On) {
+			*f.Flag = true
+		}
+		if f.Off != "" && params.has(f.Off) {
+			*f.Flag = false
+		}
+	}
+	maxLimit := 255
+	if gdg.Extended {
+		maxLimit = 999
+	}
+	if gdg.Limit < 1 || gdg.Limit > maxLimit {
+		return l.fail(12, "IDC3226I", "VALUE FOR KEYWORD 'LIMIT' IS OUT OF RANGE")
+	}
+
+	ds.Gdg = &gdg
+	if generations := s.generations(ds.Name); len(generations) > 0 {
+		s.rollOff(generations[len(generations)-1])
+	}
+	l.printf("IDC0531I ENTRY %s ALTERED", ds.Name)
+	return 0
+}
+
+func (s *Simulator) deleteGdg(l *idcamsListing, ds *dataset, params idcamsNode) int {
+	generations := s.generations(ds.Name)
+	if len(generations) > 0 && !params.has("FORCE") && !params.has("RECOVERY") {
+		l.printf("IDC3009I ** VSAM CATALOG RETURN CODE IS 12 - REASON CODE IS IGG0CLEH-26")
+		return l.fail(8, "IDC0551I", "** ENTRY %s NOT DELETED", ds.Name)
+	}
+	for _, g := range generations {
+		delete(s.datasets, g)
+		l.printf("IDC0550I ENTRY (A) %s DELETED", g)
+	}
+	delete(s.datasets, ds.Name)
+	l.printf("IDC0550I ENTRY (B) %s DELETED", ds.Name)
+	return 0
+}
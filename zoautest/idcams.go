@@ -0,0 +1,382 @@
+package zoautest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// idcamsNode is a keyword or value of an IDCAMS command, with the parenthesized list following it.
+type idcamsNode struct {
+	Name     string
+	Children []idcamsNode
+}
+
+// Abbreviations of the IDCAMS keywords, mapped to their full name.
+var idcamsAbbreviations = map[string]string{
+	"AIX":      "ALTERNATEINDEX",
+	"BIX":      "BLDINDEX",
+	"CHAR":     "CHARACTER",
+	"CISZ":     "CONTROLINTERVALSIZE",
+	"CL":       "CLUSTER",
+	"CYL":      "CYLINDERS",
+	"DATACLAS": "DATACLASS",
+	"DEF":      "DEFINE",
+	"EMP":      "EMPTY",
+	"ENT":      "ENTRIES",
+	"FKEY":     "FROMKEY",
+	"FSPC":     "FREESPACE",
+	"GDG":      "GENERATIONDATAGROUP",
+	"IDS":      "INDATASET",
+	"IFILE":    "INFILE",
+	"IX":       "INDEX",
+	"IXD":      "INDEXED",
+	"KILO":     "KILOBYTES",
+	"LIM":      "LIMIT",
+	"LIN":      "LINEAR",
+	"LISTC":    "LISTCAT",
+	"LVL":      "LEVEL",
+	"MEGA":     "MEGABYTES",
+	"MGMTCLAS": "MANAGEMENTCLASS",
+	"NEMP":     "NOEMPTY",
+	"NIXD":     "NONINDEXED",
+	"NPRG":     "NOPURGE",
+	"NSCR":     "NOSCRATCH",
+	"NUMD":     "NUMBERED",
+	"NUNQK":    "NONUNIQUEKEY",
+	"NUPD":     "NOUPDATE",
+	"NUPG":     "NOUPGRADE",
+	"ODS":      "OUTDATASET",
+	"OFILE":    "OUTFILE",
+	"PENT":     "PATHENTRY",
+	"PRG":      "PURGE",
+	"REC":      "RECORDS",
+	"RECSZ":    "RECORDSIZE",
+	"REL":      "RELATE",
+	"REP":      "REPLACE",
+	"SCR":      "SCRATCH",
+	"SHR":      "SHAREOPTIONS",
+	"STORCLAS": "STORAGECLASS",
+	"TKEY":     "TOKEY",
+	"TRK":      "TRACKS",
+	"UNQK":     "UNIQUEKEY",
+	"UPD":      "UPDATE",
+	"UPG":      "UPGRADE",
+	"VOL":      "VOLUMES",
+}
+
+func idcamsKeyword(word string) string {
+	word = strings.ToUpper(word)
+	if full, ok := idcamsAbbreviations[word]; ok {
+		return full
+	}
+	return word
+}
+
+// find returns the child named keyword.
+func (n idcamsNode) find(keyword string) (idcamsNode, bool) {
+	for _, c := range n.Children {
+		if c.Name == keyword {
+			return c, true
+		}
+	}
+	return idcamsNode{}, false
+}
+
+// has reports whether n has a child named keyword.
+func (n idcamsNode) has(keyword string) bool {
+	_, ok := n.find(keyword)
+	return ok
+}
+
+// value returns the first value of the child named keyword (e.g. "5" for LIMIT(5)).
+func (n idcamsNode) value(keyword string) (string, bool) {
+	c, ok := n.find(keyword)
+	if !ok || len(c.Children) == 0 {
+		return "", false
+	}
+	return c.Children[0].Name, true
+}
+
+// values returns the values of the child named keyword (e.g. "8", "0" for KEYS(8 0)).
+func (n idcamsNode) values(keyword string) []string {
+	c, _ := n.find(keyword)
+	values := make([]string, 0, len(c.Children))
+	for _, v := range c.Children {
+		values = append(values, v.Name)
+	}
+	return values
+}
+
+// parseIdcams splits IDCAMS control statements into commands, joining the continuation lines
+// (ending with - or +) and dropping the comments.
+func parseIdcams(statements string) ([]string, [][]idcamsNode, error) {
+	texts := make([]string, 0)
+	commands := make([][]idcamsNode, 0)
+	current := ""
+	inComment := false
+	for _, line := range strings.Split(statements, "\n") {
+		var b strings.Builder
+		for i := 0; i < len(line); i++ {
+			switch {
+			case inComment && strings.HasPrefix(line[i:], "*/"):
+				inComment = false
+				i++
+			case inComment:
+			case strings.HasPrefix(line[i:], "/*"):
+				inComment = true
+				i++
+			default:
+				b.WriteByte(line[i])
+			}
+		}
+		text := strings.TrimRight(b.String(), " \t")
+		if strings.HasSuffix(text, "-") || strings.HasSuffix(text, "+") {
+			current += text[:len(text)-1] + " "
+			continue
+		}
+		current += text
+		if strings.TrimSpace(current) != "" {
+			nodes, err := parseIdcamsCommand(current)
+			if err != nil {
+				return nil, nil, err
+			}
+			texts = append(texts, strings.TrimSpace(current))
+			commands = append(commands, nodes)
+		}
+		current = ""
+	}
+	if strings.TrimSpace(current) != "" {
+		return nil, nil, fmt.Errorf("unexpected end of input after %q", strings.TrimSpace(current))
+	}
+	return texts, commands, nil
+}
+
+func parseIdcamsCommand(text string) ([]idcamsNode, error) {
+	tokens := make([]string, 0)
+	for i := 0; i < len(text); {
+		switch c := text[i]; {
+		case c == ' ' || c == '\t' || c == ',' || c == ';':
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, string(c))
+			i++
+		case c == '\'':
+			end := strings.IndexByte(text[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quoted string in %q", text)
+			}
+			tokens = append(tokens, "'"+text[i+1:i+1+end])
+			i += end + 2
+		default:
+			start := i
+			for i < len(text) && !strings.ContainsRune(" \t,;()'", rune(text[i])) {
+				i++
+			}
+			tokens = append(tokens, text[start:i])
+		}
+	}
+
+	nodes, rest, err := parseIdcamsList(tokens)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 {
+		return nil, fmt.Errorf("unbalanced parentheses in %q", text)
+	}
+	return nodes, nil
+}
+
+func parseIdcamsList(tokens []string) ([]idcamsNode, []string, error) {
+	nodes := make([]idcamsNode, 0)
+	for len(tokens) > 0 {
+		token := tokens[0]
+		if token == ")" {
+			return nodes, tokens, nil
+		}
+		tokens = tokens[1:]
+
+		node := idcamsNode{}
+		if token != "(" {
+			if strings.HasPrefix(token, "'") {
+				node.Name = token[1:]
+			} else {
+				node.Name = idcamsKeyword(token)
+			}
+			if len(tokens) == 0 || tokens[0] != "(" {
+				nodes = append(nodes, node)
+				continue
+			}
+			tokens = tokens[1:]
+		}
+		children, rest, err := parseIdcamsList(tokens)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(rest) == 0 {
+			return nil, nil, fmt.Errorf("missing closing parenthesis")
+		}
+		node.Children = children
+		nodes = append(nodes, node)
+		tokens = rest[1:]
+	}
+	return nodes, tokens, nil
+}
+
+// idcamsListing collects the SYSPRINT of IDCAMS and its highest condition code.
+type idcamsListing struct {
+	out     strings.Builder
+	maxCode int
+}
+
+func (l *idcamsListing) printf(format string, a ...any) {
+	fmt.Fprintf(&l.out, format+"\n", a...)
+}
+
+// fail reports a failed function with the given condition code.
+func (l *idcamsListing) fail(code int, id string, format string, a ...any) int {
+	l.printf("%s %s", id, fmt.Sprintf(format, a...))
+	return code
+}
+
+type idcamsFunction func(s *Simulator, l *idcamsListing, command []idcamsNode) int
+
+var idcamsFunctions = map[string]idcamsFunction{
+	"ALTER":    (*Simulator).idcamsAlter,
+	"BLDINDEX": (*Simulator).idcamsBuildIndex,
+	"DEFINE":   (*Simulator).idcamsDefine,
+	"DELETE":   (*Simulator).idcamsDelete,
+	"LISTCAT":  (*Simulator).idcamsListcat,
+	"PRINT":    (*Simulator).idcamsPrint,
+	"REPRO":    (*Simulator).idcamsRepro,
+}
+
+// idcams runs the IDCAMS control statements and returns the SYSPRINT listing and the maximum condition code.
+func (s *Simulator) idcams(statements string) (string, int) {
+	l := &idcamsListing{}
+	l.printf("IDCAMS  SYSTEM SERVICES")
+
+	texts, commands, err := parseIdcams(statements)
+	if err != nil {
+		l.printf("IDC3211I KEYWORD PARAMETER OR VALUE IS INVALID: %v", err)
+		l.printf("IDC0002I IDCAMS PROCESSING COMPLETE. MAXIMUM CONDITION CODE WAS 12")
+		return l.out.String(), 12
+	}
+
+	for i, command := range commands {
+		l.printf("")
+		l.printf("  %s", texts[i])
+		code := 12
+		if f, ok := idcamsFunctions[command[0].Name]; ok {
+			code = f(s, l, command)
+		} else {
+			l.printf("IDC3202I ABOVE TEXT BYPASSED UNTIL NEXT COMMAND. CONDITION CODE IS 12")
+			l.printf("IDC3205I CONDITION CODE IS 12 (COMMAND %s UNKNOWN)", command[0].Name)
+		}
+		l.maxCode = max(l.maxCode, code)
+		l.printf("IDC0001I FUNCTION COMPLETED, HIGHEST CONDITION CODE WAS %d", code)
+	}
+	l.printf("")
+	l.printf("IDC0002I IDCAMS PROCESSING COMPLETE. MAXIMUM CONDITION CODE WAS %d", l.maxCode)
+	return l.out.String(), l.maxCode
+}
+
+func (s *Simulator) idcamsDefine(l *idcamsListing, command []idcamsNode) int {
+	if len(command) < 2 {
+		return l.fail(12, "IDC3203I", "ITEM 'DEFINE' DOES NOT ADHERE TO RESTRICTIONS")
+	}
+	switch object := command[1]; object.Name {
+	case "GENERATIONDATAGROUP":
+		return s.defineGdg(l, object)
+	case "CLUSTER":
+		return s.defineCluster(l, object, command[2:])
+	case "ALTERNATEINDEX":
+		return s.defineAlternateIndex(l, object, command[2:])
+	case "PATH":
+		return s.definePath(l, object)
+	default:
+		return l.fail(12, "IDC3203I", "ITEM '%s' DOES NOT ADHERE TO RESTRICTIONS", object.Name)
+	}
+}
+
+func (s *Simulator) idcamsAlter(l *idcamsListing, command []idcamsNode) int {
+	if len(command) < 2 {
+		return l.fail(12, "IDC3203I", "ITEM 'ALTER' DOES NOT ADHERE TO RESTRICTIONS")
+	}
+	params := idcamsNode{Children: command[2:]}
+	name := strings.ToUpper(command[1].Name)
+	ds, ok := s.datasets[name]
+	if !ok {
+		ds, ok = s.vsamComponent(name)
+	}
+	if !ok {
+		return l.fail(8, "IDC3012I", "ENTRY %s NOT FOUND", name)
+	}
+	if ds.Gdg != nil {
+		return s.alterGdg(l, ds, params)
+	}
+	if ds.Vsam != nil {
+		return s.alterVsam(l, name, ds, params)
+	}
+	return l.fail(8, "IDC3009I", "** VSAM CATALOG RETURN CODE IS 60 - REASON CODE IS IGG0CLKP-0")
+}
+
+func (s *Simulator) idcamsDelete(l *idcamsListing, command []idcamsNode) int {
+	if len(command) < 2 {
+		return l.fail(12, "IDC3203I", "ITEM 'DELETE' DOES NOT ADHERE TO RESTRICTIONS")
+	}
+	params := idcamsNode{Children: command[2:]}
+	names := []string{command[1].Name}
+	if command[1].Children != nil {
+		names = names[:0]
+		for _, c := range command[1].Children {
+			names = append(names, c.Name)
+		}
+	}
+
+	code := 0
+	for _, name := range names {
+		name = strings.ToUpper(name)
+		ds, ok := s.datasets[name]
+		if !ok {
+			l.printf("IDC3012I ENTRY %s NOT FOUND", name)
+			l.printf("IDC3009I ** VSAM CATALOG RETURN CODE IS 8 - REASON CODE IS IGG0CLEG-42")
+			code = max(code, l.fail(8, "IDC0551I", "** ENTRY %s NOT DELETED", name))
+			continue
+		}
+		if ds.Gdg != nil {
+			code = max(code, s.deleteGdg(l, ds, params))
+			continue
+		}
+		if ds.Vsam != nil {
+			s.deleteVsam(l, ds)
+			continue
+		}
+		delete(s.datasets, name)
+		l.printf("IDC0550I ENTRY (%s) %s DELETED", entryType(ds), name)
+	}
+	return code
+}
+
+// entryType returns the LISTCAT entry type letter of ds.
+func entryType(ds *dataset) string {
+	switch {
+	case ds.Gdg != nil:
+		return "B"
+	case ds.Vsam != nil && ds.Vsam.Kind == "AIX":
+		return "G"
+	case ds.Vsam != nil && ds.Vsam.Kind == "PATH":
+		return "R"
+	case ds.Dsorg == "VS":
+		return "C"
+	default:
+		return "A"
+	}
+}
+
+func atoiDefault(v string, def int) int {
+	if n, err := strconv.Atoi(v); err == nil {
+		return n
+	}
+	return def
+}
@@ -0,0 +1,239 @@
+package zoautest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Stolkerve/zoau-go"
+)
+
+type job struct {
+	Id     string
+	Name   string
+	Owner  string
+	Status string
+	Rc     string
+	DDs    []spoolDD
+}
+
+type spoolDD struct {
+	Step     string
+	ProcStep string
+	DDName   string
+	Records  []string
+}
+
+// jsub dataset
+//
+// The job ends right away with CC 0000. Its spool holds the JES datasets and a dataset for every
+// SYSOUT DD statement; for IEBGENER steps SYSUT2 receives the in-stream data of SYSUT1.
+func (s *Simulator) jsub(args []string) zoau.Result {
+	_, operands, err := parseOptions(args, "")
+	if err != nil || len(operands) != 1 {
+		return failure(8, "BGYSC5001E", "Usage: jsub dataset.")
+	}
+	jcl, _, res, ok := s.readSource(operands[0])
+	if !ok {
+		return res
+	}
+	if len(jcl) == 0 {
+		return failure(8, "BGYSC5002E", "No JCL found in %s.", operands[0])
+	}
+	fields := strings.Fields(strings.TrimPrefix(jcl[0], "//"))
+	if !strings.HasPrefix(jcl[0], "//") || len(fields) < 2 || fields[1] != "JOB" {
+		return failure(8, "BGYSC5003E", "The first statement of %s is not a JOB statement.", operands[0])
+	}
+
+	s.jobSeq++
+	j := &job{
+		Id:     fmt.Sprintf("JOB%05d", s.jobSeq),
+		Name:   fields[0],
+		Owner:  s.hlq,
+		Status: "CC",
+		Rc:     "0000",
+	}
+	j.DDs = append(j.DDs,
+		spoolDD{Step: "JES2", DDName: "JESMSGLG", Records: []string{
+			fmt.Sprintf("%s  $HASP373 %-8s STARTED", j.Id, j.Name),
+			fmt.Sprintf("%s  $HASP395 %-8s ENDED - RC=%s", j.Id, j.Name, j.Rc),
+		}},
+		spoolDD{Step: "JES2", DDName: "JESJCL", Records: append([]string{}, jcl...)},
+		spoolDD{Step: "JES2", DDName: "JESYSMSG", Records: []string{}},
+	)
+	j.DDs = append(j.DDs, sysoutDDs(jcl)...)
+	s.jobs = append(s.jobs, j)
+	return success(j.Id + "\n")
+}
+
+type jclStep struct {
+	Name     string
+	Pgm      string
+	Sysout   []string
+	Instream map[string][]string
+}
+
+// sysoutDDs returns the spool datasets produced by the steps of a job.
+func sysoutDDs(jcl []string) []spoolDD {
+	steps := make([]*jclStep, 0)
+	var instream *jclStep
+	instreamDD := ""
+
+	for _, card := range jcl {
+		if instream != nil {
+			if !strings.HasPrefix(card, "/*") && !strings.HasPrefix(card, "//") {
+				instream.Instream[instreamDD] = append(instream.Instream[instreamDD], card)
+				continue
+			}
+			instream = nil
+		}
+		if !strings.HasPrefix(card, "//") || strings.HasPrefix(card, "//*") {
+			continue
+		}
+		fields := strings.Fields(card[2:])
+		if len(fields) < 3 {
+			continue
+		}
+		if fields[1] == "EXEC" {
+			step := &jclStep{Name: fields[0], Instream: make(map[string][]string)}
+			if strings.HasPrefix(fields[2], "PGM=") {
+				step.Pgm = strings.Split(strings.TrimPrefix(fields[2], "PGM="), ",")[0]
+			}
+			steps = append(steps, step)
+			continue
+		}
+		if fields[1] != "DD" || len(steps) == 0 {
+			continue
+		}
+		step := steps[len(steps)-1]
+		switch {
+		case fields[2] == "*" || fields[2] == "DATA":
+			instream, instreamDD = step, fields[0]
+			step.Instream[instreamDD] = []string{}
+		case strings.HasPrefix(fields[2], "SYSOUT="):
+			step.Sysout = append(step.Sysout, fields[0])
+		}
+	}
+
+	dds := make([]spoolDD, 0)
+	for _, step := range steps {
+		for _, name := range step.Sysout {
+			records := []string{}
+			if step.Pgm == "IEBGENER" && name == "SYSUT2" {
+				records = append(records, step.Instream["SYSUT1"]...)
+			}
+			dds = append(dds, spoolDD{Step: step.Name, DDName: name, Records: records})
+		}
+	}
+	return dds
+}
+
+// findJobs returns the jobs whose id, name or owner matches pattern, in submission order.
+func (s *Simulator) findJobs(pattern string) []*job {
+	pattern = strings.ToUpper(strings.Trim(pattern, "/"))
+	jobs := make([]*job, 0)
+	for _, j := range s.jobs {
+		if pattern == "" || matchPattern(pattern, j.Id) || matchPattern(pattern, j.Name) || matchPattern(pattern, j.Owner) {
+			jobs = append(jobs, j)
+		}
+	}
+	return jobs
+}
+
+// jls [pattern]
+func (s *Simulator) jls(args []string) zoau.Result {
+	_, operands, err := parseOptions(args, "")
+	if err != nil {
+		return failure(8, "BGYSC5101E", "%v.", err)
+	}
+	pattern := ""
+	if len(operands) > 0 {
+		pattern = operands[0]
+	}
+	var out strings.Builder
+	for _, j := range s.findJobs(pattern) {
+		fmt.Fprintf(&out, "%-8s %-8s %-8s %-4s %s\n", j.Owner, j.Name, j.Id, j.Status, j.Rc)
+	}
+	return success(out.String())
+}
+
+// jcan [C|P] [jobname] jobid
+func (s *Simulator) jcan(args []string) zoau.Result {
+	_, operands, err := parseOptions(args, "")
+	if err != nil || len(operands) == 0 {
+		return failure(8, "BGYSC5201E", "Usage: jcan [C|P] [jobname] jobid.")
+	}
+	purge := operands[0] == "P"
+	id := strings.ToUpper(operands[len(operands)-1])
+	for i, j := range s.jobs {
+		if j.Id != id {
+			continue
+		}
+		if purge {
+			s.jobs = append(s.jobs[:i], s.jobs[i+1:]...)
+		} else {
+			j.Status, j.Rc = "CANCELED", "?"
+		}
+		return success("")
+	}
+	return failure(8, "BGYSC5202E", "Job %s not found.", id)
+}
+
+func (s *Simulator) job(id string) (*job, zoau.Result, bool) {
+	id = strings.ToUpper(id)
+	for _, j := range s.jobs {
+		if j.Id == id {
+			return j, zoau.Result{}, true
+		}
+	}
+	return nil, failure(8, "BGYSC5202E", "Job %s not found.", id), false
+}
+
+// ddls jobid [pattern]
+func (s *Simulator) ddls(args []string) zoau.Result {
+	_, operands, err := parseOptions(args, "")
+	if err != nil || len(operands) == 0 {
+		return failure(8, "BGYSC5301E", "Usage: ddls jobid [pattern].")
+	}
+	j, res, ok := s.job(operands[0])
+	if !ok {
+		return res
+	}
+	var out strings.Builder
+	for _, dd := range j.DDs {
+		procStep := dd.ProcStep
+		if procStep == "" {
+			procStep = "-"
+		}
+		length := 0
+		for _, r := range dd.Records {
+			length += len(r) + 1
+		}
+		fmt.Fprintf(&out, "%-8s %-8s %-8s %-4s %7d %7d\n", dd.Step, dd.DDName, procStep, "UTF", length, len(dd.Records))
+	}
+	return success(out.String())
+}
+
+// pjdd jobid step [procstep] ddname [pattern]
+func (s *Simulator) pjdd(args []string) zoau.Result {
+	_, operands, err := parseOptions(args, "")
+	if err == nil && len(operands) > 0 && strings.HasPrefix(operands[len(operands)-1], "/") {
+		operands = operands[:len(operands)-1]
+	}
+	if err != nil || len(operands) < 3 || len(operands) > 4 {
+		return failure(8, "BGYSC5401E", "Usage: pjdd jobid step [procstep] ddname.")
+	}
+	j, res, ok := s.job(operands[0])
+	if !ok {
+		return res
+	}
+	step, procStep, ddname := strings.ToUpper(operands[1]), "", strings.ToUpper(operands[len(operands)-1])
+	if len(operands) == 4 {
+		procStep = strings.ToUpper(operands[2])
+	}
+	for _, dd := range j.DDs {
+		if dd.Step == step && dd.ProcStep == procStep && dd.DDName == ddname {
+			return success(joinRecords(dd.Records))
+		}
+	}
+	return failure(8, "BGYSC5402E", "DD %s not found in step %s of job %s.", ddname, step, j.Id)
+}
@@ -0,0 +1,181 @@
+package zoautest
+
+import (
+	"os"
+	"strings"
+
+	"github.com/Stolkerve/zoau-go"
+)
+
+// ddStatement is a DD statement passed to mvscmd (e.g. --sysut2=USER.DATA,mod).
+type ddStatement struct {
+	// Dataset name, HFS path, "*" for the standard output or "dummy".
+	Value string
+
+	// Lower case options following the value (e.g. "mod", "shr", "filedata=text").
+	Options []string
+}
+
+func (dd ddStatement) has(option string) bool {
+	for _, o := range dd.Options {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+// parseMvscmd splits the arguments of mvscmd into the program name and its DD statements, by upper case name.
+func parseMvscmd(args []string) (string, map[string]ddStatement) {
+	pgm := ""
+	dds := make(map[string]ddStatement)
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "--") {
+			continue
+		}
+		name, value, _ := strings.Cut(arg[2:], "=")
+		switch name = strings.ToUpper(name); name {
+		case "PGM":
+			pgm = strings.ToUpper(value)
+		case "ARGS":
+		default:
+			fields := strings.Split(value, ",")
+			dd := ddStatement{Value: fields[0]}
+			for _, f := range fields[1:] {
+				dd.Options = append(dd.Options, strings.ToLower(f))
+			}
+			dds[name] = dd
+		}
+	}
+	return pgm, dds
+}
+
+// readDD returns the records of the input DD statement name: in-stream data read from the standard
+// input, a dataset or an HFS file.
+func (s *Simulator) readDD(dds map[string]ddStatement, name string) ([]string, zoau.Result, bool) {
+	dd, ok := dds[name]
+	if !ok {
+		return nil, failure(12, "IEC130I", "%s DD STATEMENT MISSING", name), false
+	}
+	switch {
+	case dd.Value == "stdin":
+		return splitRecords(s.stdin), zoau.Result{}, true
+	case strings.EqualFold(dd.Value, "dummy"):
+		return []string{}, zoau.Result{}, true
+	}
+	records, _, res, ok := s.readSource(dd.Value)
+	return records, res, ok
+}
+
+// writeDD writes the output of the DD statement name. Output to "*" is returned as the standard output.
+func (s *Simulator) writeDD(dds map[string]ddStatement, name string, output string) (zoau.Result, bool) {
+	dd, ok := dds[name]
+	if !ok || strings.EqualFold(dd.Value, "dummy") {
+		return success(""), true
+	}
+	if dd.Value == "*" {
+		return success(output), true
+	}
+	records := splitRecords(output)
+	if dd.has("mod") {
+		existing, _, res, ok := s.readSource(dd.Value)
+		if !ok {
+			return res, false
+		}
+		records = append(append([]string{}, existing...), records...)
+	}
+	if res, ok := s.writeTarget(dd.Value, records); !ok {
+		return res, false
+	}
+	return success(""), true
+}
+
+// mvscmd runs the few programs the simulator knows: IEFBR14, IEBGENER and IDCAMS.
+func (s *Simulator) mvscmd(args []string) zoau.Result {
+	pgm, dds := parseMvscmd(args)
+	switch pgm {
+	case "":
+		return failure(8, "BGYSC0301E", "Usage: mvscmd --pgm=program [--ddname=definition ...].")
+	case "IEFBR14":
+		return success("")
+	case "IEBGENER":
+		return s.iebgener(dds)
+	case "IDCAMS":
+		sysin, res, ok := s.readDD(dds, "SYSIN")
+		if !ok {
+			return res
+		}
+		listing, code := s.idcams(strings.Join(sysin, "\n"))
+		res, ok = s.writeDD(dds, "SYSPRINT", listing)
+		if !ok {
+			return res
+		}
+		res.Rc = code
+		return res
+	default:
+		return failure(8, "CSV003I", "Requested module %s not found.", pgm)
+	}
+}
+
+// iebgener copies SYSUT1 to SYSUT2, appending when SYSUT2 has the mod disposition.
+func (s *Simulator) iebgener(dds map[string]ddStatement) zoau.Result {
+	in, ok := dds["SYSUT1"]
+	if !ok {
+		return failure(12, "IEB311I", "CONFLICTING DCB PARAMETERS, SYSUT1 MISSING.")
+	}
+	out, ok := dds["SYSUT2"]
+	if !ok {
+		return failure(12, "IEB311I", "CONFLICTING DCB PARAMETERS, SYSUT2 MISSING.")
+	}
+
+	records := []string{}
+	if !strings.EqualFold(in.Value, "dummy") {
+		read, _, res, ok := s.readSource(in.Value)
+		if !ok {
+			return res
+		}
+		records = read
+	}
+
+	switch {
+	case out.Value == "*":
+		return success(joinRecords(records))
+	case strings.EqualFold(out.Value, "dummy"):
+		return success("")
+	case isPath(out.Value):
+		flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		if out.has("mod") {
+			flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+		}
+		f, err := os.OpenFile(out.Value, flags, 0o644)
+		if err == nil {
+			_, err = f.WriteString(joinRecords(records))
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		if err != nil {
+			return failure(8, "BGYSC1702E", "Unable to write file %s: %v.", out.Value, err)
+		}
+		return success("")
+	}
+
+	name, _ := splitName(out.Value)
+	if _, ok := s.datasets[name]; !ok {
+		if !out.has("new") {
+			return failure(8, "IEF212I", "DATA SET %s NOT FOUND.", name)
+		}
+		s.allocateLike(name, nil, false)
+	}
+	if out.has("mod") {
+		existing, _, res, ok := s.readSource(out.Value)
+		if !ok {
+			return res
+		}
+		records = append(append([]string{}, existing...), records...)
+	}
+	if res, ok := s.writeTarget(out.Value, records); !ok {
+		return res
+	}
+	return success("")
+}
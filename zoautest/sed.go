@@ -0,0 +1,532 @@
+package zoautest
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Stolkerve/zoau-go"
+)
+
+type sedAddress struct {
+	// 0 for every line, 'n' for a line number, '$' for the last line and '/' for a regular expression.
+	Kind byte
+	Line int
+	Re   *regexp.Regexp
+}
+
+// sedCommand is a single editing command of a dsed script.
+type sedCommand struct {
+	Address sedAddress
+
+	// One of s, d, a, i or c.
+	Cmd byte
+
+	// Substitution of the s command.
+	Re     *regexp.Regexp
+	Repl   string
+	Global bool
+	Nth    int
+
+	// Text of the a, i and c commands.
+	Text []string
+
+	// ZOAU extension for regular expression addresses: '1' applies the command to the first
+	// matching line only, '$' to the last one and 0 to every matching line.
+	Occurrence byte
+}
+
+// parseSed parses a single sed command. Surrounding double quotes are removed the way a shell command
+// line would, the trailing spaces of a text are kept.
+func parseSed(script string) (sedCommand, error) {
+	script = strings.TrimLeft(script, " ")
+	if len(script) >= 2 && script[0] == '"' && script[len(script)-1] == '"' {
+		script = script[1 : len(script)-1]
+	}
+	cmd := sedCommand{}
+	i := 0
+
+	switch {
+	case i < len(script) && script[i] >= '0' && script[i] <= '9':
+		j := i
+		for j < len(script) && script[j] >= '0' && script[j] <= '9' {
+			j++
+		}
+		cmd.Address.Kind = 'n'
+		cmd.Address.Line, _ = strconv.Atoi(script[i:j])
+		i = j
+	case i < len(script) && script[i] == '$':
+		cmd.Address.Kind = '$'
+		i++
+	case i < len(script) && (script[i] == '/' || script[i] == '\\'):
+		delim := byte('/')
+		if script[i] == '\\' {
+			if i+1 >= len(script) {
+				return cmd, fmt.Errorf("unterminated address regex")
+			}
+			i++
+			delim = script[i]
+		}
+		pattern, next, err := readDelimited(script, i+1, delim)
+		if err != nil {
+			return cmd, err
+		}
+		re, err := zoau.CompileBRE(pattern, false)
+		if err != nil {
+			return cmd, err
+		}
+		cmd.Address = sedAddress{Kind: '/', Re: re}
+		i = next
+	}
+
+	for i < len(script) && script[i] == ' ' {
+		i++
+	}
+	if i >= len(script) {
+		return cmd, fmt.Errorf("missing command")
+	}
+	cmd.Cmd = script[i]
+	i++
+
+	switch cmd.Cmd {
+	case 's':
+		if i >= len(script) {
+			return cmd, fmt.Errorf("unterminated `s' command")
+		}
+		delim := script[i]
+		pattern, next, err := readDelimited(script, i+1, delim)
+		if err != nil {
+			return cmd, err
+		}
+		repl, next, err := readDelimited(script, next, delim)
+		if err != nil {
+			return cmd, err
+		}
+		cmd.Repl = repl
+		ignoreCase := false
+		for _, flag := range script[next:] {
+			switch {
+			case flag == 'g':
+				cmd.Global = true
+			case flag == 'i' || flag == 'I':
+				ignoreCase = true
+			case flag >= '0' && flag <= '9':
+				cmd.Nth = cmd.Nth*10 + int(flag-'0')
+			default:
+				return cmd, fmt.Errorf("unknown option to `s': %c", flag)
+			}
+		}
+		if cmd.Re, err = zoau.CompileBRE(pattern, ignoreCase); err != nil {
+			return cmd, err
+		}
+	case 'd':
+		if rest := strings.TrimSpace(script[i:]); rest != "" {
+			return cmd, fmt.Errorf("extra characters after command: %s", rest)
+		}
+	case 'a', 'i', 'c':
+		text := strings.TrimLeft(script[i:], " ")
+		text = strings.TrimPrefix(text, "\\")
+		text = strings.TrimPrefix(text, "\n")
+		if cmd.Address.Kind == '/' {
+			if strings.HasSuffix(text, "/$") {
+				cmd.Occurrence = '$'
+				text = strings.TrimSuffix(text, "/$")
+			} else if strings.HasSuffix(text, "/1") {
+				cmd.Occurrence = '1'
+				text = strings.TrimSuffix(text, "/1")
+			}
+		}
+		cmd.Text = strings.Split(unescapeText(text), "\n")
+	default:
+		return cmd, fmt.Errorf("unknown command: `%c'", cmd.Cmd)
+	}
+	return cmd, nil
+}
+
+// readDelimited reads s from i up to the next unescaped delim. An escaped delimiter stands for itself.
+func readDelimited(s string, i int, delim byte) (string, int, error) {
+	var out strings.Builder
+	for ; i < len(s); i++ {
+		c := s[i]
+		if c == '\\' && i+1 < len(s) {
+			if s[i+1] == delim && strings.IndexByte(`.[]*^$\`, delim) < 0 {
+				out.WriteByte(delim)
+			} else {
+				out.WriteByte(c)
+				out.WriteByte(s[i+1])
+			}
+			i++
+			continue
+		}
+		if c == delim {
+			return out.String(), i + 1, nil
+		}
+		out.WriteByte(c)
+	}
+	return "", i, fmt.Errorf("unterminated expression, missing %c", delim)
+}
+
+// unescapeText resolves the backslash escapes of the text of the a, i and c commands.
+func unescapeText(s string) string {
+	var out strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			if s[i] == 'n' {
+				out.WriteByte('\n')
+				continue
+			}
+		}
+		out.WriteByte(s[i])
+	}
+	return out.String()
+}
+
+// expandReplacement builds the replacement of an s command for the match m of re in line.
+func expandReplacement(repl string, line string, m []int) string {
+	var out strings.Builder
+	for i := 0; i < len(repl); i++ {
+		c := repl[i]
+		switch {
+		case c == '&':
+			out.WriteString(line[m[0]:m[1]])
+		case c == '\\' && i+1 < len(repl):
+			i++
+			n := repl[i]
+			switch {
+			case n >= '0' && n <= '9':
+				if g := int(n - '0'); 2*g+1 < len(m) && m[2*g] >= 0 {
+					out.WriteString(line[m[2*g]:m[2*g+1]])
+				}
+			case n == 'n':
+				out.WriteByte('\n')
+			default:
+				out.WriteByte(n)
+			}
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return out.String()
+}
+
+// selected returns the indexes of the records the command applies to.
+func (cmd *sedCommand) selected(records []string) []int {
+	lines := make([]int, 0)
+	switch cmd.Address.Kind {
+	case 0:
+		for i := range records {
+			lines = append(lines, i)
+		}
+	case 'n':
+		if cmd.Address.Line >= 1 && cmd.Address.Line <= len(records) {
+			lines = append(lines, cmd.Address.Line-1)
+		}
+	case '$':
+		if len(records) > 0 {
+			lines = append(lines, len(records)-1)
+		}
+	case '/':
+		for i, r := range records {
+			if cmd.Address.Re.MatchString(r) {
+				lines = append(lines, i)
+			}
+		}
+		if len(lines) > 0 && cmd.Occurrence == '1' {
+			lines = lines[:1]
+		} else if len(lines) > 0 && cmd.Occurrence == '$' {
+			lines = lines[len(lines)-1:]
+		}
+	}
+	return lines
+}
+
+// apply runs the command over records and returns the edited records.
+func (cmd *sedCommand) apply(records []string) []string {
+	lines := cmd.selected(records)
+	if len(records) == 0 && ((cmd.Cmd == 'a' && cmd.Address.Kind == '$') || (cmd.Cmd == 'i' && cmd.Address.Kind == 'n' && cmd.Address.Line == 1)) {
+		// An empty dataset still has a place for a line appended at its end or inserted at its beginning.
+		return append([]string{}, cmd.Text...)
+	}
+	if len(lines) == 0 {
+		return records
+	}
+
+	selected := make(map[int]bool, len(lines))
+	for _, l := range lines {
+		selected[l] = true
+	}
+
+	out := make([]string, 0, len(records))
+	for i, r := range records {
+		if !selected[i] {
+			out = append(out, r)
+			continue
+		}
+		switch cmd.Cmd {
+		case 's':
+			out = append(out, strings.Split(cmd.substitute(r), "\n")...)
+		case 'd':
+		case 'a':
+			out = append(out, r)
+			out = append(out, cmd.Text...)
+		case 'i':
+			out = append(out, cmd.Text...)
+			out = append(out, r)
+		case 'c':
+			out = append(out, cmd.Text...)
+		}
+	}
+	return out
+}
+
+func (cmd *sedCommand) substitute(line string) string {
+	matches := cmd.Re.FindAllStringSubmatchIndex(line, -1)
+	var out strings.Builder
+	last := 0
+	for n, m := range matches {
+		if !cmd.Global && n+1 != max(cmd.Nth, 1) {
+			continue
+		}
+		out.WriteString(line[last:m[0]])
+		out.WriteString(expandReplacement(cmd.Repl, line, m))
+		last = m[1]
+		if !cmd.Global {
+			break
+		}
+	}
+	out.WriteString(line[last:])
+	return out.String()
+}
+
+// dsed [-s] [-e script]... [script] dataset
+//
+// With -s the expressions are tried in order and dsed stops after the first one that changes the dataset.
+func (s *Simulator) dsed(args []string) zoau.Result {
+	options, operands, err := parseOptions(args, "ec")
+	if err != nil {
+		return failure(8, "BGYSC4001E", "%v.", err)
+	}
+	scripts := options['e']
+	if len(scripts) == 0 && len(operands) > 0 {
+		scripts, operands = operands[:1], operands[1:]
+	}
+	if len(scripts) == 0 || len(operands) != 1 {
+		return failure(8, "BGYSC4001E", "Usage: dsed [-s] [-e script]... dataset.")
+	}
+	_, stopAtChange := options['s']
+
+	records, _, res, ok := s.readSource(operands[0])
+	if !ok {
+		return res
+	}
+	for _, script := range scripts {
+		cmd, err := parseSed(script)
+		if err != nil {
+			return failure(8, "BGYSC4002E", "Invalid expression %s: %v.", script, err)
+		}
+		edited := cmd.apply(records)
+		changed := strings.Join(edited, "\n") != strings.Join(records, "\n") || len(edited) != len(records)
+		records = edited
+		if stopAtChange && changed {
+			break
+		}
+	}
+	if res, ok := s.writeTarget(operands[0], records); !ok {
+		return res
+	}
+	return success("")
+}
+
+// dmod -b [-m marker] [-s] [-e script]... [script] dataset
+//
+// Only the block mode is simulated. The block is the lines between the marker lines, which default to
+// "# BEGIN MANAGED BLOCK" and "# END MANAGED BLOCK": a d command removes it, and an a or i command
+// replaces it with its text, or inserts its text between marker lines when the dataset has no block.
+func (s *Simulator) dmod(args []string) zoau.Result {
+	options, operands, err := parseOptions(args, "ecm")
+	if err != nil {
+		return failure(8, "BGYSC4001E", "%v.", err)
+	}
+	scripts := options['e']
+	if len(scripts) == 0 && len(operands) > 0 {
+		scripts, operands = operands[:1], operands[1:]
+	}
+	if _, block := options['b']; !block || len(scripts) == 0 || len(operands) != 1 {
+		return failure(8, "BGYSC4001E", "Usage: dmod -b [-m marker] [-s] [-e script]... dataset.")
+	}
+	_, stopAtChange := options['s']
+
+	marker, begin, end := "# {mark} MANAGED BLOCK", "BEGIN", "END"
+	if m, ok := lastOption(options, 'm'); ok {
+		parts := strings.Split(m, `\n`)
+		switch len(parts) {
+		case 1:
+			marker = parts[0]
+		case 3:
+			begin, end, marker = parts[0], parts[1], parts[2]
+		default:
+			return failure(8, "BGYSC4003E", "Invalid marker %s.", m)
+		}
+	}
+	begin, end = strings.ReplaceAll(marker, "{mark}", begin), strings.ReplaceAll(marker, "{mark}", end)
+
+	cmds := make([]sedCommand, 0, len(scripts))
+	for _, script := range scripts {
+		cmd, err := parseSed(script)
+		if err != nil {
+			return failure(8, "BGYSC4002E", "Invalid expression %s: %v.", script, err)
+		}
+		if cmd.Cmd != 'd' {
+			cmd.Text = append(append([]string{begin}, cmd.Text...), end)
+		}
+		cmds = append(cmds, cmd)
+	}
+
+	records, _, res, ok := s.readSource(operands[0])
+	if !ok {
+		return res
+	}
+	first, last := -1, -1
+	for i, r := range records {
+		switch strings.TrimRight(r, " ") {
+		case begin:
+			first = i
+		case end:
+			last = i
+		}
+	}
+	if first >= 0 && last >= first {
+		edited := append(append([]string{}, records[:first]...), records[last+1:]...)
+		if cmds[0].Cmd != 'd' {
+			edited = append(append(append([]string{}, records[:first]...), cmds[0].Text...), records[last+1:]...)
+		}
+		records = edited
+	} else {
+		for _, cmd := range cmds {
+			if cmd.Cmd == 'd' {
+				break
+			}
+			applied := len(cmd.selected(records)) > 0
+			records = cmd.apply(records)
+			if stopAtChange && applied {
+				break
+			}
+		}
+	}
+	if res, ok := s.writeTarget(operands[0], records); !ok {
+		return res
+	}
+	return success("")
+}
+
+type grepSource struct {
+	Name    string
+	Records []string
+}
+
+// grepSources expands a dgrep operand into the sequential datasets and members it designates.
+func (s *Simulator) grepSources(ref string) ([]grepSource, zoau.Result, bool) {
+	name, member := splitName(ref)
+	sources := make([]grepSource, 0)
+	for _, ds := range s.matchDatasets(name) {
+		if !ds.partitioned() {
+			if member == "" {
+				sources = append(sources, grepSource{ds.Name, ds.Records})
+			}
+			continue
+		}
+		_, members, res, ok := s.partitionedMembers(fmt.Sprintf("%s(%s)", ds.Name, member))
+		if !ok {
+			return nil, res, false
+		}
+		for _, m := range members {
+			sources = append(sources, grepSource{fmt.Sprintf("%s(%s)", ds.Name, m), ds.Members[m]})
+		}
+	}
+	if len(sources) == 0 {
+		return nil, failure(8, "BGYSC1501E", "Dataset %s does not exist.", ref), false
+	}
+	return sources, zoau.Result{}, true
+}
+
+// dgrep [-n] [-i] [-v] [-C lines] pattern dataset...
+//
+// Matches are printed grep style. The dataset name prefixes each line when -v is given or when
+// several datasets or members are searched, and the line number follows it with -n.
+func (s *Simulator) dgrep(args []string) zoau.Result {
+	options, operands, err := parseOptions(args, "C")
+	if err != nil {
+		return failure(8, "BGYSC4101E", "%v.", err)
+	}
+	if len(operands) < 2 {
+		return failure(8, "BGYSC4101E", "Usage: dgrep [options] pattern dataset....")
+	}
+	_, ignoreCase := options['i']
+	_, lineNumbers := options['n']
+	_, printNames := options['v']
+	context := 0
+	if v, ok := lastOption(options, 'C'); ok {
+		if context, err = strconv.Atoi(v); err != nil {
+			return failure(8, "BGYSC4102E", "Invalid context %s.", v)
+		}
+	}
+	re, err := zoau.CompileBRE(operands[0], ignoreCase)
+	if err != nil {
+		return failure(8, "BGYSC4103E", "Invalid pattern %s: %v.", operands[0], err)
+	}
+
+	sources := make([]grepSource, 0)
+	for _, ref := range operands[1:] {
+		found, res, ok := s.grepSources(ref)
+		if !ok {
+			return res
+		}
+		sources = append(sources, found...)
+	}
+	printNames = printNames || len(sources) > 1
+
+	var out strings.Builder
+	matched := false
+	for _, src := range sources {
+		printed := make([]bool, len(src.Records))
+		hits := make([]bool, len(src.Records))
+		for i, r := range src.Records {
+			if re.MatchString(r) {
+				hits[i] = true
+				for j := max(i-context, 0); j <= min(i+context, len(src.Records)-1); j++ {
+					printed[j] = true
+				}
+			}
+		}
+		for i, r := range src.Records {
+			if !printed[i] {
+				continue
+			}
+			if context > 0 && matched && (i == 0 || !printed[i-1]) {
+				out.WriteString("--\n")
+			}
+			matched = true
+			sep := "-"
+			if hits[i] {
+				sep = ":"
+			}
+			writeGrepLine(&out, src.Name, i+1, r, sep, printNames, lineNumbers)
+		}
+	}
+	if !matched {
+		return zoau.Result{Rc: 1}
+	}
+	return success(out.String())
+}
+
+func writeGrepLine(out *strings.Builder, name string, line int, text string, sep string, printNames bool, lineNumbers bool) {
+	if printNames {
+		out.WriteString(name + sep)
+	}
+	if lineNumbers {
+		out.WriteString(strconv.Itoa(line) + sep)
+	}
+	out.WriteString(text + "\n")
+}
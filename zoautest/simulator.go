@@ -0,0 +1,338 @@
+// Package zoautest provides an in-memory ZOAU backend for testing code that uses the zoau package
+// on systems without Z Open Automation Utilities.
+//
+// The Simulator implements zoau.Executor and reproduces the behavior of the ZOAU utilities the zoau
+// package drives (dls, dtouch, drm, decho, dtail, dcp, dmv, mls, mrm, mmv, dsed, dmod, dgrep, ddiff,
+// jsub, jls, jcan, ddls, pjdd, hlq, mvstmp, zoaversion, and mvscmd for IEFBR14, IEBGENER and IDCAMS)
+// over a catalog of datasets, members and job spool kept in memory. Error messages mimic the shape of
+// the ZOAU BGYSC messages; their identifiers are not those of a given ZOAU release.
+//
+// The Recorder and Replayer executors capture the invocations made on a live z/OS system into a
+// cassette file and play them back deterministically, see UseCassette.
+package zoautest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Stolkerve/zoau-go"
+)
+
+// Simulator is an in-memory ZOAU backend. It is safe for concurrent use.
+type Simulator struct {
+	mu       sync.Mutex
+	hlq      string
+	volume   string
+	datasets map[string]*dataset
+	jobs     []*job
+	tmpSeq   int
+	jobSeq   int
+	version  string
+
+	// Standard input of the running command.
+	stdin string
+}
+
+type dataset struct {
+	Name       string
+	Dsorg      string
+	Recfm      string
+	Lrecl      int
+	BlockSize  int
+	Volume     string
+	Space      int
+	Referenced time.Time
+
+	// Records of a sequential dataset.
+	Records []string
+
+	// Members of a partitioned dataset.
+	Members map[string][]string
+
+	// Attributes of a GDG base.
+	Gdg *gdgBase
+
+	// Attributes of a VSAM cluster, alternate index or path.
+	Vsam *vsamCluster
+}
+
+func (d *dataset) partitioned() bool {
+	return d.Dsorg == "PO"
+}
+
+// NewSimulator returns an empty Simulator whose user high level qualifier is hlq.
+func NewSimulator(hlq string) *Simulator {
+	return &Simulator{
+		hlq:      strings.ToUpper(hlq),
+		volume:   "SIM001",
+		datasets: make(map[string]*dataset),
+		version:  "1.3.0.0",
+	}
+}
+
+// SetVersion sets the ZOAU release reported by zoaversion, in the "1.2.3.0" form.
+func (s *Simulator) SetVersion(version string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.version = version
+}
+
+type handler func(s *Simulator, args []string) zoau.Result
+
+var handlers = map[string]handler{
+	"dcp":    (*Simulator).dcp,
+	"ddiff":  (*Simulator).ddiff,
+	"ddls":   (*Simulator).ddls,
+	"decho":  (*Simulator).decho,
+	"dgrep":  (*Simulator).dgrep,
+	"dls":    (*Simulator).dls,
+	"dmod":   (*Simulator).dmod,
+	"dmv":    (*Simulator).dmv,
+	"drm":    (*Simulator).drm,
+	"dsed":   (*Simulator).dsed,
+	"dtail":  (*Simulator).dtail,
+	"dtouch": (*Simulator).dtouch,
+	"hlq":    (*Simulator).hlqCmd,
+	"jcan":   (*Simulator).jcan,
+	"jls":    (*Simulator).jls,
+	"jsub":   (*Simulator).jsub,
+	"mls":    (*Simulator).mls,
+	"mmv":    (*Simulator).mmv,
+	"mrm":    (*Simulator).mrm,
+	"mvscmd": (*Simulator).mvscmd,
+	"mvstmp": (*Simulator).mvstmp,
+	"pjdd":   (*Simulator).pjdd,
+
+	"mvscmdauth": (*Simulator).mvscmd,
+	"zoaversion": (*Simulator).zoaversion,
+}
+
+// Run executes cmd against the in-memory catalog.
+func (s *Simulator) Run(ctx context.Context, cmd zoau.Command) (zoau.Result, error) {
+	if err := ctx.Err(); err != nil {
+		return zoau.Result{}, fmt.Errorf("%s: %w", cmd.Name, err)
+	}
+	h, ok := handlers[cmd.Name]
+	if !ok {
+		return zoau.Result{Rc: -1}, fmt.Errorf("zoautest: %s is not simulated", cmd.Name)
+	}
+
+	stdin := ""
+	if cmd.Stdin != nil {
+		content, err := io.ReadAll(cmd.Stdin)
+		if err != nil {
+			return zoau.Result{Rc: -1}, err
+		}
+		stdin = string(content)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stdin = stdin
+	return h(s, cmd.Args), nil
+}
+
+// catalog adds ds to the catalog. A new generation of a GDG rolls off the oldest ones past the limit.
+func (s *Simulator) catalog(ds *dataset) {
+	s.datasets[ds.Name] = ds
+	s.rollOff(ds.Name)
+}
+
+func (s *Simulator) hlqCmd(args []string) zoau.Result {
+	return success(s.hlq + "\n")
+}
+
+func (s *Simulator) zoaversion(args []string) zoau.Result {
+	return success(fmt.Sprintf("2024/01/01 00:00:00 CUT V%s simulator\n", s.version))
+}
+
+func (s *Simulator) mvstmp(args []string) zoau.Result {
+	hlq := "MVSTMP"
+	if len(args) > 0 {
+		hlq = strings.ToUpper(args[0])
+	}
+	s.tmpSeq++
+	now := time.Now()
+	return success(fmt.Sprintf("%s.P%07d.T%07d.C%07d\n", hlq, now.YearDay()*1000+s.tmpSeq%1000, now.Second()*1000+now.Nanosecond()/1e6, s.tmpSeq))
+}
+
+func success(stdout string) zoau.Result {
+	return zoau.Result{Stdout: stdout}
+}
+
+func failure(rc int, id string, format string, a ...any) zoau.Result {
+	return zoau.Result{Stderr: id + " " + fmt.Sprintf(format, a...) + "\n", Rc: rc}
+}
+
+// parseOptions splits args into single letter options and operands, getopt style.
+// Letters listed in withValue take an argument. Repeated options keep every value.
+func parseOptions(args []string, withValue string) (map[byte][]string, []string, error) {
+	options := make(map[byte][]string)
+	operands := make([]string, 0)
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			operands = append(operands, args[i+1:]...)
+			break
+		}
+		if len(arg) < 2 || arg[0] != '-' {
+			operands = append(operands, arg)
+			continue
+		}
+		for j := 1; j < len(arg); j++ {
+			opt := arg[j]
+			if strings.IndexByte(withValue, opt) < 0 {
+				options[opt] = append(options[opt], "")
+				continue
+			}
+			if j+1 < len(arg) {
+				options[opt] = append(options[opt], arg[j+1:])
+			} else if i+1 < len(args) {
+				i++
+				options[opt] = append(options[opt], args[i])
+			} else {
+				return nil, nil, fmt.Errorf("option -%c requires an argument", opt)
+			}
+			break
+		}
+	}
+	return options, operands, nil
+}
+
+func lastOption(options map[byte][]string, opt byte) (string, bool) {
+	values, ok := options[opt]
+	if !ok {
+		return "", false
+	}
+	return values[len(values)-1], true
+}
+
+// splitName normalizes a dataset reference into its dataset name and member name.
+func splitName(ref string) (string, string) {
+	name := strings.TrimPrefix(ref, "//")
+	name = strings.Trim(name, `'"`)
+	name = strings.ToUpper(name)
+	if i := strings.IndexByte(name, '('); i >= 0 && strings.HasSuffix(name, ")") {
+		return name[:i], name[i+1 : len(name)-1]
+	}
+	return name, ""
+}
+
+func isPath(ref string) bool {
+	return strings.HasPrefix(ref, "/") && !strings.HasPrefix(ref, "//")
+}
+
+// matchDatasets returns the datasets matching a dataset name pattern, sorted by name.
+func (s *Simulator) matchDatasets(pattern string) []*dataset {
+	name, _ := splitName(pattern)
+	matches := make([]*dataset, 0)
+	compiled, err := zoau.CompilePattern(name)
+	if err != nil {
+		return matches
+	}
+	for dsn, ds := range s.datasets {
+		if compiled.Match(dsn) {
+			matches = append(matches, ds)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Name < matches[j].Name })
+	return matches
+}
+
+// matchPattern reports whether a job name, identifier or owner matches a pattern, where * matches
+// zero or more characters and % or ? match a single character.
+func matchPattern(pattern string, name string) bool {
+	if pattern == "" {
+		return name == ""
+	}
+	switch {
+	case pattern[0] == '*':
+		for i := 0; i <= len(name); i++ {
+			if matchPattern(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	case name == "":
+		return false
+	case pattern[0] == '%' || pattern[0] == '?':
+		return matchPattern(pattern[1:], name[1:])
+	default:
+		return pattern[0] == name[0] && matchPattern(pattern[1:], name[1:])
+	}
+}
+
+// readSource returns the records of a dataset, a member or a USS file.
+func (s *Simulator) readSource(ref string) ([]string, *dataset, zoau.Result, bool) {
+	if isPath(ref) {
+		content, err := os.ReadFile(ref)
+		if err != nil {
+			return nil, nil, failure(8, "BGYSC1701E", "Unable to open file %s: %v.", ref, err), false
+		}
+		return splitRecords(string(content)), nil, zoau.Result{}, true
+	}
+
+	name, member := splitName(ref)
+	ds, ok := s.datasets[name]
+	if !ok {
+		return nil, nil, failure(8, "BGYSC1501E", "Dataset %s does not exist.", name), false
+	}
+	ds.Referenced = time.Now()
+	if member == "" {
+		if ds.partitioned() {
+			return nil, ds, failure(8, "BGYSC1502E", "Dataset %s is partitioned, a member name is required.", name), false
+		}
+		return ds.Records, ds, zoau.Result{}, true
+	}
+	if !ds.partitioned() {
+		return nil, ds, failure(8, "BGYSC1503E", "Dataset %s is not partitioned.", name), false
+	}
+	records, ok := ds.Members[member]
+	if !ok {
+		return nil, ds, failure(8, "BGYSC1504E", "Member %s not found in dataset %s.", member, name), false
+	}
+	return records, ds, zoau.Result{}, true
+}
+
+// writeTarget replaces the records of an existing sequential dataset or of a member.
+func (s *Simulator) writeTarget(ref string, records []string) (zoau.Result, bool) {
+	name, member := splitName(ref)
+	ds, ok := s.datasets[name]
+	if !ok {
+		return failure(8, "BGYSC1501E", "Dataset %s does not exist.", name), false
+	}
+	ds.Referenced = time.Now()
+	if member == "" {
+		if ds.partitioned() {
+			return failure(8, "BGYSC1502E", "Dataset %s is partitioned, a member name is required.", name), false
+		}
+		ds.Records = records
+		return zoau.Result{}, true
+	}
+	if !ds.partitioned() {
+		return failure(8, "BGYSC1503E", "Dataset %s is not partitioned.", name), false
+	}
+	ds.Members[member] = records
+	return zoau.Result{}, true
+}
+
+func splitRecords(content string) []string {
+	content = strings.TrimSuffix(content, "\n")
+	if content == "" {
+		return []string{}
+	}
+	return strings.Split(content, "\n")
+}
+
+func joinRecords(records []string) string {
+	if len(records) == 0 {
+		return ""
+	}
+	return strings.Join(records, "\n") + "\n"
+}
@@ -0,0 +1,124 @@
+package zoautest_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Stolkerve/zoau-go"
+	"github.com/Stolkerve/zoau-go/zoautest"
+)
+
+func newClient() *zoau.Client {
+	return zoau.NewClient(&zoau.ClientArgs{Executor: zoautest.NewSimulator("SIMUSER")})
+}
+
+func TestMembers(t *testing.T) {
+	ctx := context.Background()
+	client := newClient()
+
+	if _, err := client.Create(ctx, "SIMUSER.PDS", &zoau.CreateArgs{Type: zoau.String(zoau.DS_ORG_PDSE)}); err != nil {
+		t.Fatalf("Fail to create SIMUSER.PDS. Err: %v", err)
+	}
+	for _, member := range []string{"ALPHA", "BETA", "GAMMA"} {
+		if err := client.Write(ctx, "SIMUSER.PDS("+member+")", "member "+member, false); err != nil {
+			t.Fatalf("Fail to write %s. Err: %v", member, err)
+		}
+	}
+
+	if members, err := client.ListMembers(ctx, "SIMUSER.PDS(*A)"); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(members, []string{"ALPHA", "BETA", "GAMMA", ""}) {
+		t.Fatalf("Unexpected members %q", members)
+	}
+
+	if err := client.MoveMember(ctx, "SIMUSER.PDS", "BETA", "DELTA"); err != nil {
+		t.Fatal(err)
+	}
+	if deleted, err := client.DeleteMember(ctx, "SIMUSER.PDS(G*)"); err != nil || !deleted {
+		t.Fatalf("expected: true, <nil>, got %v, %v", deleted, err)
+	}
+	if members, err := client.ListMembers(ctx, "SIMUSER.PDS"); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(members, []string{"ALPHA", "DELTA", ""}) {
+		t.Fatalf("Unexpected members %q", members)
+	}
+
+	if out, err := client.Search(ctx, "SIMUSER.PDS", "member", nil); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(out.Matches, []zoau.SearchMatch{
+		{Dataset: "SIMUSER.PDS", Member: "ALPHA", Line: 1, Text: "member ALPHA"},
+		{Dataset: "SIMUSER.PDS", Member: "DELTA", Line: 1, Text: "member BETA"},
+	}) || out.Count != 2 {
+		t.Fatalf("Unexpected search result %+v", out)
+	}
+}
+
+func TestJobs(t *testing.T) {
+	ctx := context.Background()
+	client := newClient()
+
+	jcl := strings.Join([]string{
+		"//SIMJOB   JOB (ACCT),'SIM'",
+		"//COPY     EXEC PGM=IEBGENER",
+		"//SYSPRINT DD SYSOUT=*",
+		"//SYSIN    DD DUMMY",
+		"//SYSUT1   DD *",
+		"HELLO FROM THE SIMULATOR",
+		"/*",
+		"//SYSUT2   DD SYSOUT=*",
+	}, "\n")
+	if err := client.Write(ctx, "SIMUSER.JCL", jcl, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if job, err := client.SubmitJob(ctx, "SIMUSER.JCL", &zoau.SubmitArgs{Wait: false}); err != nil || job != nil {
+		t.Fatalf("expected: <nil>, <nil>, got %v, %v", job, err)
+	}
+	jobs, err := client.ListingJobs(ctx, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 || *jobs[0].Name != "SIMJOB" || *jobs[0].Owner != "SIMUSER" || *jobs[0].Status != "CC" || *jobs[0].Rc != "0000" {
+		t.Fatalf("Unexpected jobs %+v", jobs)
+	}
+
+	dds, err := client.ListJobDDs(ctx, *jobs[0].Id, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0)
+	for _, dd := range dds {
+		names = append(names, dd.StepName+"."+dd.Dataset)
+	}
+	if !reflect.DeepEqual(names, []string{"JES2.JESMSGLG", "JES2.JESJCL", "JES2.JESYSMSG", "COPY.SYSPRINT", "COPY.SYSUT2"}) {
+		t.Fatalf("Unexpected DDs %v", names)
+	}
+
+	if out, err := client.ReadJobOutput(ctx, *jobs[0].Id, "COPY", "SYSUT2", nil); err != nil {
+		t.Fatal(err)
+	} else if out != "HELLO FROM THE SIMULATOR" {
+		t.Fatalf("Unexpected output %q", out)
+	}
+}
+
+func TestEdit(t *testing.T) {
+	ctx := context.Background()
+	client := newClient()
+
+	if err := client.Write(ctx, "SIMUSER.PARMS", "A=1\nB=2\nC=3", false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.LineInFile(ctx, "SIMUSER.PARMS", "B=20", &zoau.LineInFileArgs{Regex: zoau.String("^B="), InsAft: zoau.String("EOF")}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.LineInFile(ctx, "SIMUSER.PARMS", "D=4", &zoau.LineInFileArgs{Regex: zoau.String("^D="), InsAft: zoau.String("EOF")}); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := client.Read(ctx, "SIMUSER.PARMS", nil); err != nil {
+		t.Fatal(err)
+	} else if out != "A=1\nB=20\nC=3\nD=4" {
+		t.Fatalf("Unexpected content %q", out)
+	}
+}
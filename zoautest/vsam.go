@@ -0,0 +1,949 @@
+package zoautest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Stolkerve/zoau-go"
+)
+
+// vsamCluster holds the attributes of a VSAM cluster, alternate index or path.
+type vsamCluster struct {
+	// CLUSTER, AIX or PATH.
+	Kind string
+
+	// INDEXED, NONINDEXED, NUMBERED or LINEAR.
+	Organization string
+
+	KeyLength           int
+	KeyOffset           int
+	AverageRecordSize   int
+	MaximumRecordSize   int
+	ControlIntervalSize int
+	ShareOptions        [2]int
+	SpaceType           string
+	Primary             int
+	Secondary           int
+	Reuse               bool
+	StorageClass        string
+	DataClass           string
+	ManagementClass     string
+	DataName            string
+	IndexName           string
+
+	// Base cluster of an alternate index, alternate index or cluster of a path.
+	Related   string
+	UniqueKey bool
+	Upgrade   bool
+	Update    bool
+
+	Created time.Time
+
+	// Record statistics since the definition.
+	Inserted  int
+	Deleted   int
+	Updated   int
+	Retrieved int
+}
+
+// IDCAMS organization of the dtouch VSAM types.
+var vsamOrganizations = map[string]string{
+	zoau.DS_ORG_KSDS: "INDEXED",
+	zoau.DS_ORG_ESDS: "NONINDEXED",
+	zoau.DS_ORG_RRDS: "NUMBERED",
+	zoau.DS_ORG_LDS:  "LINEAR",
+}
+
+// Bytes of the space units, for 4K control intervals.
+var vsamSpaceUnits = map[string]int{
+	"CYLINDERS": 737280,
+	"TRACKS":    49152,
+	"KILOBYTES": 1024,
+	"MEGABYTES": 1024 * 1024,
+}
+
+// newVsamCluster returns the attributes of a new cluster with the IDCAMS defaults.
+func newVsamCluster(name string, organization string) *vsamCluster {
+	v := &vsamCluster{
+		Kind:                "CLUSTER",
+		Organization:        organization,
+		KeyLength:           64,
+		AverageRecordSize:   4089,
+		MaximumRecordSize:   4089,
+		ControlIntervalSize: 4096,
+		ShareOptions:        [2]int{1, 3},
+		SpaceType:           "TRACKS",
+		Primary:             1,
+		Secondary:           1,
+		DataName:            name + ".DATA",
+		Created:             time.Now(),
+	}
+	if organization == "INDEXED" {
+		v.IndexName = name + ".INDEX"
+	}
+	return v
+}
+
+// keyed reports whether the records of v are accessed by key.
+func (v *vsamCluster) keyed() bool {
+	return v.Organization == "INDEXED"
+}
+
+// key returns the key of record, padded with blanks when the record is shorter than the key end.
+func (v *vsamCluster) key(record string) string {
+	end := v.KeyOffset + v.KeyLength
+	if len(record) < end {
+		record += strings.Repeat(" ", end-len(record))
+	}
+	return record[v.KeyOffset:end]
+}
+
+// allocated returns the space allocated to the data component, in bytes.
+func (v *vsamCluster) allocated() int {
+	unit, ok := vsamSpaceUnits[v.SpaceType]
+	if !ok {
+		unit = v.MaximumRecordSize
+	}
+	size := v.Primary * unit
+	return (size + v.ControlIntervalSize - 1) / v.ControlIntervalSize * v.ControlIntervalSize
+}
+
+// vsamDefine applies the parameters of a DEFINE CLUSTER, ALTERNATEINDEX or PATH command to v and
+// returns the name of the new entry.
+func vsamDefine(l *idcamsListing, s *Simulator, params idcamsNode, v *vsamCluster) (string, int) {
+	name, ok := params.value("NAME")
+	if !ok {
+		return "", l.fail(12, "IDC3201I", "VALUE REQUIRED FOR KEYWORD 'NAME'")
+	}
+	name = strings.ToUpper(name)
+	if _, ok := s.datasets[name]; ok {
+		l.printf("IDC3013I DUPLICATE DATA SET NAME")
+		return "", l.fail(12, "IDC3009I", "** VSAM CATALOG RETURN CODE IS 8 - REASON CODE IS IGG0CLEH-38")
+	}
+
+	if values := params.values("KEYS"); len(values) == 2 {
+		v.KeyLength = atoiDefault(values[0], -1)
+		v.KeyOffset = atoiDefault(values[1], -1)
+		if v.KeyLength < 1 || v.KeyLength > 255 || v.KeyOffset < 0 {
+			return "", l.fail(12, "IDC3226I", "VALUE FOR KEYWORD 'KEYS' IS OUT OF RANGE")
+		}
+	}
+	if values := params.values("RECORDSIZE"); len(values) == 2 {
+		v.AverageRecordSize = atoiDefault(values[0], -1)
+		v.MaximumRecordSize = atoiDefault(values[1], -1)
+		if v.AverageRecordSize < 1 || v.MaximumRecordSize < v.AverageRecordSize {
+			return "", l.fail(12, "IDC3226I", "VALUE FOR KEYWORD 'RECORDSIZE' IS OUT OF RANGE")
+		}
+	}
+	if v.keyed() && v.KeyOffset+v.KeyLength > v.MaximumRecordSize {
+		return "", l.fail(12, "IDC3226I", "VALUE FOR KEYWORD 'KEYS' IS OUT OF RANGE")
+	}
+	for unit := range vsamSpaceUnits {
+		if values := params.values(unit); len(values) > 0 {
+			v.SpaceType = unit
+			v.Primary = atoiDefault(values[0], 1)
+			if len(values) > 1 {
+				v.Secondary = atoiDefault(values[1], 0)
+			}
+		}
+	}
+	if values := params.values("RECORDS"); len(values) > 0 {
+		v.SpaceType = "RECORDS"
+		v.Primary = atoiDefault(values[0], 1)
+		if len(values) > 1 {
+			v.Secondary = atoiDefault(values[1], 0)
+		}
+	}
+	if value, ok := params.value("CONTROLINTERVALSIZE"); ok {
+		v.ControlIntervalSize = atoiDefault(value, 4096)
+	}
+	if values := params.values("SHAREOPTIONS"); len(values) > 0 {
+		v.ShareOptions[0] = atoiDefault(values[0], 1)
+		if len(values) > 1 {
+			v.ShareOptions[1] = atoiDefault(values[1], 3)
+		}
+	}
+	v.Reuse = params.has("REUSE")
+	v.StorageClass, _ = params.value("STORAGECLASS")
+	v.DataClass, _ = params.value("DATACLASS")
+	v.ManagementClass, _ = params.value("MANAGEMENTCLASS")
+	return name, 0
+}
+
+// vsamComponentNames applies the DATA and INDEX parameters of a DEFINE command.
+func vsamComponentNames(command []idcamsNode, v *vsamCluster) {
+	for _, c := range command {
+		name, ok := c.value("NAME")
+		switch {
+		case !ok:
+		case c.Name == "DATA":
+			v.DataName = strings.ToUpper(name)
+		case c.Name == "INDEX" && v.IndexName != "":
+			v.IndexName = strings.ToUpper(name)
+		}
+	}
+}
+
+// vsamVolume returns the first volume of the VOLUMES parameter, or the default volume.
+func (s *Simulator) vsamVolume(params idcamsNode) string {
+	if volumes := params.values("VOLUMES"); len(volumes) > 0 {
+		return strings.ToUpper(volumes[0])
+	}
+	return s.volume
+}
+
+func (s *Simulator) defineCluster(l *idcamsListing, params idcamsNode, rest []idcamsNode) int {
+	organization := "INDEXED"
+	for _, o := range []string{"INDEXED", "NONINDEXED", "NUMBERED", "LINEAR"} {
+		if params.has(o) {
+			organization = o
+		}
+	}
+	v := newVsamCluster("", organization)
+	name, code := vsamDefine(l, s, params, v)
+	if code != 0 {
+		return code
+	}
+	v.DataName = name + ".DATA"
+	if v.keyed() {
+		v.IndexName = name + ".INDEX"
+	}
+	vsamComponentNames(rest, v)
+
+	s.catalog(&dataset{
+		Name:       name,
+		Dsorg:      "VS",
+		Recfm:      "??",
+		Lrecl:      v.MaximumRecordSize,
+		Volume:     s.vsamVolume(params),
+		Space:      v.allocated(),
+		Referenced: time.Now(),
+		Records:    []string{},
+		Vsam:       v,
+	})
+	l.printf("IDC0508I DATA ALLOCATION STATUS FOR VOLUME %s IS 0", s.vsamVolume(params))
+	if v.keyed() {
+		l.printf("IDC0509I INDEX ALLOCATION STATUS FOR VOLUME %s IS 0", s.vsamVolume(params))
+	}
+	return 0
+}
+
+func (s *Simulator) defineAlternateIndex(l *idcamsListing, params idcamsNode, rest []idcamsNode) int {
+	related, ok := params.value("RELATE")
+	if !ok {
+		return l.fail(12, "IDC3201I", "VALUE REQUIRED FOR KEYWORD 'RELATE'")
+	}
+	related = strings.ToUpper(related)
+	base, ok := s.datasets[related]
+	if !ok || base.Vsam == nil || base.Vsam.Kind != "CLUSTER" || (base.Vsam.Organization != "INDEXED" && base.Vsam.Organization != "NONINDEXED") {
+		l.printf("IDC3012I ENTRY %s NOT FOUND", related)
+		return l.fail(12, "IDC3009I", "** VSAM CATALOG RETURN CODE IS 8 - REASON CODE IS IGG0CLEG-42")
+	}
+
+	v := newVsamCluster("", "INDEXED")
+	v.Kind = "AIX"
+	v.AverageRecordSize, v.MaximumRecordSize = 4086, 32600
+	name, code := vsamDefine(l, s, params, v)
+	if code != 0 {
+		return code
+	}
+	v.Related = related
+	v.UniqueKey = params.has("UNIQUEKEY")
+	v.Upgrade = !params.has("NOUPGRADE")
+	v.DataName, v.IndexName = name+".DATA", name+".INDEX"
+	vsamComponentNames(rest, v)
+
+	s.catalog(&dataset{
+		Name:       name,
+		Dsorg:      "VS",
+		Recfm:      "??",
+		Lrecl:      v.MaximumRecordSize,
+		Volume:     s.vsamVolume(params),
+		Space:      v.allocated(),
+		Referenced: time.Now(),
+		Records:    []string{},
+		Vsam:       v,
+	})
+	l.printf("IDC0508I DATA ALLOCATION STATUS FOR VOLUME %s IS 0", s.vsamVolume(params))
+	l.printf("IDC0509I INDEX ALLOCATION STATUS FOR VOLUME %s IS 0", s.vsamVolume(params))
+	return 0
+}
+
+func (s *Simulator) definePath(l *idcamsListing, params idcamsNode) int {
+	entry, ok := params.value("PATHENTRY")
+	if !ok {
+		return l.fail(12, "IDC3201I", "VALUE REQUIRED FOR KEYWORD 'PATHENTRY'")
+	}
+	entry = strings.ToUpper(entry)
+	related, ok := s.datasets[entry]
+	if !ok || related.Vsam == nil || related.Vsam.Kind == "PATH" {
+		l.printf("IDC3012I ENTRY %s NOT FOUND", entry)
+		return l.fail(12, "IDC3009I", "** VSAM CATALOG RETURN CODE IS 8 - REASON CODE IS IGG0CLEG-42")
+	}
+
+	v := &vsamCluster{Kind: "PATH", Related: entry, Update: !params.has("NOUPDATE"), Created: time.Now()}
+	name, code := vsamDefine(l, s, params, v)
+	if code != 0 {
+		return code
+	}
+	s.catalog(&dataset{
+		Name:       name,
+		Dsorg:      "VS",
+		Recfm:      "??",
+		Volume:     related.Volume,
+		Referenced: time.Now(),
+		Vsam:       v,
+	})
+	return 0
+}
+
+// pointerWidth returns the width of the base cluster pointers in the records of an alternate index:
+// the prime key of a KSDS or the 8 digit RBA of an ESDS.
+func pointerWidth(base *dataset) int {
+	if base.Vsam.keyed() {
+		return base.Vsam.KeyLength
+	}
+	return 8
+}
+
+// pointers returns the prime keys or RBAs of the records of base, in record order.
+func pointers(base *dataset) []string {
+	pointers := make([]string, len(base.Records))
+	rba := 0
+	for i, r := range base.Records {
+		if base.Vsam.keyed() {
+			pointers[i] = base.Vsam.key(r)
+		} else {
+			pointers[i] = fmt.Sprintf("%08d", rba)
+			rba += len(r)
+		}
+	}
+	return pointers
+}
+
+// buildIndex returns the records of the alternate index aix over the records of its base cluster:
+// the alternate key followed by the pointers to the base records having it. The key of a duplicate
+// is returned when a unique alternate index has one.
+func (s *Simulator) buildIndex(aix *dataset) ([]string, string) {
+	base := s.datasets[aix.Vsam.Related]
+	keys := make(map[string][]string)
+	for i, p := range pointers(base) {
+		key := aix.Vsam.key(base.Records[i])
+		if aix.Vsam.UniqueKey && len(keys[key]) > 0 {
+			return nil, key
+		}
+		keys[key] = append(keys[key], p)
+	}
+
+	records := make([]string, 0, len(keys))
+	for key, p := range keys {
+		records = append(records, key+strings.Join(p, ""))
+	}
+	sort.Strings(records)
+	return records, ""
+}
+
+// upgrade rebuilds the alternate indexes of the upgrade set of the cluster name.
+func (s *Simulator) upgrade(name string) {
+	for _, ds := range s.datasets {
+		if ds.Vsam != nil && ds.Vsam.Kind == "AIX" && ds.Vsam.Related == name && ds.Vsam.Upgrade {
+			if records, dup := s.buildIndex(ds); dup == "" {
+				ds.Records = records
+			}
+		}
+	}
+}
+
+func (s *Simulator) idcamsBuildIndex(l *idcamsListing, command []idcamsNode) int {
+	params := idcamsNode{Children: command[1:]}
+	in, ok := params.value("INDATASET")
+	out, ok2 := params.value("OUTDATASET")
+	if !ok || !ok2 {
+		return l.fail(12, "IDC3201I", "VALUE REQUIRED FOR KEYWORD 'INDATASET' AND 'OUTDATASET'")
+	}
+	in, out = strings.ToUpper(in), strings.ToUpper(out)
+	aix, ok := s.datasets[out]
+	if !ok || aix.Vsam == nil || aix.Vsam.Kind != "AIX" {
+		return l.fail(12, "IDC3012I", "ENTRY %s NOT FOUND", out)
+	}
+	if aix.Vsam.Related != in {
+		return l.fail(12, "IDC1645I", "**ALTERNATE INDEX %s NOT RELATED TO BASE CLUSTER %s", out, in)
+	}
+
+	records, dup := s.buildIndex(aix)
+	if dup != "" {
+		l.printf("IDC1646I **DUPLICATE PRIME KEY OR RBA FOR UNIQUE ALTERNATE KEY %s", dup)
+		return l.fail(8, "IDC0652I", "%s NOT BUILT", out)
+	}
+	aix.Records = records
+	aix.Referenced = time.Now()
+	l.printf("IDC0652I %s SUCCESSFULLY BUILT", out)
+	return 0
+}
+
+// vsamRecord is a record read from a VSAM entry, along with its key, RBA or relative record number.
+type vsamRecord struct {
+	Key    string
+	Record string
+}
+
+// readEntry returns the records of a cluster, alternate index, path or sequential dataset, in access order.
+func (s *Simulator) readEntry(name string) ([]vsamRecord, *dataset, bool) {
+	ds, ok := s.datasets[name]
+	if !ok || ds.partitioned() || ds.Gdg != nil {
+		return nil, nil, false
+	}
+	ds.Referenced = time.Now()
+	records := make([]vsamRecord, 0, len(ds.Records))
+
+	if ds.Vsam == nil {
+		for i, r := range ds.Records {
+			records = append(records, vsamRecord{Key: fmt.Sprint(i + 1), Record: r})
+		}
+		return records, ds, true
+	}
+
+	switch v := ds.Vsam; {
+	case v.Kind == "PATH":
+		aix := s.datasets[v.Related]
+		if aix.Vsam.Kind == "CLUSTER" {
+			return s.readEntry(aix.Name)
+		}
+		base := s.datasets[aix.Vsam.Related]
+		byPointer := make(map[string]string)
+		for i, p := range pointers(base) {
+			byPointer[p] = base.Records[i]
+		}
+		width := pointerWidth(base)
+		for _, r := range aix.Records {
+			key := r[:aix.Vsam.KeyLength]
+			for p := r[aix.Vsam.KeyLength:]; len(p) >= width; p = p[width:] {
+				records = append(records, vsamRecord{Key: key, Record: byPointer[p[:width]]})
+			}
+		}
+		base.Vsam.Retrieved += len(records)
+	case v.keyed() && v.Kind == "CLUSTER":
+		for _, r := range ds.Records {
+			records = append(records, vsamRecord{Key: v.key(r), Record: r})
+		}
+		v.Retrieved += len(records)
+	case v.Organization == "NONINDEXED":
+		rba := 0
+		for _, r := range ds.Records {
+			records = append(records, vsamRecord{Key: fmt.Sprint(rba), Record: r})
+			rba += len(r)
+		}
+		v.Retrieved += len(records)
+	default:
+		for i, r := range ds.Records {
+			records = append(records, vsamRecord{Key: fmt.Sprint(i + 1), Record: r})
+		}
+		v.Retrieved += len(records)
+	}
+	return records, ds, true
+}
+
+// selectRecords applies the FROMKEY, TOKEY, SKIP and COUNT parameters of REPRO and PRINT.
+func selectRecords(params idcamsNode, records []vsamRecord, keyed bool) []vsamRecord {
+	if from, ok := params.value("FROMKEY"); ok && keyed {
+		i := 0
+		for i < len(records) && records[i].Key[:min(len(from), len(records[i].Key))] < from {
+			i++
+		}
+		records = records[i:]
+	}
+	if to, ok := params.value("TOKEY"); ok && keyed {
+		i := 0
+		for i < len(records) && records[i].Key[:min(len(to), len(records[i].Key))] <= to {
+			i++
+		}
+		records = records[:i]
+	}
+	if v, ok := params.value("SKIP"); ok {
+		records = records[min(atoiDefault(v, 0), len(records)):]
+	}
+	if v, ok := params.value("COUNT"); ok {
+		records = records[:min(atoiDefault(v, len(records)), len(records))]
+	}
+	return records
+}
+
+// entryKeyed reports whether the records of ds are read by key: a KSDS, or a path over an alternate index.
+func (s *Simulator) entryKeyed(ds *dataset) bool {
+	if ds.Vsam == nil {
+		return false
+	}
+	if ds.Vsam.Kind == "PATH" {
+		return s.entryKeyed(s.datasets[ds.Vsam.Related])
+	}
+	return ds.Vsam.keyed()
+}
+
+func (s *Simulator) idcamsRepro(l *idcamsListing, command []idcamsNode) int {
+	params := idcamsNode{Children: command[1:]}
+	in, ok := params.value("INDATASET")
+	out, ok2 := params.value("OUTDATASET")
+	if !ok || !ok2 {
+		return l.fail(12, "IDC3201I", "VALUE REQUIRED FOR KEYWORD 'INDATASET' AND 'OUTDATASET'")
+	}
+	in, out = strings.ToUpper(in), strings.ToUpper(out)
+
+	records, source, ok := s.readEntry(in)
+	if !ok {
+		return l.fail(12, "IDC3012I", "ENTRY %s NOT FOUND", in)
+	}
+	records = selectRecords(params, records, s.entryKeyed(source))
+
+	target, ok := s.datasets[out]
+	if !ok || target.partitioned() || target.Gdg != nil {
+		return l.fail(12, "IDC3012I", "ENTRY %s NOT FOUND", out)
+	}
+	target.Referenced = time.Now()
+	if target.Vsam == nil {
+		target.Records = make([]string, 0, len(records))
+		for _, r := range records {
+			target.Records = append(target.Records, r.Record)
+		}
+		l.printf("IDC0005I NUMBER OF RECORDS PROCESSED WAS %d", len(records))
+		return 0
+	}
+
+	v := target.Vsam
+	if v.Kind != "CLUSTER" {
+		return l.fail(12, "IDC3300I", "ERROR OPENING %s", out)
+	}
+	if params.has("REUSE") {
+		if !v.Reuse {
+			l.printf("IDC3300I ERROR OPENING %s", out)
+			return l.fail(12, "IDC3351I", "** VSAM OPEN RETURN CODE IS 232")
+		}
+		target.Records = []string{}
+	}
+
+	code, processed := 0, 0
+	for _, r := range records {
+		if len(r.Record) > v.MaximumRecordSize {
+			l.printf("IDC3302I ACTION ERROR ON %s", out)
+			code = l.fail(8, "IDC3351I", "** VSAM I/O RETURN CODE IS 108")
+			continue
+		}
+		if !v.keyed() {
+			target.Records = append(target.Records, r.Record)
+			v.Inserted++
+			processed++
+			continue
+		}
+		key := v.key(r.Record)
+		i := sort.Search(len(target.Records), func(i int) bool { return v.key(target.Records[i]) >= key })
+		switch {
+		case i < len(target.Records) && v.key(target.Records[i]) == key && params.has("REPLACE"):
+			target.Records[i] = r.Record
+			v.Updated++
+		case i < len(target.Records) && v.key(target.Records[i]) == key:
+			code = l.fail(8, "IDC3314I", "**RECORD KEY %s IS A DUPLICATE", strings.TrimRight(key, " "))
+			continue
+		default:
+			target.Records = append(target.Records[:i], append([]string{r.Record}, target.Records[i:]...)...)
+			v.Inserted++
+		}
+		processed++
+	}
+	s.upgrade(out)
+	l.printf("IDC0005I NUMBER OF RECORDS PROCESSED WAS %d", processed)
+	return code
+}
+
+func (s *Simulator) idcamsPrint(l *idcamsListing, command []idcamsNode) int {
+	params := idcamsNode{Children: command[1:]}
+	in, ok := params.value("INDATASET")
+	if !ok {
+		return l.fail(12, "IDC3201I", "VALUE REQUIRED FOR KEYWORD 'INDATASET'")
+	}
+	in = strings.ToUpper(in)
+	records, ds, ok := s.readEntry(in)
+	if !ok {
+		return l.fail(12, "IDC3012I", "ENTRY %s NOT FOUND", in)
+	}
+	records = selectRecords(params, records, s.entryKeyed(ds))
+
+	label := "RECORD SEQUENCE NUMBER"
+	switch {
+	case s.entryKeyed(ds):
+		label = "KEY OF RECORD"
+	case ds.Vsam != nil && ds.Vsam.Organization == "NONINDEXED":
+		label = "RBA OF RECORD"
+	case ds.Vsam != nil && ds.Vsam.Organization == "NUMBERED":
+		label = "RELATIVE RECORD NUMBER"
+	}
+
+	l.printf("LISTING OF DATA SET -%s", in)
+	for _, r := range records {
+		switch {
+		case params.has("CHARACTER"):
+			l.printf("%s - %s", label, r.Key)
+			l.printf("%s", r.Record)
+		case params.has("HEX"):
+			l.printf("%s - %X", label, r.Key)
+			l.printf("%X", r.Record)
+		default:
+			l.printf("%s - %X", label, r.Key)
+			for offset := 0; offset < len(r.Record); offset += 32 {
+				chunk := r.Record[offset:min(offset+32, len(r.Record))]
+				l.printf("%06X %-64X *%s*", offset, chunk, chunk)
+			}
+		}
+		l.printf("")
+	}
+	if len(records) == 0 {
+		return l.fail(4, "IDC3005I", "DATA SET IS EMPTY")
+	}
+	l.printf("IDC0005I NUMBER OF RECORDS PROCESSED WAS %d", len(records))
+	return 0
+}
+
+// dependents returns the alternate indexes and paths related to the entry name, recursively.
+func (s *Simulator) dependents(name string) []*dataset {
+	dependents := make([]*dataset, 0)
+	for _, other := range s.datasets {
+		if other.Vsam != nil && other.Vsam.Related == name {
+			dependents = append(dependents, other)
+			dependents = append(dependents, s.dependents(other.Name)...)
+		}
+	}
+	return dependents
+}
+
+// deleteVsam deletes a VSAM entry along with its alternate indexes and paths, as IDCAMS DELETE does.
+func (s *Simulator) deleteVsam(l *idcamsListing, ds *dataset) {
+	for _, entry := range append(s.dependents(ds.Name), ds) {
+		delete(s.datasets, entry.Name)
+		l.printf("IDC0550I ENTRY (%s) %s DELETED", entryType(entry), entry.Name)
+		if entry.Vsam.DataName != "" {
+			l.printf("IDC0550I ENTRY (D) %s DELETED", entry.Vsam.DataName)
+		}
+		if entry.Vsam.IndexName != "" {
+			l.printf("IDC0550I ENTRY (I) %s DELETED", entry.Vsam.IndexName)
+		}
+	}
+}
+
+// Entry types listed by LISTCAT, in the order of the summary.
+var listcatTypes = []string{"AIX", "ALIAS", "CLUSTER", "DATA", "GDG", "INDEX", "NONVSAM", "PAGESPACE", "PATH", "SPACE", "USERCATALOG", "TAPELIBRARY", "TAPEVOLUME"}
+
+const simulatorCatalog = "CATALOG.SIMULATR"
+
+func (s *Simulator) idcamsListcat(l *idcamsListing, command []idcamsNode) int {
+	params := idcamsNode{Children: command[1:]}
+	all := params.has("ALL")
+
+	l.printf("LISTING FROM CATALOG -- %s", simulatorCatalog)
+	entries := make([]*dataset, 0)
+	code := 0
+	if names := params.values("ENTRIES"); len(names) > 0 {
+		for _, name := range names {
+			matches := s.matchDatasets(name)
+			if len(matches) == 0 {
+				l.printf("IDC3012I ENTRY %s NOT FOUND", strings.ToUpper(name))
+				l.printf("IDC3009I ** VSAM CATALOG RETURN CODE IS 8 - REASON CODE IS IGG0CLEG-42")
+				code = l.fail(4, "IDC1566I", "** %s NOT LISTED", strings.ToUpper(name))
+			}
+			entries = append(entries, matches...)
+		}
+	} else {
+		level := s.hlq
+		if v, ok := params.value("LEVEL"); ok {
+			level = strings.ToUpper(v)
+		}
+		entries = s.matchDatasets(level + ".**")
+		if len(entries) == 0 {
+			code = l.fail(4, "IDC3012I", "ENTRY %s NOT FOUND", level)
+		}
+	}
+
+	counts := make(map[string]int)
+	for _, ds := range entries {
+		l.printf("")
+		s.listcatEntry(l, ds, all, counts)
+	}
+
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	l.printf("")
+	l.printf("         THE NUMBER OF ENTRIES PROCESSED WAS:")
+	for _, t := range listcatTypes {
+		l.printf("                 %s %s%d", t, strings.Repeat("-", 22-len(t)), counts[t])
+	}
+	l.printf("                 TOTAL %s%d", strings.Repeat("-", 17), total)
+	l.printf("")
+	l.printf("         THE NUMBER OF PROTECTED ENTRIES SUPPRESSED WAS 0")
+	return code
+}
+
+// listcatField formats a LISTCAT field, padded with dashes to the width of the real listing.
+func listcatField(key string, value any, width int) string {
+	v := fmt.Sprint(value)
+	if v == "" {
+		v = "(NULL)"
+	}
+	return key + strings.Repeat("-", max(2, width-len(key)-len(v))) + v
+}
+
+// listcatFields formats a line of LISTCAT fields, four columns per line at most.
+func listcatFields(fields ...string) string {
+	padded := make([]string, len(fields))
+	for i, f := range fields {
+		padded[i] = fmt.Sprintf("%-24s", f)
+	}
+	return strings.TrimRight("       "+strings.Join(padded, "     "), " ")
+}
+
+func (s *Simulator) listcatEntry(l *idcamsListing, ds *dataset, all bool, counts map[string]int) {
+	header := func(indent string, kind string, name string) {
+		l.printf("%s%s %s %s", indent, kind, strings.Repeat("-", max(3, 14-len(indent)-len(kind))), name)
+		l.printf("     IN-CAT --- %s", simulatorCatalog)
+	}
+	history := func(created time.Time) {
+		if all {
+			l.printf("     HISTORY")
+			l.printf("%s", listcatFields(listcatField("DATASET-OWNER", "", 24), listcatField("CREATION", created.Format("2006.002"), 24)))
+			l.printf("%s", listcatFields(listcatField("RELEASE", 2, 24), listcatField("EXPIRATION", "0000.000", 24)))
+		}
+	}
+
+	switch {
+	case ds.Gdg != nil:
+		counts["GDG"]++
+		header("", "GDG BASE", ds.Name)
+		if all {
+			history(ds.Referenced)
+			l.printf("     ATTRIBUTES")
+			flags := []string{keyword(ds.Gdg.Scratch, "SCRATCH", "NOSCRATCH"), keyword(ds.Gdg.Empty, "EMPTY", "NOEMPTY"), keyword(ds.Gdg.Lifo, "LIFO", "FIFO"), keyword(ds.Gdg.Purge, "PURGE", "NOPURGE"), keyword(ds.Gdg.Extended, "EXTENDED", "NOEXTENDED")}
+			l.printf("%s", listcatFields(listcatField("LIMIT", ds.Gdg.Limit, 24), strings.Join(flags, "     ")))
+			generations := s.generations(ds.Name)
+			if len(generations) == 0 {
+				l.printf("     ASSOCIATIONS--(NULL)")
+				return
+			}
+			l.printf("     ASSOCIATIONS")
+			for _, g := range generations {
+				l.printf("       NONVSAM--%s", g)
+			}
+		}
+		return
+	case ds.Vsam == nil:
+		counts["NONVSAM"]++
+		header("", "NONVSAM", ds.Name)
+		if all {
+			history(ds.Referenced)
+			l.printf("     VOLUMES")
+			l.printf("%s", listcatFields(listcatField("VOLSER", ds.Volume, 24), listcatField("DEVTYPE", "X'3010200F'", 24)))
+			l.printf("     ASSOCIATIONS--(NULL)")
+		}
+		return
+	}
+
+	v := ds.Vsam
+	counts[v.Kind]++
+	header("", v.Kind, ds.Name)
+	history(v.Created)
+	if all && v.Kind != "PATH" {
+		l.printf("     SMSDATA")
+		l.printf("%s", listcatFields(listcatField("STORAGECLASS ", v.StorageClass, 17), listcatField("MANAGEMENTCLASS", v.ManagementClass, 18)))
+		l.printf("%s", listcatFields(listcatField("DATACLASS ", v.DataClass, 17), listcatField("LBACKUP ", "0000.000.0000", 24)))
+	}
+	if all {
+		l.printf("     ASSOCIATIONS")
+		if v.Related != "" {
+			related := s.datasets[v.Related]
+			l.printf("       %s", listcatField(related.Vsam.Kind, related.Name, len(related.Vsam.Kind)+2+len(related.Name)))
+		}
+		if v.Kind == "PATH" {
+			target := s.datasets[v.Related]
+			if target.Vsam.DataName != "" {
+				l.printf("       DATA-----%s", target.Vsam.DataName)
+			}
+			if target.Vsam.IndexName != "" {
+				l.printf("       INDEX----%s", target.Vsam.IndexName)
+			}
+		} else {
+			l.printf("       DATA-----%s", v.DataName)
+			if v.IndexName != "" {
+				l.printf("       INDEX----%s", v.IndexName)
+			}
+		}
+		names := make([]string, 0)
+		for name, other := range s.datasets {
+			if other.Vsam != nil && other.Vsam.Related == ds.Name {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			kind := s.datasets[name].Vsam.Kind
+			l.printf("       %s%s%s", kind, strings.Repeat("-", 9-len(kind)), name)
+		}
+		switch v.Kind {
+		case "PATH":
+			l.printf("     ATTRIBUTES")
+			l.printf("       %s", keyword(v.Update, "UPDATE", "NOUPDATE"))
+		case "AIX":
+			l.printf("     ATTRIBUTES")
+			l.printf("       %s", keyword(v.Upgrade, "UPGRADE", "NOUPGRADE"))
+		}
+	}
+	if v.Kind == "PATH" {
+		return
+	}
+
+	counts["DATA"]++
+	l.printf("   DATA ------- %s", v.DataName)
+	l.printf("     IN-CAT --- %s", simulatorCatalog)
+	if all {
+		s.listcatData(l, ds)
+	}
+	if v.IndexName != "" {
+		counts["INDEX"]++
+		l.printf("   INDEX ------ %s", v.IndexName)
+		l.printf("     IN-CAT --- %s", simulatorCatalog)
+		if all {
+			s.listcatIndex(l, ds)
+		}
+	}
+}
+
+func (s *Simulator) listcatData(l *idcamsListing, ds *dataset) {
+	v := ds.Vsam
+	used := 0
+	for _, r := range ds.Records {
+		used += len(r)
+	}
+	used = (used + v.ControlIntervalSize - 1) / v.ControlIntervalSize * v.ControlIntervalSize
+	keyLength, keyOffset := 0, 0
+	if v.keyed() {
+		keyLength, keyOffset = v.KeyLength, v.KeyOffset
+	}
+
+	l.printf("     ATTRIBUTES")
+	l.printf("%s", listcatFields(listcatField("KEYLEN", keyLength, 24), listcatField("AVGLRECL", v.AverageRecordSize, 24), listcatField("BUFSPACE", 2*v.ControlIntervalSize, 24), listcatField("CISIZE", v.ControlIntervalSize, 24)))
+	l.printf("%s", listcatFields(listcatField("RKP", keyOffset, 24), listcatField("MAXLRECL", v.MaximumRecordSize, 24), listcatField("EXCPEXIT", "", 24), listcatField("CI/CA", 180, 24)))
+	flags := []string{fmt.Sprintf("SHROPTNS(%d,%d)", v.ShareOptions[0], v.ShareOptions[1]), "RECOVERY", "UNIQUE", "NOERASE", v.Organization, "NOWRITECHK", "NOIMBED", "NOREPLICAT"}
+	if v.Reuse {
+		flags[2] = "REUSE"
+	}
+	if v.Kind == "AIX" {
+		flags = append(flags, keyword(v.UniqueKey, "UNQKEY", "NONUNIQKEY"))
+	}
+	l.printf("       %s", strings.Join(flags, "   "))
+	l.printf("     STATISTICS")
+	l.printf("%s", listcatFields(listcatField("REC-TOTAL", len(ds.Records), 24), listcatField("SPLITS-CI", 0, 24), listcatField("EXCPS", 0, 24)))
+	l.printf("%s", listcatFields(listcatField("REC-DELETED", v.Deleted, 24), listcatField("SPLITS-CA", 0, 24), listcatField("EXTENTS", 1, 24)))
+	l.printf("%s", listcatFields(listcatField("REC-INSERTED", v.Inserted, 24), listcatField("FREESPACE-%CI", 0, 24), "SYSTEM-TIMESTAMP:"))
+	l.printf("%s", listcatFields(listcatField("REC-UPDATED", v.Updated, 24), listcatField("FREESPACE-%CA", 0, 24), "     X'0000000000000000'"))
+	l.printf("%s", listcatFields(listcatField("REC-RETRIEVED", v.Retrieved, 24), listcatField("FREESPC", v.allocated()-used, 24)))
+	l.printf("     ALLOCATION")
+	spaceType := strings.TrimSuffix(v.SpaceType, "S")
+	l.printf("%s", listcatFields(listcatField("SPACE-TYPE", spaceType, 24), listcatField("HI-A-RBA", v.allocated(), 24)))
+	l.printf("%s", listcatFields(listcatField("SPACE-PRI", v.Primary, 24), listcatField("HI-U-RBA", used, 24)))
+	l.printf("%s", listcatFields(listcatField("SPACE-SEC", v.Secondary, 24)))
+	l.printf("     VOLUME")
+	l.printf("%s", listcatFields(listcatField("VOLSER", ds.Volume, 24), listcatField("PHYREC-SIZE", v.ControlIntervalSize, 24)))
+}
+
+func (s *Simulator) listcatIndex(l *idcamsListing, ds *dataset) {
+	v := ds.Vsam
+	entries := 0
+	if len(ds.Records) > 0 {
+		entries = 1
+	}
+	l.printf("     ATTRIBUTES")
+	l.printf("%s", listcatFields(listcatField("KEYLEN", v.KeyLength, 24), listcatField("AVGLRECL", 0, 24), listcatField("BUFSPACE", 0, 24), listcatField("CISIZE", 512, 24)))
+	l.printf("%s", listcatFields(listcatField("RKP", v.KeyOffset, 24), listcatField("MAXLRECL", 505, 24), listcatField("EXCPEXIT", "", 24), listcatField("CI/CA", 46, 24)))
+	l.printf("       SHROPTNS(%d,%d)   RECOVERY   UNIQUE   NOERASE   NOWRITECHK   NOIMBED   NOREPLICAT", v.ShareOptions[0], v.ShareOptions[1])
+	l.printf("     STATISTICS")
+	l.printf("%s", listcatFields(listcatField("REC-TOTAL", entries, 24), listcatField("SPLITS-CI", 0, 24), listcatField("EXCPS", 0, 24)))
+	l.printf("%s", listcatFields(listcatField("REC-DELETED", 0, 24), listcatField("SPLITS-CA", 0, 24), listcatField("EXTENTS", 1, 24)))
+	l.printf("     ALLOCATION")
+	l.printf("%s", listcatFields(listcatField("SPACE-TYPE", "TRACK", 24), listcatField("HI-A-RBA", 23552, 24)))
+	l.printf("%s", listcatFields(listcatField("SPACE-PRI", 1, 24), listcatField("HI-U-RBA", 512*entries, 24)))
+	l.printf("%s", listcatFields(listcatField("SPACE-SEC", 1, 24)))
+	l.printf("     VOLUME")
+	l.printf("%s", listcatFields(listcatField("VOLSER", ds.Volume, 24), listcatField("PHYREC-SIZE", 512, 24)))
+}
+
+// keyword returns on if set is true, off otherwise.
+func keyword(set bool, on string, off string) string {
+	if set {
+		return on
+	}
+	return off
+}
+
+// vsamComponent returns the cluster or alternate index whose data or index component is named name.
+func (s *Simulator) vsamComponent(name string) (*dataset, bool) {
+	for _, ds := range s.datasets {
+		if ds.Vsam != nil && (ds.Vsam.DataName == name || ds.Vsam.IndexName == name) {
+			return ds, true
+		}
+	}
+	return nil, false
+}
+
+// alterVsam applies the parameters of an ALTER command to the entry name, a cluster, an alternate
+// index, a path or one of their components.
+func (s *Simulator) alterVsam(l *idcamsListing, name string, ds *dataset, params idcamsNode) int {
+	v := ds.Vsam
+	component := name != ds.Name
+	if component && (params.has("NEWNAME") || params.has("UNIQUEKEY") || params.has("NONUNIQUEKEY") || params.has("UPGRADE") || params.has("NOUPGRADE")) {
+		return l.fail(12, "IDC3014I", "KEYWORD IS INVALID FOR ENTRY %s", name)
+	}
+	if !component && (params.has("FREESPACE") || params.has("SHAREOPTIONS")) {
+		return l.fail(12, "IDC3014I", "KEYWORD IS INVALID FOR ENTRY %s", name)
+	}
+	if params.has("UNIQUEKEY") || params.has("NONUNIQUEKEY") || params.has("UPGRADE") || params.has("NOUPGRADE") {
+		if v.Kind != "AIX" {
+			return l.fail(12, "IDC3014I", "KEYWORD IS INVALID FOR ENTRY %s", name)
+		}
+	}
+	if params.has("UNIQUEKEY") || params.has("NONUNIQUEKEY") {
+		if len(ds.Records) > 0 {
+			return l.fail(8, "IDC3009I", "** VSAM CATALOG RETURN CODE IS 60 - REASON CODE IS IGG0CLKP-4")
+		}
+		v.UniqueKey = params.has("UNIQUEKEY")
+	}
+	if params.has("UPGRADE") || params.has("NOUPGRADE") {
+		v.Upgrade = params.has("UPGRADE")
+	}
+	if values := params.values("SHAREOPTIONS"); len(values) > 0 {
+		v.ShareOptions[0] = atoiDefault(values[0], 1)
+		if len(values) > 1 {
+			v.ShareOptions[1] = atoiDefault(values[1], 3)
+		}
+	}
+	if value, ok := params.value("STORAGECLASS"); ok {
+		v.StorageClass = value
+	}
+	if value, ok := params.value("MANAGEMENTCLASS"); ok {
+		v.ManagementClass = value
+	}
+	if newName, ok := params.value("NEWNAME"); ok {
+		newName = strings.ToUpper(newName)
+		if _, ok := s.datasets[newName]; ok {
+			l.printf("IDC3013I DUPLICATE DATA SET NAME")
+			return l.fail(12, "IDC3009I", "** VSAM CATALOG RETURN CODE IS 8 - REASON CODE IS IGG0CLEH-38")
+		}
+		for _, other := range s.datasets {
+			if other.Vsam != nil && other.Vsam.Related == ds.Name {
+				other.Vsam.Related = newName
+			}
+		}
+		delete(s.datasets, ds.Name)
+		ds.Name = newName
+		s.datasets[newName] = ds
+	}
+	l.printf("IDC0531I ENTRY %s ALTERED", name)
+	return 0
+}
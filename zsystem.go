@@ -1,11 +1,17 @@
 package zoau
 
 import (
+	"context"
 	"errors"
 	"fmt"
 )
 
+// Apf runs Client.Apf on the default client.
 func Apf(args ApfArgs) (string, int, error) {
+	return DefaultClient().Apf(context.Background(), args)
+}
+
+func (c *Client) Apf(ctx context.Context, args ApfArgs) (string, int, error) {
 	options := make([]string, 0)
 	persistentOption := make([]string, 0)
 	if args.Persistent != nil {
@@ -24,8 +30,8 @@ func Apf(args ApfArgs) (string, int, error) {
 	}
 	if args.Opt != nil {
 		switch *args.Opt {
-		case OPT_ADD | OPT_DEL:
-			if args.DsName != nil {
+		case OPT_ADD, OPT_DEL:
+			if args.DsName == nil {
 				return "", -1, errors.New(fmt.Sprintf("DsName is required with %v operation", *args.Opt))
 			}
 			if args.ForceDynamic {
@@ -46,7 +52,7 @@ func Apf(args ApfArgs) (string, int, error) {
 				options = append(options, persistentOption...)
 			}
 			options = append(options, dsn)
-		case OPT_CHECK_FORMAT | OPT_SET_DYNAMIC | OPT_SET_STATIC:
+		case OPT_CHECK_FORMAT, OPT_SET_DYNAMIC, OPT_SET_STATIC:
 			options = append(options, "-F")
 			if *args.Opt == OPT_SET_DYNAMIC {
 				options = append(options, "DYNAMIC")
@@ -54,7 +60,11 @@ func Apf(args ApfArgs) (string, int, error) {
 				options = append(options, "STATIC")
 			}
 		case OPT_LIST:
-			options = append(options, "-lj")
+			if c.config.Has(CAPABILITY_APF_JSON) {
+				options = append(options, "-lj")
+			} else {
+				options = append(options, "-l")
+			}
 		}
 	} else if len(args.Batch) != 0 {
 		if args.ForceDynamic {
@@ -62,7 +72,7 @@ func Apf(args ApfArgs) (string, int, error) {
 		}
 		for _, b := range args.Batch {
 			switch b.Opt {
-			case OPT_ADD | OPT_DEL:
+			case OPT_ADD, OPT_DEL:
 				if b.Opt == OPT_ADD {
 					options = append(options, "-A")
 				} else {
@@ -85,51 +95,96 @@ func Apf(args ApfArgs) (string, int, error) {
 	} else {
 		return "", -1, errors.New("i")
 	}
-	stdout, rc, err := execZaouCmd("apfadm", options)
+	stdout, rc, err := c.execZaouCmd(ctx, "apfadm", options)
 	return stdout, rc, err
 }
 
+// FindLinkList runs Client.FindLinkList on the default client.
 func FindLinkList(member string) (string, error) {
-	return execSimpleStringCmd("llwhence", []string{member})
+	return DefaultClient().FindLinkList(context.Background(), member)
+}
+
+func (c *Client) FindLinkList(ctx context.Context, member string) (string, error) {
+	return c.execSimpleStringCmd(ctx, "llwhence", []string{member})
 }
 
+// FindParmLib runs Client.FindParmLib on the default client.
 func FindParmLib(member string) (string, error) {
-	return execSimpleStringCmd("parmwhence", []string{member})
+	return DefaultClient().FindParmLib(context.Background(), member)
 }
 
+func (c *Client) FindParmLib(ctx context.Context, member string) (string, error) {
+	return c.execSimpleStringCmd(ctx, "parmwhence", []string{member})
+}
+
+// FindProcLib runs Client.FindProcLib on the default client.
 func FindProcLib(member string) (string, error) {
-	return execSimpleStringCmd("procwhence", []string{member})
+	return DefaultClient().FindProcLib(context.Background(), member)
 }
 
+func (c *Client) FindProcLib(ctx context.Context, member string) (string, error) {
+	return c.execSimpleStringCmd(ctx, "procwhence", []string{member})
+}
+
+// ListLinkList runs Client.ListLinkList on the default client.
 func ListLinkList() ([]string, error) {
-	return execSimpleStringListCmd("pll", nil)
+	return DefaultClient().ListLinkList(context.Background())
+}
+
+func (c *Client) ListLinkList(ctx context.Context) ([]string, error) {
+	return c.execSimpleStringListCmd(ctx, "pll", nil)
 }
 
+// ListParmList runs Client.ListParmList on the default client.
 func ListParmList() ([]string, error) {
-	return execSimpleStringListCmd("pparm", nil)
+	return DefaultClient().ListParmList(context.Background())
+}
+
+func (c *Client) ListParmList(ctx context.Context) ([]string, error) {
+	return c.execSimpleStringListCmd(ctx, "pparm", nil)
 }
 
+// ListProcLib runs Client.ListProcLib on the default client.
 func ListProcLib() ([]string, error) {
-	return execSimpleStringListCmd("pproc", nil)
+	return DefaultClient().ListProcLib(context.Background())
 }
 
+func (c *Client) ListProcLib(ctx context.Context) ([]string, error) {
+	return c.execSimpleStringListCmd(ctx, "pproc", nil)
+}
+
+// ReadConsole runs Client.ReadConsole on the default client.
 func ReadConsole(options *rune) (string, error) {
+	return DefaultClient().ReadConsole(context.Background(), options)
+}
+
+func (c *Client) ReadConsole(ctx context.Context, options *rune) (string, error) {
 	opt := 'r'
-	if options == nil {
+	if options != nil {
 		opt = *options
 	}
 	switch opt {
-	case 'h' | 'r' | 'l' | 'd' | 'w' | 'm' | 'y' | 'a':
-		return execSimpleStringCmd("pcon", []string{fmt.Sprintf("-%c", opt)})
+	case 'h', 'r', 'l', 'd', 'w', 'm', 'y', 'a':
+		return c.execSimpleStringCmd(ctx, "pcon", []string{fmt.Sprintf("-%c", opt)})
 	default:
 		return "", errors.New(fmt.Sprintf("Invalid option -%c", opt))
 	}
 }
 
+// SearchParamLib runs Client.SearchParamLib on the default client.
 func SearchParamLib(find string) (string, error) {
-	return execSimpleStringCmd("parmgrep", []string{find})
+	return DefaultClient().SearchParamLib(context.Background(), find)
+}
+
+func (c *Client) SearchParamLib(ctx context.Context, find string) (string, error) {
+	return c.execSimpleStringCmd(ctx, "parmgrep", []string{find})
 }
 
+// SearchProcLib runs Client.SearchProcLib on the default client.
 func SearchProcLib(find string) (string, error) {
-	return execSimpleStringCmd("procgrep", []string{find})
+	return DefaultClient().SearchProcLib(context.Background(), find)
+}
+
+func (c *Client) SearchProcLib(ctx context.Context, find string) (string, error) {
+	return c.execSimpleStringCmd(ctx, "procgrep", []string{find})
 }